@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util/i18n"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/apply/prune"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
@@ -39,9 +41,14 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 	cmd.Flags().BoolVar(&r.serverSideOptions.ServerSideApply, "server-side", false,
 		"If true, apply merge patch is calculated on API server instead of client.")
 	cmd.Flags().BoolVar(&r.serverSideOptions.ForceConflicts, "force-conflicts", false,
-		"If true, overwrite applied fields on server if field manager conflict.")
+		"If true, overwrite applied fields on server if field manager conflict. "+
+			"Otherwise, fields are only overwritten if all conflicting fields are listed in the "+
+			common.ForceConflictsAnnotation+" annotation of the object.")
 	cmd.Flags().StringVar(&r.serverSideOptions.FieldManager, "field-manager", common.DefaultFieldManager,
 		"The client owner of the fields being applied on the server-side.")
+	cmd.Flags().BoolVar(&r.migrateClientSideApply, "migrate-client-side-apply", false,
+		"If true with --server-side, move the ownership of fields applied client-side to the field manager, "+
+			"so that fields removed from the manifests are removed from the cluster.")
 
 	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
 		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
@@ -55,6 +62,16 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"Background", "Propagation policy for pruning")
 	cmd.Flags().DurationVar(&r.pruneTimeout, "prune-timeout", time.Duration(0),
 		"Timeout threshold for waiting for all pruned resources to be deleted")
+	cmd.Flags().IntVar(&r.pruneLimit, "prune-limit", 0,
+		"Maximum number of objects that may be pruned. If more would be pruned, nothing is applied. 0 means no limit.")
+	cmd.Flags().IntVar(&r.pruneLimitPercent, "prune-limit-percent", 0,
+		"Maximum percentage of the objects in the inventory that may be pruned. "+
+			"If more would be pruned, nothing is applied. 0 means no limit.")
+	cmd.Flags().StringSliceVar(&r.pruneProtectedKinds, "prune-protected-kinds", defaultPruneProtectedKinds(),
+		"Kinds of objects, like Kind.group, that are only pruned with --confirm-prune-protected. "+
+			"If they would be pruned otherwise, nothing is applied.")
+	cmd.Flags().BoolVar(&r.confirmPruneProtected, "confirm-prune-protected", false,
+		"If true, prune objects of the kinds in --prune-protected-kinds.")
 	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
 		"It determines the behavior when the resources don't belong to current inventory. Available options "+
 			fmt.Sprintf("%q, %q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt, flagutils.InventoryPolicyForceAdopt))
@@ -62,11 +79,46 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"How long to wait before exiting")
 	cmd.Flags().BoolVar(&r.printStatusEvents, "status-events", false,
 		"Print status events (always enabled for table output)")
+	cmd.Flags().BoolVar(&r.watch, "watch", false,
+		"If true, keep running and apply the directory again whenever its files change. "+
+			"The timeout applies to each apply.")
+	cmd.Flags().DurationVar(&r.watchDebounce, "watch-debounce", 500*time.Millisecond,
+		"How long the files must be unchanged before applying them again in watch mode.")
+	cmd.Flags().StringVar(&r.watchOnChange, "watch-on-change", OnChangeCancel,
+		"What to do with a running apply when the files change in watch mode. Available options "+
+			fmt.Sprintf("%q and %q.", OnChangeCancel, OnChangeQueue))
+	cmd.Flags().BoolVar(&r.persistStatus, "persist-status", false,
+		"If true, persist the status of the objects in the inventory object as the apply progresses, "+
+			"so that an interrupted apply can be resumed. Requires an inventory type with a status subresource.")
+	cmd.Flags().BoolVar(&r.resume, "resume", false,
+		"If true, continue an interrupted apply, skipping the objects that were already applied or pruned "+
+			"successfully. Implies --persist-status.")
+	cmd.Flags().IntVar(&r.historyLimit, "history-limit", 0,
+		"Number of revisions of the inventory to keep. If set, each apply that completes without failures "+
+			"is recorded as a new revision. 0 means no revision is recorded.")
+	cmd.Flags().BoolVar(&r.historyManifests, "history-manifests", false,
+		"If true, record the applied manifests with each revision, so that it can be applied again with rollback. "+
+			"The manifests are readable by anyone who can read the inventory, so the apply fails if they include a Secret.")
+	cmd.Flags().BoolVar(&r.lock, "lock", false,
+		"If true, lock the inventory for the length of the apply, so that a concurrent apply or destroy "+
+			"of the same inventory fails instead of changing it at the same time.")
+	cmd.Flags().DurationVar(&r.lockTimeout, "lock-timeout", inventory.DefaultLockDuration,
+		"How long a lock that is not renewed is held, before another run may take it over. Only used with --lock.")
 
 	r.Command = cmd
 	return r
 }
 
+// defaultPruneProtectedKinds returns the default protected kinds, in the
+// format of the prune-protected-kinds flag.
+func defaultPruneProtectedKinds() []string {
+	kinds := make([]string, len(prune.DefaultProtectedGroupKinds))
+	for i, gk := range prune.DefaultProtectedGroupKinds {
+		kinds[i] = gk.String()
+	}
+	return kinds
+}
+
 func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
 	ioStreams genericclioptions.IOStreams) *cobra.Command {
 	return GetRunner(f, invFactory, loader, ioStreams).Command
@@ -80,25 +132,33 @@ type Runner struct {
 	loader     manifestreader.ManifestLoader
 
 	serverSideOptions      common.ServerSideOptions
+	migrateClientSideApply bool
 	output                 string
 	period                 time.Duration
 	reconcileTimeout       time.Duration
 	noPrune                bool
 	prunePropagationPolicy string
 	pruneTimeout           time.Duration
+	pruneLimit             int
+	pruneLimitPercent      int
+	pruneProtectedKinds    []string
+	confirmPruneProtected  bool
 	inventoryPolicy        string
 	timeout                time.Duration
 	printStatusEvents      bool
+	watch                  bool
+	watchDebounce          time.Duration
+	watchOnChange          string
+	persistStatus          bool
+	resume                 bool
+	historyLimit           int
+	historyManifests       bool
+	lock                   bool
+	lockTimeout            time.Duration
 }
 
 func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
-	// If specified, cancel with timeout.
-	if r.timeout != 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, r.timeout)
-		defer cancel()
-	}
 
 	prunePropPolicy, err := flagutils.ConvertPropagationPolicy(r.prunePropagationPolicy)
 	if err != nil {
@@ -119,20 +179,36 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
-	if err != nil {
-		return err
+	if r.watch {
+		if len(args) == 0 {
+			return fmt.Errorf("a directory is required to watch")
+		}
+		if r.watchOnChange != OnChangeCancel && r.watchOnChange != OnChangeQueue {
+			return fmt.Errorf("unknown watch-on-change option %q", r.watchOnChange)
+		}
+		if r.resume {
+			return fmt.Errorf("--resume cannot be used with --watch")
+		}
 	}
-	objs, err := reader.Read()
-	if err != nil {
-		return err
+	if r.migrateClientSideApply && !r.serverSideOptions.ServerSideApply {
+		return fmt.Errorf("--migrate-client-side-apply requires --server-side")
 	}
-
-	invObj, objs, err := inventory.SplitUnstructureds(objs)
-	if err != nil {
-		return err
+	if r.pruneLimit < 0 {
+		return fmt.Errorf("--prune-limit must not be negative")
+	}
+	if r.pruneLimitPercent < 0 || r.pruneLimitPercent > 100 {
+		return fmt.Errorf("--prune-limit-percent must be between 0 and 100")
+	}
+	if r.historyLimit < 0 {
+		return fmt.Errorf("--history-limit must not be negative")
+	}
+	if r.lockTimeout <= 0 {
+		return fmt.Errorf("--lock-timeout must be positive")
+	}
+	protectedGroupKinds := make([]schema.GroupKind, len(r.pruneProtectedKinds))
+	for i, kind := range r.pruneProtectedKinds {
+		protectedGroupKinds[i] = schema.ParseGroupKind(kind)
 	}
-	inv := inventory.WrapInventoryInfoObj(invObj)
 
 	invClient, err := r.invFactory.NewClient(r.factory)
 	if err != nil {
@@ -154,19 +230,83 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 		r.printStatusEvents = true
 	}
 
-	ch := a.Run(ctx, inv, objs, apply.ApplierOptions{
+	options := apply.ApplierOptions{
 		ServerSideOptions: r.serverSideOptions,
 		PollInterval:      r.period,
 		ReconcileTimeout:  r.reconcileTimeout,
 		// If we are not waiting for status, tell the applier to not
 		// emit the events.
-		EmitStatusEvents:       r.printStatusEvents,
-		NoPrune:                r.noPrune,
-		DryRunStrategy:         common.DryRunNone,
-		PrunePropagationPolicy: prunePropPolicy,
-		PruneTimeout:           r.pruneTimeout,
-		InventoryPolicy:        inventoryPolicy,
-	})
+		EmitStatusEvents:         r.printStatusEvents,
+		NoPrune:                  r.noPrune,
+		DryRunStrategy:           common.DryRunNone,
+		PrunePropagationPolicy:   prunePropPolicy,
+		PruneTimeout:             r.pruneTimeout,
+		InventoryPolicy:          inventoryPolicy,
+		PersistStatus:            r.persistStatus,
+		Resume:                   r.resume,
+		MigrateClientSideApply:   r.migrateClientSideApply,
+		PruneLimit:               r.pruneLimit,
+		PruneLimitPercent:        r.pruneLimitPercent,
+		PruneProtectedGroupKinds: protectedGroupKinds,
+		ConfirmPruneProtected:    r.confirmPruneProtected,
+		HistoryLimit:             r.historyLimit,
+		HistoryManifests:         r.historyManifests,
+		Lock:                     r.lock,
+		LockOptions: inventory.LockOptions{
+			LeaseDuration: r.lockTimeout,
+		},
+	}
+	run := func(ctx context.Context) error {
+		return r.runApply(ctx, cmd, args, a, options)
+	}
+
+	if !r.watch {
+		return run(ctx)
+	}
+
+	// Re-read and apply the directory whenever its files change, until
+	// the command is interrupted.
+	changes, err := watchDir(ctx, args[0], flagutils.InventoryFile(r.invFactory))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(r.ioStreams.ErrOut, "watching %s for changes\n", args[0])
+	loop := &watchLoop{
+		changes:  changes,
+		debounce: r.watchDebounce,
+		onChange: r.watchOnChange,
+		apply:    run,
+		errOut:   r.ioStreams.ErrOut,
+	}
+	return loop.Run(ctx)
+}
+
+// runApply reads the manifests and applies them once.
+func (r *Runner) runApply(ctx context.Context, cmd *cobra.Command, args []string,
+	a *apply.Applier, options apply.ApplierOptions) error {
+	// If specified, cancel with timeout.
+	if r.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	invObj, objs, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return err
+	}
+	inv := inventory.WrapInventoryInfoObj(invObj)
+
+	ch := a.Run(ctx, inv, objs, options)
 
 	// The printer will print updates from the channel. It will block
 	// until the channel is closed.
@@ -0,0 +1,207 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// OnChangeCancel cancels a running apply when the manifests change,
+	// and starts a new apply.
+	OnChangeCancel = "cancel"
+	// OnChangeQueue lets a running apply finish when the manifests change,
+	// and starts a new apply afterwards.
+	OnChangeQueue = "queue"
+)
+
+// watchLoop runs the apply function once, and again each time a change
+// is received. Changes are debounced, so that rapid changes, like an
+// editor saving several files, start a single apply. If a change arrives
+// while an apply is running, the running apply is either cancelled or
+// allowed to finish, depending on onChange, and a new apply starts once
+// it returns. Changes during a running apply are collapsed into a single
+// queued apply.
+type watchLoop struct {
+	changes  <-chan struct{}
+	debounce time.Duration
+	onChange string
+	apply    func(ctx context.Context) error
+	// errOut receives the errors of the individual applies, which do
+	// not stop the loop.
+	errOut io.Writer
+}
+
+// Run blocks until the context is cancelled or the changes channel is
+// closed. It waits for a running apply to return before returning.
+func (w *watchLoop) Run(ctx context.Context) error {
+	done := make(chan error, 1)
+	running := false
+	var runCtx context.Context
+	var cancelRun context.CancelFunc
+	start := func() {
+		runCtx, cancelRun = context.WithCancel(ctx)
+		running = true
+		go func(ctx context.Context) {
+			done <- w.apply(ctx)
+		}(runCtx)
+	}
+	stop := func() {
+		cancelRun()
+		if running {
+			<-done
+		}
+	}
+
+	start()
+	queued := false
+	var debounced <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			stop()
+			return nil
+		case _, ok := <-w.changes:
+			if !ok {
+				stop()
+				return nil
+			}
+			// Restart the debounce period on every change.
+			debounced = time.After(w.debounce)
+		case <-debounced:
+			debounced = nil
+			if !running {
+				start()
+				continue
+			}
+			queued = true
+			if w.onChange == OnChangeCancel {
+				klog.V(4).Infoln("manifests changed; cancelling running apply")
+				cancelRun()
+			}
+		case err := <-done:
+			running = false
+			// Errors caused by cancelling the apply are expected.
+			if err != nil && runCtx.Err() == nil {
+				_, _ = fmt.Fprintf(w.errOut, "error: %v\n", err)
+			}
+			cancelRun()
+			if queued {
+				queued = false
+				start()
+			}
+		}
+	}
+}
+
+// watchDir sends a change on the returned channel for each file in the
+// directory, or any of its subdirectories, that is written, created,
+// removed or renamed. Hidden files and directories, like editor swap
+// files, are ignored, and so is the passed inventory file, if any, which
+// is written by each apply. The channel is closed when the context is
+// cancelled.
+func watchDir(ctx context.Context, dir string, inventoryFile string) (<-chan struct{}, error) {
+	isInventoryFile, err := inventoryFileMatcher(inventoryFile)
+	if err != nil {
+		return nil, err
+	}
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addDirs(fsWatcher, dir); err != nil {
+		_ = fsWatcher.Close()
+		return nil, err
+	}
+
+	changes := make(chan struct{})
+	go func() {
+		defer close(changes)
+		defer fsWatcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-fsWatcher.Events:
+				if !ok {
+					return
+				}
+				if e.Op == fsnotify.Chmod || isHidden(e.Name) || isInventoryFile(e.Name) {
+					continue
+				}
+				klog.V(4).Infof("manifest change: %s", e)
+				if e.Op&fsnotify.Create != 0 {
+					// Watch new subdirectories too.
+					if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+						if err := addDirs(fsWatcher, e.Name); err != nil {
+							klog.Warningf("failed to watch %s: %v", e.Name, err)
+						}
+					}
+				}
+				select {
+				case changes <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			case err, ok := <-fsWatcher.Errors:
+				if !ok {
+					return
+				}
+				klog.Warningf("error watching %s: %v", dir, err)
+			}
+		}
+	}()
+	return changes, nil
+}
+
+// addDirs adds the directory and all its subdirectories to the watcher.
+func addDirs(fsWatcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if path != dir && isHidden(path) {
+			return filepath.SkipDir
+		}
+		return fsWatcher.Add(path)
+	})
+}
+
+func isHidden(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), ".")
+}
+
+// inventoryFileMatcher returns a function which returns true for the
+// passed inventory file, and for the temporary files it is written to
+// before it is replaced. Matches no file if the inventory file is empty.
+func inventoryFileMatcher(inventoryFile string) (func(path string) bool, error) {
+	if inventoryFile == "" {
+		return func(string) bool { return false }, nil
+	}
+	invPath, err := filepath.Abs(inventoryFile)
+	if err != nil {
+		return nil, err
+	}
+	return func(path string) bool {
+		absPath, err := filepath.Abs(path)
+		if err != nil || filepath.Dir(absPath) != filepath.Dir(invPath) {
+			return false
+		}
+		base := filepath.Base(absPath)
+		invBase := filepath.Base(invPath)
+		return base == invBase || strings.HasPrefix(base, invBase+".")
+	}, nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeApply records the applies started by the watch loop. Each apply
+// blocks until it is released, or its context is cancelled.
+type fakeApply struct {
+	mu        sync.Mutex
+	started   int
+	cancelled int
+	startedCh chan struct{}
+	release   chan error
+}
+
+func newFakeApply() *fakeApply {
+	return &fakeApply{
+		startedCh: make(chan struct{}, 10),
+		release:   make(chan error),
+	}
+}
+
+func (f *fakeApply) apply(ctx context.Context) error {
+	f.mu.Lock()
+	f.started++
+	f.mu.Unlock()
+	f.startedCh <- struct{}{}
+	select {
+	case err := <-f.release:
+		return err
+	case <-ctx.Done():
+		f.mu.Lock()
+		f.cancelled++
+		f.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (f *fakeApply) counts() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.started, f.cancelled
+}
+
+// syncBuffer is a buffer that can be written and read concurrently.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitStarted(t *testing.T, f *fakeApply) {
+	select {
+	case <-f.startedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for apply to start")
+	}
+}
+
+func TestWatchLoop(t *testing.T) {
+	testCases := map[string]struct {
+		onChange          string
+		expectedStarted   int
+		expectedCancelled int
+	}{
+		"changes cancel the running apply": {
+			onChange:          OnChangeCancel,
+			expectedStarted:   2,
+			expectedCancelled: 1,
+		},
+		"changes are queued after the running apply": {
+			onChange:          OnChangeQueue,
+			expectedStarted:   2,
+			expectedCancelled: 0,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			f := newFakeApply()
+			changes := make(chan struct{})
+			errOut := &syncBuffer{}
+			loop := &watchLoop{
+				changes:  changes,
+				debounce: 10 * time.Millisecond,
+				onChange: tc.onChange,
+				apply:    f.apply,
+				errOut:   errOut,
+			}
+			done := make(chan error)
+			go func() {
+				done <- loop.Run(ctx)
+			}()
+
+			waitStarted(t, f)
+			// Rapid changes while the first apply runs start a single
+			// new apply.
+			for i := 0; i < 3; i++ {
+				changes <- struct{}{}
+			}
+			if tc.onChange == OnChangeQueue {
+				// Let the debounce period pass before the first apply
+				// finishes.
+				time.Sleep(50 * time.Millisecond)
+				f.release <- nil
+			}
+			waitStarted(t, f)
+			f.release <- errors.New("apply failed")
+
+			// Only errors that were not caused by cancellation are printed,
+			// and they do not stop the loop.
+			assert.Eventually(t, func() bool {
+				return errOut.String() == "error: apply failed\n"
+			}, 5*time.Second, 10*time.Millisecond)
+
+			close(changes)
+			assert.NoError(t, <-done)
+
+			started, cancelled := f.counts()
+			assert.Equal(t, tc.expectedStarted, started)
+			assert.Equal(t, tc.expectedCancelled, cancelled)
+		})
+	}
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, err := watchDir(ctx, dir, filepath.Join(dir, "inventory.yaml"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Hidden files, and the inventory file and its temporary files, are
+	// ignored.
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".swp"), []byte("x"), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "inventory.yaml.123"), []byte("x"), 0600))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "inventory.yaml.123"), filepath.Join(dir, "inventory.yaml")))
+	select {
+	case <-changes:
+		t.Fatal("unexpected change")
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("x"), 0600))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for change")
+	}
+
+	cancel()
+	for range changes {
+		// drain until closed
+	}
+}
@@ -50,6 +50,15 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
 		"How long to wait before exiting")
 	cmd.Flags().BoolVar(&r.printStatusEvents, "status-events", false,
 		"Print status events (always enabled for table output)")
+	cmd.Flags().StringSliceVar(&r.removeFinalizers, "remove-finalizers", nil,
+		"Names of the finalizers to remove from resources still terminating after --remove-finalizers-after")
+	cmd.Flags().DurationVar(&r.removeFinalizersAfter, "remove-finalizers-after", time.Duration(0),
+		"How long after the deletion of a resource the finalizers in --remove-finalizers are removed")
+	cmd.Flags().BoolVar(&r.lock, "lock", false,
+		"If true, lock the inventory for the length of the destroy, so that a concurrent apply or destroy "+
+			"of the same inventory fails instead of changing it at the same time.")
+	cmd.Flags().DurationVar(&r.lockTimeout, "lock-timeout", inventory.DefaultLockDuration,
+		"How long a lock that is not renewed is held, before another run may take it over. Only used with --lock.")
 
 	r.Command = cmd
 	return r
@@ -75,6 +84,10 @@ type Runner struct {
 	inventoryPolicy         string
 	timeout                 time.Duration
 	printStatusEvents       bool
+	removeFinalizers        []string
+	removeFinalizersAfter   time.Duration
+	lock                    bool
+	lockTimeout             time.Duration
 }
 
 func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
@@ -108,7 +121,7 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	invObj, _, err := inventory.SplitUnstructureds(objs)
+	invObj, objs, err := inventory.SplitUnstructureds(objs)
 	if err != nil {
 		return err
 	}
@@ -135,6 +148,13 @@ func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
 		DeletePropagationPolicy: deletePropPolicy,
 		InventoryPolicy:         inventoryPolicy,
 		EmitStatusEvents:        r.printStatusEvents,
+		HookObjects:             objs,
+		RemoveFinalizers:        r.removeFinalizers,
+		RemoveFinalizersAfter:   r.removeFinalizersAfter,
+		Lock:                    r.lock,
+		LockOptions: inventory.LockOptions{
+			LeaseDuration: r.lockTimeout,
+		},
 	})
 
 	// The printer will print updates from the channel. It will block
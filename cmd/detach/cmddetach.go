@@ -0,0 +1,159 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package detach
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "detach (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Remove resources from the inventory without deleting them"),
+		Long: i18n.T(`Remove resources from the inventory without deleting them.
+
+The selected resources are removed from the inventory, and their owning-inventory
+annotation is removed, so that they can be adopted by another inventory. The
+resources are otherwise left untouched. Resources must match all the provided
+--selector, --kind and --name flags, and at least one of them is required.`),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().StringVarP(&r.selector, "selector", "l", "",
+		"Label selector of the resources to detach")
+	cmd.Flags().StringSliceVar(&r.kinds, "kind", nil,
+		"Kinds of the resources to detach, as KIND or KIND.GROUP. A kind without a group matches any group.")
+	cmd.Flags().StringSliceVar(&r.names, "name", nil,
+		"Names of the resources to detach")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"Only print the resources that would be detached")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
+		"How long to wait before exiting")
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the detach command.
+type Runner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.ClientFactory
+	loader     manifestreader.ManifestLoader
+
+	selector string
+	kinds    []string
+	names    []string
+	dryRun   bool
+	timeout  time.Duration
+}
+
+func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// If specified, cancel with timeout.
+	if r.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	options, err := r.detacherOptions()
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the inventory object.
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	invObj, _, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return err
+	}
+	inv := inventory.WrapInventoryInfoObj(invObj)
+
+	invClient, err := r.invFactory.NewClient(r.factory)
+	if err != nil {
+		return err
+	}
+	d, err := apply.NewDetacher(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+
+	detached, err := d.Detach(ctx, inv, options)
+	suffix := ""
+	if r.dryRun {
+		suffix = " (preview)"
+	}
+	for _, id := range detached {
+		fmt.Fprintf(r.ioStreams.Out, "%s/%s detached%s\n", strings.ToLower(id.GroupKind.String()), id.Name, suffix)
+	}
+	fmt.Fprintf(r.ioStreams.Out, "%d resource(s) detached%s\n", len(detached), suffix)
+	return err
+}
+
+// detacherOptions validates the flags and returns the DetacherOptions.
+func (r *Runner) detacherOptions() (apply.DetacherOptions, error) {
+	options := apply.DetacherOptions{
+		Names: r.names,
+	}
+	if r.selector == "" && len(r.kinds) == 0 && len(r.names) == 0 {
+		return options, fmt.Errorf("at least one of --selector, --kind or --name is required")
+	}
+	if r.selector != "" {
+		selector, err := labels.Parse(r.selector)
+		if err != nil {
+			return options, fmt.Errorf("invalid --selector: %w", err)
+		}
+		options.Selector = selector
+	}
+	for _, kind := range r.kinds {
+		gk := schema.ParseGroupKind(kind)
+		if gk.Kind == "" {
+			return options, fmt.Errorf("invalid --kind %q", kind)
+		}
+		options.GroupKinds = append(options.GroupKinds, gk)
+	}
+	if r.dryRun {
+		options.DryRunStrategy = common.DryRunClient
+	}
+	return options, nil
+}
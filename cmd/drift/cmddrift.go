@@ -0,0 +1,127 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/printers"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "drift (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Report resources whose live state differs from the last applied state"),
+		Long: i18n.T(`Report resources whose live state differs from the last applied state.
+
+The objects in the inventory are compared with the state they were last applied
+with, read from the server-side apply managed fields of the field manager, or from
+the last-applied-configuration annotation. The command fails if any objects
+drifted or were deleted.`),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().StringVar(&r.fieldManager, "field-manager", common.DefaultFieldManager,
+		"The client owner of the fields being applied on the server-side.")
+	cmd.Flags().StringSliceVar(&r.ignoreManagers, "ignore-manager", nil,
+		"Field managers whose changes are not reported as drift, like controllers. Can be repeated.")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
+		"How long to wait before exiting")
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the drift command.
+type Runner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.ClientFactory
+	loader     manifestreader.ManifestLoader
+
+	output         string
+	fieldManager   string
+	ignoreManagers []string
+	timeout        time.Duration
+}
+
+func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// If specified, cancel with timeout.
+	if r.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	if found := printers.ValidatePrinterType(r.output); !found {
+		return fmt.Errorf("unknown output type %q", r.output)
+	}
+
+	// Retrieve the inventory object.
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	invObj, _, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return err
+	}
+	inv := inventory.WrapInventoryInfoObj(invObj)
+
+	invClient, err := r.invFactory.NewClient(r.factory)
+	if err != nil {
+		return err
+	}
+	d, err := apply.NewDrifter(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+
+	// Run the drifter. It will return a channel where we can receive the
+	// result for each object in the inventory.
+	ch := d.Run(ctx, inv, apply.DrifterOptions{
+		FieldManager:   r.fieldManager,
+		IgnoreManagers: r.ignoreManagers,
+	})
+
+	// The printer will print updates from the channel. It will block
+	// until the channel is closed.
+	printer := printers.GetPrinter(r.output, r.ioStreams)
+	return printer.Print(ch, common.DryRunNone, false)
+}
@@ -5,16 +5,21 @@ package flagutils
 
 import (
 	"fmt"
+	"io"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
 )
 
 const (
-	InventoryPolicyFlag       = "inventory-policy"
-	InventoryPolicyStrict     = "strict"
-	InventoryPolicyAdopt      = "adopt"
-	InventoryPolicyForceAdopt = "force-adopt"
+	InventoryFileFlag           = "inventory-file"
+	InventoryShardSizeLimitFlag = "inventory-shard-size-limit"
+	InventoryPolicyFlag         = "inventory-policy"
+	InventoryPolicyStrict       = "strict"
+	InventoryPolicyAdopt        = "adopt"
+	InventoryPolicyForceAdopt   = "force-adopt"
 )
 
 // ConvertPropagationPolicy converts a propagationPolicy described as a
@@ -55,3 +60,57 @@ func PathFromArgs(args []string) string {
 	}
 	return args[0]
 }
+
+// InventoryClientFactory creates the inventory clients of the commands. If
+// an inventory file is provided, the inventory is stored in that file,
+// otherwise the inventory client is created with the ClientFactory.
+type InventoryClientFactory struct {
+	inventory.ClientFactory
+	// InventoryFile is the path of the inventory file, usually set by the
+	// InventoryFileFlag.
+	InventoryFile string
+}
+
+func (f *InventoryClientFactory) NewClient(factory cmdutil.Factory) (inventory.Client, error) {
+	if f.InventoryFile != "" {
+		return inventory.FileClientFactory{Path: f.InventoryFile}.NewClient(factory)
+	}
+	return f.ClientFactory.NewClient(factory)
+}
+
+// ManifestLoader returns a ManifestLoader which reads the manifests with the
+// passed loader, except for the inventory file, so that an inventory file
+// stored in the package directory is not read as a manifest.
+func (f *InventoryClientFactory) ManifestLoader(loader manifestreader.ManifestLoader) manifestreader.ManifestLoader {
+	return &inventoryFileLoader{
+		ManifestLoader: loader,
+		factory:        f,
+	}
+}
+
+// inventoryFileLoader skips the inventory file of the factory when the
+// manifests are read from a directory.
+type inventoryFileLoader struct {
+	manifestreader.ManifestLoader
+	factory *InventoryClientFactory
+}
+
+func (l *inventoryFileLoader) ManifestReader(reader io.Reader, path string) (manifestreader.ManifestReader, error) {
+	mReader, err := l.ManifestLoader.ManifestReader(reader, path)
+	if err != nil {
+		return nil, err
+	}
+	if pathReader, ok := mReader.(*manifestreader.PathManifestReader); ok && l.factory.InventoryFile != "" {
+		pathReader.SkipFiles = append(pathReader.SkipFiles, l.factory.InventoryFile)
+	}
+	return mReader, nil
+}
+
+// InventoryFile returns the inventory file of the passed factory, or an
+// empty string if it does not store the inventory in a file.
+func InventoryFile(invFactory inventory.ClientFactory) string {
+	if f, ok := invFactory.(*InventoryClientFactory); ok {
+		return f.InventoryFile
+	}
+	return ""
+}
@@ -5,9 +5,15 @@ package flagutils
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 func TestConvertInventoryPolicy(t *testing.T) {
@@ -50,3 +56,93 @@ func TestConvertInventoryPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestInventoryClientFactory(t *testing.T) {
+	factory := &InventoryClientFactory{
+		ClientFactory: inventory.FakeClientFactory{},
+	}
+	invClient, err := factory.NewClient(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := invClient.(*inventory.FakeClient); !ok {
+		t.Errorf("expected the client of the ClientFactory, got %T", invClient)
+	}
+
+	factory.InventoryFile = "inventory.yaml"
+	invClient, err = factory.NewClient(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fileClient, ok := invClient.(*inventory.FileClient)
+	if !ok {
+		t.Fatalf("expected a file client, got %T", invClient)
+	}
+	if fileClient.Path != "inventory.yaml" {
+		t.Errorf("expected the inventory file %q, got %q", "inventory.yaml", fileClient.Path)
+	}
+}
+
+func TestInventoryFileInPackage(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("default")
+	defer tf.Cleanup()
+
+	dir := t.TempDir()
+	manifests := map[string]string{
+		"inventory-template.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: inventory
+  namespace: default
+  labels:
+    cli-utils.sigs.k8s.io/inventory-id: test
+`,
+		"cm.yaml": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm
+  namespace: default
+`,
+	}
+	for filename, content := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, filename), []byte(content), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	factory := &InventoryClientFactory{
+		ClientFactory: inventory.FakeClientFactory{},
+		InventoryFile: filepath.Join(dir, "inventory.yaml"),
+	}
+	loader := factory.ManifestLoader(manifestreader.NewManifestLoader(tf))
+
+	// The inventory file written by the first apply is not read as a
+	// manifest by the second apply.
+	for i := 0; i < 2; i++ {
+		reader, err := loader.ManifestReader(nil, dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		objs, err := reader.Read()
+		if err != nil {
+			t.Fatalf("apply %d: unexpected error: %v", i, err)
+		}
+		invObj, objs, err := inventory.SplitUnstructureds(objs)
+		if err != nil {
+			t.Fatalf("apply %d: unexpected error: %v", i, err)
+		}
+		invClient, err := factory.NewClient(tf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = invClient.Merge(inventory.WrapInventoryInfoObj(invObj),
+			object.UnstructuredSetToObjMetadataSet(objs), common.DryRunNone)
+		if err != nil {
+			t.Fatalf("apply %d: unexpected error: %v", i, err)
+		}
+	}
+	if _, err := os.Stat(factory.InventoryFile); err != nil {
+		t.Errorf("expected the inventory file to be written: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package history
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "history (DIRECTORY | STDIN)",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("List the recorded revisions of the inventory"),
+		Long: i18n.T(`List the recorded revisions of the inventory.
+
+A revision is recorded by each apply that completes without failures, when
+the apply is run with --history-limit. Revisions recorded with their manifests
+can be applied again with the rollback command.`),
+		RunE: r.RunE,
+	}
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the history command.
+type Runner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.ClientFactory
+	loader     manifestreader.ManifestLoader
+}
+
+func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
+	_, err := common.DemandOneDirectory(args)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the inventory object.
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	invObj, _, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return err
+	}
+	inv := inventory.WrapInventoryInfoObj(invObj)
+
+	invClient, err := r.invFactory.NewClient(r.factory)
+	if err != nil {
+		return err
+	}
+	historyClient, ok := invClient.(inventory.HistoryClient)
+	if !ok {
+		return fmt.Errorf("inventory client does not record revision history")
+	}
+	revs, err := historyClient.GetRevisions(inv)
+	if err != nil {
+		return err
+	}
+	if len(revs) == 0 {
+		_, _ = fmt.Fprint(r.ioStreams.Out, "no revisions found in the inventory history\n")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(r.ioStreams.Out, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "REVISION\tTIMESTAMP\tOBJECTS\tHASH\tMANIFESTS")
+	for _, rev := range revs {
+		manifests := "no"
+		if len(rev.Manifests) > 0 {
+			manifests = "yes"
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", rev.Number, rev.Timestamp.Format(time.RFC3339),
+			len(rev.Objects), rev.Hash, manifests)
+	}
+	return w.Flush()
+}
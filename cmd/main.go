@@ -14,10 +14,16 @@ import (
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/cmd/apply"
 	"sigs.k8s.io/cli-utils/cmd/destroy"
+	"sigs.k8s.io/cli-utils/cmd/detach"
 	"sigs.k8s.io/cli-utils/cmd/diff"
+	"sigs.k8s.io/cli-utils/cmd/drift"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/cmd/history"
 	"sigs.k8s.io/cli-utils/cmd/initcmd"
 	"sigs.k8s.io/cli-utils/cmd/preview"
+	"sigs.k8s.io/cli-utils/cmd/rollback"
 	"sigs.k8s.io/cli-utils/cmd/status"
+	"sigs.k8s.io/cli-utils/cmd/transfer"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
 
@@ -52,11 +58,16 @@ func main() {
 		ErrOut: os.Stderr,
 	}
 
-	names := []string{"init", "apply", "preview", "diff", "destroy", "status"}
+	names := []string{"init", "apply", "preview", "diff", "destroy", "status", "drift", "detach", "transfer", "history", "rollback"}
 	initCmd := initcmd.NewCmdInit(f, ioStreams)
 	updateHelp(names, initCmd)
-	loader := manifestreader.NewManifestLoader(f)
-	invFactory := inventory.ClusterClientFactory{}
+	clusterFactory := &inventory.ClusterClientFactory{}
+	flags.IntVar(&clusterFactory.ShardSizeLimit, flagutils.InventoryShardSizeLimitFlag, inventory.DefaultShardSizeLimit,
+		"The maximum size in bytes of each inventory object. Larger inventories are split across several inventory objects. A negative value does not split new inventories.")
+	invFactory := &flagutils.InventoryClientFactory{ClientFactory: clusterFactory}
+	flags.StringVar(&invFactory.InventoryFile, flagutils.InventoryFileFlag, "",
+		"If set, store the inventory in this local file instead of in an inventory object in the cluster.")
+	loader := invFactory.ManifestLoader(manifestreader.NewManifestLoader(f))
 	applyCmd := apply.Command(f, invFactory, loader, ioStreams)
 	updateHelp(names, applyCmd)
 	previewCmd := preview.Command(f, invFactory, loader, ioStreams)
@@ -67,8 +78,19 @@ func main() {
 	updateHelp(names, destroyCmd)
 	statusCmd := status.Command(f, invFactory, loader)
 	updateHelp(names, statusCmd)
+	driftCmd := drift.Command(f, invFactory, loader, ioStreams)
+	updateHelp(names, driftCmd)
+	detachCmd := detach.Command(f, invFactory, loader, ioStreams)
+	updateHelp(names, detachCmd)
+	transferCmd := transfer.Command(f, invFactory, loader, ioStreams)
+	updateHelp(names, transferCmd)
+	historyCmd := history.Command(f, invFactory, loader, ioStreams)
+	updateHelp(names, historyCmd)
+	rollbackCmd := rollback.Command(f, invFactory, loader, ioStreams)
+	updateHelp(names, rollbackCmd)
 
-	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd)
+	cmd.AddCommand(initCmd, applyCmd, diffCmd, destroyCmd, previewCmd, statusCmd, driftCmd, detachCmd, transferCmd,
+		historyCmd, rollbackCmd)
 
 	code := cli.Run(cmd)
 	os.Exit(code)
@@ -0,0 +1,187 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/cmd/flagutils"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/printers"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "rollback (DIRECTORY | STDIN) --to REVISION",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Apply a recorded revision of the inventory again"),
+		Long: i18n.T(`Apply a recorded revision of the inventory again.
+
+The manifests recorded with the revision are applied, and the objects applied
+since the revision are pruned. Only the inventory object is read from the
+directory or stdin. The revision must have been recorded with its manifests.`),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().IntVar(&r.revision, "to", 0,
+		"Number of the revision to apply, as listed by the history command.")
+	cmd.Flags().StringVar(&r.output, "output", printers.DefaultPrinter(),
+		fmt.Sprintf("Output format, must be one of %s", strings.Join(printers.SupportedPrinters(), ",")))
+	cmd.Flags().DurationVar(&r.period, "poll-period", 2*time.Second,
+		"Polling period for resource statuses.")
+	cmd.Flags().DurationVar(&r.reconcileTimeout, "reconcile-timeout", time.Duration(0),
+		"Timeout threshold for waiting for all resources to reach the Current status.")
+	cmd.Flags().DurationVar(&r.pruneTimeout, "prune-timeout", time.Duration(0),
+		"Timeout threshold for waiting for all pruned resources to be deleted")
+	cmd.Flags().StringVar(&r.inventoryPolicy, flagutils.InventoryPolicyFlag, flagutils.InventoryPolicyStrict,
+		"It determines the behavior when the resources don't belong to current inventory. Available options "+
+			fmt.Sprintf("%q, %q and %q.", flagutils.InventoryPolicyStrict, flagutils.InventoryPolicyAdopt, flagutils.InventoryPolicyForceAdopt))
+	cmd.Flags().IntVar(&r.historyLimit, "history-limit", 0,
+		"Number of revisions of the inventory to keep. If set, the rollback is recorded as a new revision. "+
+			"0 means no revision is recorded.")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
+		"How long to wait before exiting")
+	_ = cmd.MarkFlagRequired("to")
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the rollback command.
+type Runner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.ClientFactory
+	loader     manifestreader.ManifestLoader
+
+	revision         int
+	output           string
+	period           time.Duration
+	reconcileTimeout time.Duration
+	pruneTimeout     time.Duration
+	inventoryPolicy  string
+	historyLimit     int
+	timeout          time.Duration
+}
+
+func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// If specified, cancel with timeout.
+	if r.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	inventoryPolicy, err := flagutils.ConvertInventoryPolicy(r.inventoryPolicy)
+	if err != nil {
+		return err
+	}
+	if found := printers.ValidatePrinterType(r.output); !found {
+		return fmt.Errorf("unknown output type %q", r.output)
+	}
+	if r.historyLimit < 0 {
+		return fmt.Errorf("--history-limit must not be negative")
+	}
+	_, err = common.DemandOneDirectory(args)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the inventory object.
+	reader, err := r.loader.ManifestReader(cmd.InOrStdin(), flagutils.PathFromArgs(args))
+	if err != nil {
+		return err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	invObj, _, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return err
+	}
+	inv := inventory.WrapInventoryInfoObj(invObj)
+
+	invClient, err := r.invFactory.NewClient(r.factory)
+	if err != nil {
+		return err
+	}
+	rev, err := findRevision(invClient, inv, r.revision)
+	if err != nil {
+		return err
+	}
+
+	a, err := apply.NewApplierBuilder().
+		WithFactory(r.factory).
+		WithInventoryClient(invClient).
+		Build()
+	if err != nil {
+		return err
+	}
+	options := apply.ApplierOptions{
+		PollInterval:     r.period,
+		ReconcileTimeout: r.reconcileTimeout,
+		EmitStatusEvents: r.output == printers.TablePrinter,
+		DryRunStrategy:   common.DryRunNone,
+		PruneTimeout:     r.pruneTimeout,
+		InventoryPolicy:  inventoryPolicy,
+		HistoryLimit:     r.historyLimit,
+		HistoryManifests: true,
+	}
+	ch := a.Run(ctx, inv, rev.Manifests, options)
+
+	// The printer will print updates from the channel. It will block
+	// until the channel is closed.
+	printer := printers.GetPrinter(r.output, r.ioStreams)
+	return printer.Print(ch, common.DryRunNone, options.EmitStatusEvents)
+}
+
+// findRevision returns the recorded revision of the inventory with the
+// passed number, or an error if it is not found or cannot be applied.
+func findRevision(invClient inventory.Client, inv inventory.Info, number int) (inventory.Revision, error) {
+	historyClient, ok := invClient.(inventory.HistoryClient)
+	if !ok {
+		return inventory.Revision{}, fmt.Errorf("inventory client does not record revision history")
+	}
+	revs, err := historyClient.GetRevisions(inv)
+	if err != nil {
+		return inventory.Revision{}, err
+	}
+	for _, rev := range revs {
+		if rev.Number != number {
+			continue
+		}
+		if len(rev.Manifests) == 0 {
+			return rev, fmt.Errorf("revision %d was recorded without its manifests and cannot be applied; apply with --history-manifests to record them", number)
+		}
+		return rev, nil
+	}
+	return inventory.Revision{}, fmt.Errorf("revision %d not found in the inventory history", number)
+}
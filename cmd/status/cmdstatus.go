@@ -13,6 +13,7 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/cmd/flagutils"
 	"sigs.k8s.io/cli-utils/cmd/status/printers"
+	"sigs.k8s.io/cli-utils/cmd/status/printers/printer"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -22,6 +23,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
 	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader) *Runner {
@@ -42,6 +44,8 @@ func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory, load
 	c.Flags().StringVar(&r.output, "output", "events", "Output format.")
 	c.Flags().DurationVar(&r.timeout, "timeout", 0,
 		"How long to wait before exiting")
+	c.Flags().BoolVar(&r.lastResult, "last-result", false,
+		"If true, print the result of the last apply or destroy of each object, as recorded in the inventory, instead of polling the objects. Only supported by the events output.")
 
 	r.Command = c
 	return r
@@ -59,10 +63,11 @@ type Runner struct {
 	invFactory inventory.ClientFactory
 	loader     manifestreader.ManifestLoader
 
-	period    time.Duration
-	pollUntil string
-	timeout   time.Duration
-	output    string
+	period     time.Duration
+	pollUntil  string
+	timeout    time.Duration
+	output     string
+	lastResult bool
 
 	pollerFactoryFunc func(cmdutil.Factory) (poller.Poller, error)
 }
@@ -109,11 +114,6 @@ func (r *Runner) runE(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	statusPoller, err := r.pollerFactoryFunc(r.factory)
-	if err != nil {
-		return err
-	}
-
 	// Fetch a printer implementation based on the desired output format as
 	// specified in the output flag.
 	printer, err := printers.CreatePrinter(r.output, genericclioptions.IOStreams{
@@ -125,6 +125,15 @@ func (r *Runner) runE(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error creating printer: %w", err)
 	}
 
+	if r.lastResult {
+		return printLastResults(printer, r.output, invClient, inv, identifiers)
+	}
+
+	statusPoller, err := r.pollerFactoryFunc(r.factory)
+	if err != nil {
+		return err
+	}
+
 	// If the user has specified a timeout, we create a context with timeout,
 	// otherwise we create a context with cancel.
 	ctx := cmd.Context()
@@ -159,6 +168,27 @@ func (r *Runner) runE(cmd *cobra.Command, args []string) error {
 	return printer.Print(eventChannel, identifiers, cancelFunc)
 }
 
+// printLastResults prints the result of the last run of each object in the
+// inventory, as recorded in the inventory object, without reading the
+// objects from the cluster. Returns an error if the printer of the
+// output format can not print the results.
+func printLastResults(p printer.Printer, output string, invClient inventory.Client, inv inventory.Info,
+	identifiers object.ObjMetadataSet) error {
+	resultPrinter, ok := p.(printer.ResultPrinter)
+	if !ok {
+		return fmt.Errorf("output %q does not support --last-result", output)
+	}
+	resultClient, ok := invClient.(inventory.ResultClient)
+	if !ok {
+		return fmt.Errorf("inventory client does not record the result of the last run")
+	}
+	results, err := resultClient.GetClusterObjResults(inv)
+	if err != nil {
+		return err
+	}
+	return resultPrinter.PrintResults(identifiers, results)
+}
+
 // desiredStatusNotifierFunc returns an Observer function for the
 // ResourceStatusCollector that will cancel the context (using the cancelFunc)
 // when all resources have reached the desired status.
@@ -6,6 +6,7 @@ package status
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
@@ -256,6 +258,81 @@ deployment.apps/foo is InProgress: inProgress
 	}
 }
 
+func TestCommandLastResult(t *testing.T) {
+	testCases := map[string]struct {
+		printer        string
+		expectedErrMsg string
+		expectedOutput string
+	}{
+		"events output": {
+			printer: "events",
+			expectedOutput: `deployment.apps/foo: Apply Succeeded, reconcile Timeout
+statefulset.apps/bar: no result recorded
+`,
+		},
+		"table output is not supported": {
+			printer:        "table",
+			expectedErrMsg: `output "table" does not support --last-result`,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("namespace")
+			defer tf.Cleanup()
+
+			invClient := inventory.NewFakeClient(object.ObjMetadataSet{depObject, stsObject})
+			invClient.Results = []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(depObject),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcileTimeout,
+				},
+			}
+			runner := &Runner{
+				factory:    tf,
+				invFactory: fakeClientFactory{invClient},
+				loader:     manifestreader.NewFakeLoader(tf, invClient.Objs),
+				pollerFactoryFunc: func(c cmdutil.Factory) (poller.Poller, error) {
+					return nil, fmt.Errorf("unexpected poll")
+				},
+				output:     tc.printer,
+				lastResult: true,
+			}
+
+			cmd := &cobra.Command{
+				RunE: runner.runE,
+			}
+			cmd.SetIn(strings.NewReader(inventoryTemplate))
+			var buf bytes.Buffer
+			cmd.SetOut(&buf)
+			cmd.SetArgs([]string{})
+
+			err := cmd.Execute()
+			if tc.expectedErrMsg != "" {
+				if !assert.Error(t, err) {
+					t.FailNow()
+				}
+				assert.Contains(t, err.Error(), tc.expectedErrMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedOutput, buf.String())
+		})
+	}
+}
+
+// fakeClientFactory returns the wrapped inventory client.
+type fakeClientFactory struct {
+	client *inventory.FakeClient
+}
+
+func (f fakeClientFactory) NewClient(cmdutil.Factory) (inventory.Client, error) {
+	return f.client, nil
+}
+
 type fakePoller struct {
 	events []pollevent.Event
 }
@@ -9,6 +9,8 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -52,6 +54,26 @@ func (ep *Printer) Print(ch <-chan pollevent.Event, identifiers object.ObjMetada
 	return err
 }
 
+// PrintResults outputs the result of the last apply or destroy of each of
+// the identifiers, one per line.
+func (ep *Printer) PrintResults(identifiers object.ObjMetadataSet, results []actuation.ObjectStatus) error {
+	resultMap := make(map[object.ObjMetadata]actuation.ObjectStatus, len(results))
+	for _, result := range results {
+		resultMap[inventory.ObjMetadataFromObjectReference(result.ObjectReference)] = result
+	}
+	for _, id := range identifiers {
+		resourceID := resourceIDToString(id.GroupKind, id.Name)
+		result, found := resultMap[id]
+		if !found {
+			fmt.Fprintf(ep.IOStreams.Out, "%s: no result recorded\n", resourceID)
+			continue
+		}
+		fmt.Fprintf(ep.IOStreams.Out, "%s: %s %s, reconcile %s\n", resourceID,
+			result.Strategy, result.Actuation, result.Reconcile)
+	}
+	return nil
+}
+
 func (ep *Printer) printStatusEvent(se pollevent.Event) {
 	switch se.Type {
 	case pollevent.ResourceUpdateEvent:
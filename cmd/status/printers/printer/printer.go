@@ -4,6 +4,7 @@
 package printer
 
 import (
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/collector"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -23,3 +24,13 @@ type Printer interface {
 	// program terminates.
 	Print(ch <-chan event.Event, identifiers object.ObjMetadataSet, cancelFunc collector.ObserverFunc) error
 }
+
+// ResultPrinter is implemented by the printers that can output the result
+// of the last apply or destroy of each resource, as recorded in the
+// inventory.
+type ResultPrinter interface {
+
+	// PrintResults outputs the result of each of the identifiers. The
+	// identifiers without a result are reported as such.
+	PrintResults(identifiers object.ObjMetadataSet, results []actuation.ObjectStatus) error
+}
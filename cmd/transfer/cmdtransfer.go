@@ -0,0 +1,174 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/util/i18n"
+	"sigs.k8s.io/cli-utils/pkg/apply"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/manifestreader"
+)
+
+// GetRunner creates and returns the Runner which stores the cobra command.
+func GetRunner(factory cmdutil.Factory, invFactory inventory.ClientFactory,
+	loader manifestreader.ManifestLoader, ioStreams genericclioptions.IOStreams) *Runner {
+	r := &Runner{
+		ioStreams:  ioStreams,
+		factory:    factory,
+		invFactory: invFactory,
+		loader:     loader,
+	}
+	cmd := &cobra.Command{
+		Use:                   "transfer SOURCE_DIRECTORY TARGET_DIRECTORY",
+		DisableFlagsInUseLine: true,
+		Short:                 i18n.T("Move resources from one inventory to another"),
+		Long: i18n.T(`Move resources from one inventory to another, without deleting them.
+
+The selected resources are added to the inventory of TARGET_DIRECTORY, their
+owning-inventory annotation is set to that inventory, and they are removed from
+the inventory of SOURCE_DIRECTORY. If any of these steps fails, the changes are
+reverted. The resources are otherwise left untouched. Resources must match all
+the provided --selector, --kind and --name flags, and at least one of them is
+required.`),
+		Args: cobra.ExactArgs(2),
+		RunE: r.RunE,
+	}
+
+	cmd.Flags().StringVarP(&r.selector, "selector", "l", "",
+		"Label selector of the resources to transfer")
+	cmd.Flags().StringSliceVar(&r.kinds, "kind", nil,
+		"Kinds of the resources to transfer, as KIND or KIND.GROUP. A kind without a group matches any group.")
+	cmd.Flags().StringSliceVar(&r.names, "name", nil,
+		"Names of the resources to transfer")
+	cmd.Flags().BoolVar(&r.dryRun, "dry-run", false,
+		"Only print the resources that would be transferred")
+	cmd.Flags().DurationVar(&r.timeout, "timeout", 0,
+		"How long to wait before exiting")
+
+	r.Command = cmd
+	return r
+}
+
+// Command creates the Runner, returning the cobra command associated with it.
+func Command(f cmdutil.Factory, invFactory inventory.ClientFactory, loader manifestreader.ManifestLoader,
+	ioStreams genericclioptions.IOStreams) *cobra.Command {
+	return GetRunner(f, invFactory, loader, ioStreams).Command
+}
+
+// Runner encapsulates data necessary to run the transfer command.
+type Runner struct {
+	Command    *cobra.Command
+	ioStreams  genericclioptions.IOStreams
+	factory    cmdutil.Factory
+	invFactory inventory.ClientFactory
+	loader     manifestreader.ManifestLoader
+
+	selector string
+	kinds    []string
+	names    []string
+	dryRun   bool
+	timeout  time.Duration
+}
+
+func (r *Runner) RunE(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	// If specified, cancel with timeout.
+	if r.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	options, err := r.transfererOptions()
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the source and target inventory objects.
+	from, err := r.readInventory(cmd.InOrStdin(), args[0])
+	if err != nil {
+		return err
+	}
+	to, err := r.readInventory(cmd.InOrStdin(), args[1])
+	if err != nil {
+		return err
+	}
+
+	invClient, err := r.invFactory.NewClient(r.factory)
+	if err != nil {
+		return err
+	}
+	t, err := apply.NewTransferer(r.factory, invClient)
+	if err != nil {
+		return err
+	}
+
+	transferred, err := t.Transfer(ctx, from, to, options)
+	suffix := ""
+	if r.dryRun {
+		suffix = " (preview)"
+	}
+	for _, id := range transferred {
+		fmt.Fprintf(r.ioStreams.Out, "%s/%s transferred%s\n", strings.ToLower(id.GroupKind.String()), id.Name, suffix)
+	}
+	fmt.Fprintf(r.ioStreams.Out, "%d resource(s) transferred%s\n", len(transferred), suffix)
+	return err
+}
+
+// readInventory reads the package at the path and returns its inventory.
+func (r *Runner) readInventory(in io.Reader, path string) (inventory.Info, error) {
+	reader, err := r.loader.ManifestReader(in, path)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	invObj, _, err := inventory.SplitUnstructureds(objs)
+	if err != nil {
+		return nil, err
+	}
+	return inventory.WrapInventoryInfoObj(invObj), nil
+}
+
+// transfererOptions validates the flags and returns the TransfererOptions.
+func (r *Runner) transfererOptions() (apply.TransfererOptions, error) {
+	options := apply.TransfererOptions{
+		Names: r.names,
+	}
+	if r.selector == "" && len(r.kinds) == 0 && len(r.names) == 0 {
+		return options, fmt.Errorf("at least one of --selector, --kind or --name is required")
+	}
+	if r.selector != "" {
+		selector, err := labels.Parse(r.selector)
+		if err != nil {
+			return options, fmt.Errorf("invalid --selector: %w", err)
+		}
+		options.Selector = selector
+	}
+	for _, kind := range r.kinds {
+		gk := schema.ParseGroupKind(kind)
+		if gk.Kind == "" {
+			return options, fmt.Errorf("invalid --kind %q", kind)
+		}
+		options.GroupKinds = append(options.GroupKinds, gk)
+	}
+	if r.dryRun {
+		options.DryRunStrategy = common.DryRunClient
+	}
+	return options, nil
+}
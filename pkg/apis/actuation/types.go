@@ -9,8 +9,9 @@ import (
 )
 
 // Inventory represents the inventory object in memory.
-// Inventory is currently only used for in-memory storage and not serialized to
-// disk or to the API server.
+// Inventory is currently only used for in-memory storage. Only the object
+// statuses may be persisted to the API server, in the "status.objects" field
+// of inventory objects with a status subresource.
 type Inventory struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
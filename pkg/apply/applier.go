@@ -8,8 +8,10 @@ import (
 	"fmt"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -21,11 +23,13 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -91,23 +95,112 @@ func (a *Applier) prepareObjects(localInv inventory.Info, localObjs object.Unstr
 	if err != nil {
 		return nil, nil, err
 	}
+	// Stop before anything is changed if too many objects, or objects
+	// of protected kinds, would be pruned. Objects skipped by the prune
+	// filters are not pruned, so they are not counted.
+	if safetyObjs := filterObjects(pruneObjs, buildPruneFilters(localInv, localObjs, o)); !o.NoPrune && len(safetyObjs) > 0 {
+		invCount := 0
+		if o.PruneLimitPercent > 0 {
+			invIDs, err := a.invClient.GetClusterObjs(localInv)
+			if err != nil {
+				return nil, nil, err
+			}
+			invCount = len(invIDs)
+		}
+		err := prune.CheckSafety(safetyObjs, invCount, prune.SafetyOptions{
+			MaxCount:            o.PruneLimit,
+			MaxPercent:          o.PruneLimitPercent,
+			ProtectedGroupKinds: o.PruneProtectedGroupKinds,
+			AllowProtected:      o.ConfirmPruneProtected,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
 	return localObjs, pruneObjs, nil
 }
 
+// buildPruneFilters returns the filters of the objects that must not be pruned.
+func buildPruneFilters(invInfo inventory.Info, objs object.UnstructuredSet, o ApplierOptions) []filter.ValidationFilter {
+	return []filter.ValidationFilter{
+		filter.PreventRemoveFilter{},
+		filter.InventoryPolicyFilter{
+			Inv:       invInfo,
+			InvPolicy: o.InventoryPolicy,
+		},
+		filter.LocalNamespacesFilter{
+			LocalNamespaces: localNamespaces(invInfo, object.UnstructuredSetToObjMetadataSet(objs)),
+		},
+	}
+}
+
+// filterObjects returns the objects that pass all the filters. Objects
+// whose filtering fails are skipped, like the prune task does.
+func filterObjects(objs object.UnstructuredSet, filters []filter.ValidationFilter) object.UnstructuredSet {
+	var result object.UnstructuredSet
+	for _, obj := range objs {
+		skip := false
+		for _, f := range filters {
+			filtered, _, err := f.Filter(obj)
+			if err != nil || filtered {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
 // Run performs the Apply step. This happens asynchronously with updates
 // on progress and any errors reported back on the event channel.
 // Cancelling the operation or setting timeout on how long to Wait
 // for it complete can be done with the passed in context.
-// Note: There isn't currently any way to interrupt the operation
-// before all the given resources have been applied to the cluster. Any
-// cancellation or timeout will only affect how long we Wait for the
-// resources to become current.
+// If the context is cancelled, applies and prunes in progress are allowed
+// to finish, and the remaining objects of the running task are skipped,
+// with a Skipped event. The tasks after it do not run, except for the
+// inventory update, so the inventory matches the objects in the cluster.
 func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects object.UnstructuredSet, options ApplierOptions) <-chan event.Event {
 	klog.V(4).Infof("apply run for %d objects", len(objects))
 	eventChannel := make(chan event.Event)
 	setDefaults(&options)
 	go func() {
 		defer close(eventChannel)
+		// Lock the inventory before it is read, so that concurrent runs do
+		// not prune each other's objects.
+		if options.Lock && !options.DryRunStrategy.ClientOrServerDryRun() {
+			lock, err := lockInventory(ctx, a.invClient, invInfo, objects, options.LockOptions)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			defer releaseInventoryLock(eventChannel, lock)
+			ctx = lock.Context()
+		}
+
+		var historyClient inventory.HistoryClient
+		var manifests object.UnstructuredSet
+		if options.HistoryLimit > 0 {
+			var ok bool
+			historyClient, ok = a.invClient.(inventory.HistoryClient)
+			if !ok {
+				handleError(eventChannel, fmt.Errorf("inventory client cannot record revision history"))
+				return
+			}
+			// Record the manifests before they are changed by the run.
+			if options.HistoryManifests {
+				if err := inventory.CheckRevisionManifests(objects); err != nil {
+					handleError(eventChannel, err)
+					return
+				}
+				for _, obj := range objects {
+					manifests = append(manifests, obj.DeepCopy())
+				}
+			}
+		}
+
 		// Validate the resources to make sure we catch those problems early
 		// before anything has been updated in the cluster.
 		vCollector := &validation.Collector{}
@@ -117,6 +210,12 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 		}
 		validator.Validate(objects)
 
+		// Hooks are run, not applied, and are not added to the inventory.
+		objects, hookObjs := hook.SplitObjects(objects)
+		if err := hook.Validate(hookObjs); err != nil {
+			vCollector.Collect(err)
+		}
+
 		// Decide which objects to apply and which to prune
 		applyObjs, pruneObjs, err := a.prepareObjects(invInfo, objects, options)
 		if err != nil {
@@ -125,6 +224,16 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 		}
 		klog.V(4).Infof("calculated %d apply objs; %d prune objs", len(applyObjs), len(pruneObjs))
 
+		// The API server persists the changes to resources that don't
+		// support dry-run, so check them before running a server dry-run.
+		if options.DryRunStrategy.ServerDryRun() {
+			verifier := dryRunVerifierFactoryFunc(a.client, a.openAPIGetter)
+			if err := verifyServerDryRun(verifier, a.mapper, applyObjs, pruneObjs, hookObjs); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
+
 		// Fetch the queue (channel) of tasks that should be executed.
 		klog.V(4).Infoln("applier building task queue...")
 		taskBuilder := &solver.TaskQueueBuilder{
@@ -136,6 +245,7 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			InvClient:     a.invClient,
 			Destroy:       false,
 			Collector:     vCollector,
+			Retry:         options.Retry,
 		}
 		opts := solver.Options{
 			ServerSideOptions:      options.ServerSideOptions,
@@ -145,6 +255,19 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			PrunePropagationPolicy: options.PrunePropagationPolicy,
 			PruneTimeout:           options.PruneTimeout,
 			InventoryPolicy:        options.InventoryPolicy,
+			ApplyConcurrency:       options.ApplyConcurrency,
+			ApplyScheduler:         options.ApplyScheduler,
+			HookTimeout:            options.HookTimeout,
+
+			ReplaceOnImmutableChange: options.ReplaceOnImmutableChange,
+			MigrateClientSideApply:   options.MigrateClientSideApply,
+			ReplaceFilters: []filter.ValidationFilter{
+				filter.PreventRemoveFilter{},
+				filter.InventoryPolicyFilter{
+					Inv:       invInfo,
+					InvPolicy: options.InventoryPolicy,
+				},
+			},
 		}
 		// Build list of apply validation filters.
 		applyFilters := []filter.ValidationFilter{
@@ -157,16 +280,7 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 		}
 
 		// Build list of prune validation filters.
-		pruneFilters := []filter.ValidationFilter{
-			filter.PreventRemoveFilter{},
-			filter.InventoryPolicyFilter{
-				Inv:       invInfo,
-				InvPolicy: options.InventoryPolicy,
-			},
-			filter.LocalNamespacesFilter{
-				LocalNamespaces: localNamespaces(invInfo, object.UnstructuredSetToObjMetadataSet(objects)),
-			},
-		}
+		pruneFilters := buildPruneFilters(invInfo, objects, options)
 		// Build list of apply mutators.
 		resourceCache := cache.NewResourceCacheMap()
 		applyMutators := []mutator.Interface{
@@ -177,13 +291,34 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			},
 		}
 
+		// Read the inventory before it is updated by the run, so the objects
+		// that were not actuated can be kept in it, and so it can be reset
+		// if the apply fails.
+		prevInvIds, err := a.invClient.GetClusterObjs(invInfo)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+
 		// Build the ordered set of tasks to execute.
-		taskQueue := taskBuilder.
+		taskBuilder.
+			AppendHookTask(hook.PreApply, hookObjs, opts).
 			AppendInvAddTask(invInfo, applyObjs, options.DryRunStrategy).
 			AppendApplyWaitTasks(applyObjs, applyFilters, applyMutators, opts).
 			AppendPruneWaitTasks(pruneObjs, pruneFilters, opts).
-			AppendInvSetTask(invInfo, options.DryRunStrategy).
-			Build()
+			AppendHookTask(hook.PostApply, hookObjs, opts).
+			AppendInvSetTask(invInfo, prevInvIds, options.DryRunStrategy)
+		if options.RollbackOnFailure && !options.DryRunStrategy.ClientOrServerDryRun() {
+			// Snapshot the live objects before anything is applied, so
+			// they can be restored if the apply fails.
+			snapshots, err := a.snapshotObjects(applyObjs)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			taskBuilder.AppendRollbackTask(invInfo, applyObjs, snapshots, pruneObjs, prevInvIds, opts)
+		}
+		taskQueue := taskBuilder.Build()
 
 		klog.V(4).Infof("validation errors: %d", len(vCollector.Errors))
 		klog.V(4).Infof("invalid objects: %d", len(vCollector.InvalidIds))
@@ -213,6 +348,25 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 			taskContext.AddInvalidObject(id)
 		}
 
+		var statusClient inventory.StatusClient
+		if options.PersistStatus || options.Resume {
+			var ok bool
+			statusClient, ok = a.invClient.(inventory.StatusClient)
+			if !ok {
+				handleError(eventChannel, fmt.Errorf("inventory client cannot persist object status"))
+				return
+			}
+		}
+
+		// Skip the tasks that completed in the interrupted run.
+		if options.Resume {
+			allObjs := object.UnstructuredSetToObjMetadataSet(append(applyObjs, pruneObjs...))
+			if err := resumeTaskQueue(statusClient, invInfo, taskQueue, taskContext, allObjs); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
+
 		// Send event to inform the caller about the resources that
 		// will be applied/pruned.
 		eventChannel <- event.Event{
@@ -224,16 +378,39 @@ func (a *Applier) Run(ctx context.Context, invInfo inventory.Info, objects objec
 		// Create a new TaskStatusRunner to execute the taskQueue.
 		klog.V(4).Infoln("applier building TaskStatusRunner...")
 		allIds := object.UnstructuredSetToObjMetadataSet(append(applyObjs, pruneObjs...))
+		allIds = allIds.Union(object.UnstructuredSetToObjMetadataSet(hookObjs))
 		runner := taskrunner.NewTaskStatusRunner(allIds, a.statusPoller)
 		klog.V(4).Infoln("applier running TaskStatusRunner...")
-		err = runner.Run(ctx, taskContext, taskQueue.ToChannel(), taskrunner.Options{
+		runnerOpts := taskrunner.Options{
 			PollInterval:     options.PollInterval,
 			EmitStatusEvents: options.EmitStatusEvents,
-		})
+		}
+		if statusClient != nil {
+			runnerOpts.TaskFinished = persistObjStatus(statusClient, invInfo, options.DryRunStrategy)
+		}
+		err = runner.Run(ctx, taskContext, taskQueue.ToChannel(), runnerOpts)
 		if err != nil {
 			handleError(eventChannel, err)
 			return
 		}
+		// Forget the persisted status once every object has been applied,
+		// so that resuming a later run does not skip changed objects.
+		if statusClient != nil && runCompleted(taskContext.InventoryManager()) {
+			if err := statusClient.UpdateObjStatus(invInfo, nil, options.DryRunStrategy); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
+		// Record a revision of the inventory once every object has been
+		// applied.
+		if historyClient != nil && runCompleted(taskContext.InventoryManager()) {
+			appliedObjs := object.UnstructuredSetToObjMetadataSet(applyObjs)
+			if err := recordRevision(historyClient, invInfo, appliedObjs, manifests,
+				options.HistoryLimit, options.DryRunStrategy); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
 	}()
 	return eventChannel
 }
@@ -273,11 +450,130 @@ type ApplierOptions struct {
 	// wait.
 	PruneTimeout time.Duration
 
+	// PruneLimit defines the maximum number of objects that may be
+	// pruned in a single run. If more objects would be pruned, the run
+	// fails before anything is changed. Objects that are kept, because of
+	// their annotations or the InventoryPolicy, are not counted. If this
+	// is not provided, the number of pruned objects is not limited.
+	PruneLimit int
+
+	// PruneLimitPercent defines the maximum percentage of the objects in
+	// the inventory that may be pruned in a single run, like PruneLimit.
+	PruneLimitPercent int
+
+	// PruneProtectedGroupKinds defines the kinds of objects that are only
+	// pruned if ConfirmPruneProtected is set. If objects of these kinds
+	// would be pruned otherwise, the run fails before anything is changed.
+	// See prune.DefaultProtectedGroupKinds.
+	PruneProtectedGroupKinds []schema.GroupKind
+
+	// ConfirmPruneProtected confirms that objects of the
+	// PruneProtectedGroupKinds may be pruned.
+	ConfirmPruneProtected bool
+
 	// InventoryPolicy defines the inventory policy of apply.
 	InventoryPolicy inventory.Policy
 
 	// ValidationPolicy defines how to handle invalid objects.
 	ValidationPolicy validation.Policy
+
+	// RollbackOnFailure defines whether the applier should revert the
+	// changes made by the run if any object fails to apply or times out
+	// reconciling, or if the run is aborted. Objects are restored to their state before the run,
+	// and the inventory is reset to the previous set of objects.
+	// Ignored for dry-run.
+	RollbackOnFailure bool
+
+	// ApplyConcurrency defines the maximum number of objects in the same
+	// apply group that are applied in parallel. With the DAGScheduler,
+	// it is the maximum across all objects. If this is not provided,
+	// objects are applied one at a time.
+	ApplyConcurrency int
+
+	// ApplyScheduler defines when objects are applied, relative to their
+	// dependencies. The default LevelScheduler waits for all the objects
+	// in a level of the dependency graph to reconcile before applying the
+	// next level. The DAGScheduler applies each object as soon as all of
+	// its own dependencies are reconciled.
+	ApplyScheduler solver.Scheduler
+
+	// HookTimeout defines how long to wait for the hooks of each phase to
+	// complete. Hooks that have not completed in time are failed. If this
+	// is not provided, there is no timeout.
+	HookTimeout time.Duration
+
+	// ReplaceOnImmutableChange defines whether objects that fail to apply
+	// because an immutable field was changed should be deleted and
+	// recreated. Objects can also opt in individually with the
+	// on-immutable-change annotation. Objects are not replaced if the
+	// inventory policy or a prevent-deletion annotation would prevent
+	// pruning them. Ignored for dry-run.
+	ReplaceOnImmutableChange bool
+
+	// MigrateClientSideApply defines whether objects that were previously
+	// applied client-side are migrated to server-side apply. If an object
+	// applied server-side still has fields applied client-side, their
+	// ownership is moved to the field manager, the
+	// last-applied-configuration annotation is removed, and the object is
+	// applied again, so that fields removed from the object are also
+	// removed from the cluster. Objects that were not applied client-side
+	// are not read or applied again. Migrated
+	// objects are reported with the Migrated apply operation. Only used
+	// with server-side apply.
+	MigrateClientSideApply bool
+
+	// Retry defines how applies, prunes and inventory writes that fail
+	// with transient errors, like conflicts or throttling, are retried.
+	// Each retry is reported with a RetryEvent. The zero value does not
+	// retry.
+	Retry retry.Policy
+
+	// PersistStatus defines whether the status of the objects is persisted
+	// in the inventory object after each apply, prune and wait task, so
+	// that an interrupted run can be resumed. The persisted status is
+	// cleared when the run completes without failures. Requires an
+	// inventory client that implements inventory.StatusClient, and the run
+	// fails if the inventory object cannot store the status.
+	PersistStatus bool
+
+	// Resume defines whether the applier should continue an interrupted
+	// run, using the object status persisted in the inventory object. The
+	// apply, prune and wait tasks whose objects all completed are skipped,
+	// up to the first task with objects that did not complete, which is
+	// run again in full. The objects are expected to be the same as in the
+	// interrupted run. After a run that completed, nothing is skipped.
+	// Implies PersistStatus.
+	Resume bool
+
+	// HistoryLimit defines how many revisions of the inventory are kept.
+	// After each run that completes without failures, the applied objects
+	// are recorded as a new revision, and the oldest revisions beyond the
+	// limit are deleted. Requires an inventory client that implements
+	// inventory.HistoryClient. If this is not provided, no revision is
+	// recorded. Ignored for dry-run.
+	HistoryLimit int
+
+	// HistoryManifests defines whether the applied objects are recorded
+	// with each revision, so that the revision can be applied again. Only
+	// used with HistoryLimit. The run fails before applying any object if
+	// the objects include a Secret, because the revisions do not protect
+	// the data of Secrets.
+	HistoryManifests bool
+
+	// Lock defines whether the inventory is locked for the length of the
+	// run, so that a concurrent run on the same inventory fails with an
+	// inventory.LockHeldError instead of pruning the objects of this run.
+	// If the lock is lost during the run, the run is cancelled. The lock is
+	// created in the namespace of the inventory, which is applied first if
+	// it is one of the objects, and the run fails if it does not exist.
+	// Requires an inventory client that implements inventory.LockClient.
+	// Ignored for dry-run.
+	Lock bool
+
+	// LockOptions defines how the inventory lock is taken and renewed. If
+	// the holder is not provided, the run is identified by the hostname
+	// and a unique id. Only used with Lock.
+	LockOptions inventory.LockOptions
 }
 
 // setDefaults set the options to the default values if they
@@ -291,6 +587,35 @@ func setDefaults(o *ApplierOptions) {
 	}
 }
 
+// snapshotObjects returns the live state of the passed objects. Objects
+// that don't exist in the cluster, or whose type is not yet registered,
+// are omitted.
+func (a *Applier) snapshotObjects(objs object.UnstructuredSet) (object.UnstructuredSet, error) {
+	snapshots := object.UnstructuredSet{}
+	for _, obj := range objs {
+		id := object.UnstructuredToObjMetadata(obj)
+		mapping, err := a.mapper.RESTMapping(id.GroupKind)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				klog.V(4).Infof("skip snapshot (object: %q): resource type not registered", id)
+				continue
+			}
+			return nil, err
+		}
+		liveObj, err := a.client.Resource(mapping.Resource).Namespace(id.Namespace).
+			Get(context.TODO(), id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				klog.V(4).Infof("skip snapshot (object: %q): resource not found", id)
+				continue
+			}
+			return nil, fmt.Errorf("failed to snapshot object %q: %w", id, err)
+		}
+		snapshots = append(snapshots, liveObj)
+	}
+	return snapshots, nil
+}
+
 func handleError(eventChannel chan event.Event, err error) {
 	eventChannel <- event.Event{
 		Type: event.ErrorType,
@@ -13,9 +13,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
@@ -1675,25 +1677,24 @@ func TestApplierCancel(t *testing.T) {
 						Type:      event.Finished, // TODO: add Cancelled event type
 					},
 				},
-				// TODO: Update the inventory after cancellation
-				// {
-				// 	// InvSetTask start
-				// 	EventType: event.ActionGroupType,
-				// 	ActionGroupEvent: &testutil.ExpActionGroupEvent{
-				// 		Action:    event.InventoryAction,
-				// 		GroupName: "inventory-set-0",
-				// 		Type:      event.Started,
-				// 	},
-				// },
-				// {
-				// 	// InvSetTask finished
-				// 	EventType: event.ActionGroupType,
-				// 	ActionGroupEvent: &testutil.ExpActionGroupEvent{
-				// 		Action:    event.InventoryAction,
-				// 		GroupName: "inventory-set-0",
-				// 		Type:      event.Finished,
-				// 	},
-				// },
+				{
+					// InvSetTask start
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						Action:    event.InventoryAction,
+						GroupName: "inventory-set-0",
+						Type:      event.Started,
+					},
+				},
+				{
+					// InvSetTask finished
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						Action:    event.InventoryAction,
+						GroupName: "inventory-set-0",
+						Type:      event.Finished,
+					},
+				},
 				{
 					// Error
 					EventType: event.ErrorType,
@@ -1966,6 +1967,14 @@ func TestReadAndPrepareObjects(t *testing.T) {
 	obj1 := testutil.Unstructured(t, resources["obj1"])
 	obj2 := testutil.Unstructured(t, resources["obj2"])
 	clusterScopedObj := testutil.Unstructured(t, resources["clusterScopedObj"])
+	// Objects owned by the inventory are pruned, unless annotated to be
+	// kept.
+	obj2Owned := testutil.Unstructured(t, resources["obj2"], testutil.AddOwningInv(t, inventory.ID()))
+	clusterScopedObjOwned := testutil.Unstructured(t, resources["clusterScopedObj"], testutil.AddOwningInv(t, inventory.ID()))
+	obj2Keep := obj2Owned.DeepCopy()
+	annotations := obj2Keep.GetAnnotations()
+	annotations[common.OnRemoveAnnotation] = common.OnRemoveKeep
+	obj2Keep.SetAnnotations(annotations)
 
 	testCases := map[string]struct {
 		// objects in the cluster
@@ -1974,6 +1983,8 @@ func TestReadAndPrepareObjects(t *testing.T) {
 		invInfo inventoryInfo
 		// resources input to applier
 		resources object.UnstructuredSet
+		// options input to applier
+		options ApplierOptions
 		// expected objects to apply
 		applyObjs object.UnstructuredSet
 		// expected objects to prune
@@ -2050,6 +2061,122 @@ func TestReadAndPrepareObjects(t *testing.T) {
 			applyObjs: object.UnstructuredSet{obj1, obj2, clusterScopedObj},
 			pruneObjs: object.UnstructuredSet{},
 		},
+		"prune within limits, prune old": {
+			clusterObjs: object.UnstructuredSet{obj2Owned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(obj1),
+					object.UnstructuredToObjMetadata(obj2),
+				},
+			},
+			resources: object.UnstructuredSet{obj1},
+			options: ApplierOptions{
+				PruneLimit:        1,
+				PruneLimitPercent: 50,
+			},
+			applyObjs: object.UnstructuredSet{obj1},
+			pruneObjs: object.UnstructuredSet{obj2Owned},
+		},
+		"prune limit exceeded, error": {
+			clusterObjs: object.UnstructuredSet{obj2Owned, clusterScopedObjOwned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(obj2),
+					object.UnstructuredToObjMetadata(clusterScopedObj),
+				},
+			},
+			options: ApplierOptions{
+				PruneLimit: 1,
+			},
+			isError: true,
+		},
+		"prune percentage exceeded, error": {
+			clusterObjs: object.UnstructuredSet{obj2Owned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(obj1),
+					object.UnstructuredToObjMetadata(obj2),
+				},
+			},
+			resources: object.UnstructuredSet{obj1},
+			options: ApplierOptions{
+				PruneLimitPercent: 49,
+			},
+			isError: true,
+		},
+		"prune protected kind without confirmation, error": {
+			clusterObjs: object.UnstructuredSet{clusterScopedObjOwned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(clusterScopedObj),
+				},
+			},
+			options: ApplierOptions{
+				PruneProtectedGroupKinds: []schema.GroupKind{clusterScopedObj.GroupVersionKind().GroupKind()},
+			},
+			isError: true,
+		},
+		"prune protected kind with confirmation, prune old": {
+			clusterObjs: object.UnstructuredSet{clusterScopedObjOwned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(clusterScopedObj),
+				},
+			},
+			options: ApplierOptions{
+				PruneProtectedGroupKinds: []schema.GroupKind{clusterScopedObj.GroupVersionKind().GroupKind()},
+				ConfirmPruneProtected:    true,
+			},
+			pruneObjs: object.UnstructuredSet{clusterScopedObjOwned},
+		},
+		"prune limit exceeded only with kept objects, prune old": {
+			clusterObjs: object.UnstructuredSet{obj2Keep, clusterScopedObjOwned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(obj2),
+					object.UnstructuredToObjMetadata(clusterScopedObj),
+				},
+			},
+			options: ApplierOptions{
+				PruneLimit: 1,
+			},
+			pruneObjs: object.UnstructuredSet{obj2Keep, clusterScopedObjOwned},
+		},
+		"prune limit exceeded with no prune, prune none": {
+			clusterObjs: object.UnstructuredSet{obj2Owned, clusterScopedObjOwned},
+			invInfo: inventoryInfo{
+				name:      inventory.Name(),
+				namespace: inventory.Namespace(),
+				id:        inventory.ID(),
+				set: object.ObjMetadataSet{
+					object.UnstructuredToObjMetadata(obj2),
+					object.UnstructuredToObjMetadata(clusterScopedObj),
+				},
+			},
+			options: ApplierOptions{
+				NoPrune:    true,
+				PruneLimit: 1,
+			},
+			pruneObjs: object.UnstructuredSet{obj2Owned, clusterScopedObjOwned},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -2062,7 +2189,7 @@ func TestReadAndPrepareObjects(t *testing.T) {
 				newFakePoller([]pollevent.Event{}),
 			)
 
-			applyObjs, pruneObjs, err := applier.prepareObjects(tc.invInfo.toWrapped(), tc.resources, ApplierOptions{})
+			applyObjs, pruneObjs, err := applier.prepareObjects(tc.invInfo.toWrapped(), tc.resources, tc.options)
 			if tc.isError {
 				assert.Error(t, err)
 				return
@@ -2079,3 +2206,28 @@ func TestReadAndPrepareObjects(t *testing.T) {
 		})
 	}
 }
+
+func TestApplierInventoryReadError(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	applier := newTestApplier(t, invInfo,
+		object.UnstructuredSet{deployment},
+		object.UnstructuredSet{},
+		newFakePoller([]pollevent.Event{}))
+	// The objects to prune are read by the pruner, which still succeeds.
+	readErr := fmt.Errorf("inventory read failed")
+	applier.invClient = errorInventoryClient{Client: applier.invClient, err: readErr}
+
+	var events []event.Event
+	for e := range applier.Run(context.Background(), invInfo.toWrapped(), object.UnstructuredSet{deployment}, ApplierOptions{}) {
+		events = append(events, e)
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, event.ErrorType, events[0].Type)
+		assert.Equal(t, readErr, events[0].ErrorEvent.Err)
+	}
+}
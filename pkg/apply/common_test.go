@@ -473,3 +473,13 @@ func (jps JSONPathSetter) Mutate(u *unstructured.Unstructured) {
 		panic(fmt.Sprintf("failed to mutate unstructured object: %v", err))
 	}
 }
+
+// errorInventoryClient fails to read the objects in the inventory.
+type errorInventoryClient struct {
+	inventory.Client
+	err error
+}
+
+func (c errorInventoryClient) GetClusterObjs(inventory.Info) (object.ObjMetadataSet, error) {
+	return nil, c.err
+}
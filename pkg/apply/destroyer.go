@@ -17,12 +17,14 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/solver"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/kstatus/polling"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -85,6 +87,41 @@ type DestroyerOptions struct {
 
 	// ValidationPolicy defines how to handle invalid objects.
 	ValidationPolicy validation.Policy
+
+	// HookObjects are the objects to run as pre-delete and post-delete
+	// hooks. Objects without the hook annotation are ignored, because
+	// the objects to delete are read from the inventory.
+	HookObjects object.UnstructuredSet
+
+	// HookTimeout defines how long to wait for the hooks of each phase to
+	// complete. Hooks that have not completed in time are failed. If this
+	// is not provided, there is no timeout.
+	HookTimeout time.Duration
+
+	// Retry defines how deletes and inventory writes that fail with
+	// transient errors, like conflicts or throttling, are retried. Each
+	// retry is reported with a RetryEvent. The zero value does not retry.
+	Retry retry.Policy
+
+	// RemoveFinalizers are the names of the finalizers to remove from the
+	// deleted objects that are still terminating after RemoveFinalizersAfter,
+	// like the finalizers of controllers that were already deleted. Objects
+	// blocked by other finalizers are still reported, but not changed. If
+	// this is not provided, no finalizers are removed.
+	RemoveFinalizers []string
+
+	// RemoveFinalizersAfter defines how long after the deletion of an object
+	// its finalizers are removed.
+	RemoveFinalizersAfter time.Duration
+
+	// Lock defines whether the inventory is locked for the length of the
+	// run, like ApplierOptions.Lock. The run fails if the namespace of the
+	// inventory does not exist. Ignored for dry-run.
+	Lock bool
+
+	// LockOptions defines how the inventory lock is taken and renewed.
+	// Only used with Lock.
+	LockOptions inventory.LockOptions
 }
 
 func setDestroyerDefaults(o *DestroyerOptions) {
@@ -98,12 +135,25 @@ func setDestroyerDefaults(o *DestroyerOptions) {
 
 // Run performs the destroy step. Passes the inventory object. This
 // happens asynchronously on progress and any errors are reported
-// back on the event channel.
+// back on the event channel. If the context is cancelled, the objects
+// that have not been deleted yet are skipped, and the inventory is
+// updated to the objects that remain, instead of being deleted.
 func (d *Destroyer) Run(ctx context.Context, inv inventory.Info, options DestroyerOptions) <-chan event.Event {
 	eventChannel := make(chan event.Event)
 	setDestroyerDefaults(&options)
 	go func() {
 		defer close(eventChannel)
+		// Lock the inventory before it is read, so that concurrent runs do
+		// not change it at the same time.
+		if options.Lock && !options.DryRunStrategy.ClientOrServerDryRun() {
+			lock, err := lockInventory(ctx, d.invClient, inv, nil, options.LockOptions)
+			if err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+			defer releaseInventoryLock(eventChannel, lock)
+			ctx = lock.Context()
+		}
 		// Retrieve the objects to be deleted from the cluster. Second parameter is empty
 		// because no local objects returns all inventory objects for deletion.
 		emptyLocalObjs := object.UnstructuredSet{}
@@ -129,12 +179,28 @@ func (d *Destroyer) Run(ctx context.Context, inv inventory.Info, options Destroy
 		}
 		validator.Validate(deleteObjs)
 
+		_, hookObjs := hook.SplitObjects(options.HookObjects)
+		validator.Validate(hookObjs)
+		if err := hook.Validate(hookObjs); err != nil {
+			vCollector.Collect(err)
+		}
+
 		klog.V(4).Infoln("destroyer building task queue...")
 		dynamicClient, err := d.factory.DynamicClient()
 		if err != nil {
 			handleError(eventChannel, err)
 			return
 		}
+
+		// The API server persists the changes to resources that don't
+		// support dry-run, so check them before running a server dry-run.
+		if options.DryRunStrategy.ServerDryRun() {
+			verifier := dryRunVerifierFactoryFunc(dynamicClient, d.factory.OpenAPIGetter())
+			if err := verifyServerDryRun(verifier, mapper, deleteObjs, hookObjs); err != nil {
+				handleError(eventChannel, err)
+				return
+			}
+		}
 		taskBuilder := &solver.TaskQueueBuilder{
 			Pruner:        d.pruner,
 			DynamicClient: dynamicClient,
@@ -144,12 +210,16 @@ func (d *Destroyer) Run(ctx context.Context, inv inventory.Info, options Destroy
 			InvClient:     d.invClient,
 			Destroy:       true,
 			Collector:     vCollector,
+			Retry:         options.Retry,
 		}
 		opts := solver.Options{
 			Prune:                  true,
 			PruneTimeout:           options.DeleteTimeout,
 			DryRunStrategy:         options.DryRunStrategy,
 			PrunePropagationPolicy: options.DeletePropagationPolicy,
+			HookTimeout:            options.HookTimeout,
+			RemoveFinalizers:       options.RemoveFinalizers,
+			RemoveFinalizersAfter:  options.RemoveFinalizersAfter,
 		}
 		deleteFilters := []filter.ValidationFilter{
 			filter.PreventRemoveFilter{},
@@ -159,10 +229,20 @@ func (d *Destroyer) Run(ctx context.Context, inv inventory.Info, options Destroy
 			},
 		}
 
+		// Read the inventory before the objects are deleted, so the objects
+		// that were not deleted can be kept in it.
+		prevInvIds, err := d.invClient.GetClusterObjs(inv)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+
 		// Build the ordered set of tasks to execute.
 		taskQueue := taskBuilder.
+			AppendHookTask(hook.PreDelete, hookObjs, opts).
 			AppendPruneWaitTasks(deleteObjs, deleteFilters, opts).
-			AppendDeleteInvTask(inv, options.DryRunStrategy).
+			AppendDeleteInvTask(inv, prevInvIds, options.DryRunStrategy).
+			AppendHookTask(hook.PostDelete, hookObjs, opts).
 			Build()
 
 		klog.V(4).Infof("validation errors: %d", len(vCollector.Errors))
@@ -205,6 +285,7 @@ func (d *Destroyer) Run(ctx context.Context, inv inventory.Info, options Destroy
 		// Create a new TaskStatusRunner to execute the taskQueue.
 		klog.V(4).Infoln("destroyer building TaskStatusRunner...")
 		deleteIds := object.UnstructuredSetToObjMetadataSet(deleteObjs)
+		deleteIds = deleteIds.Union(object.UnstructuredSetToObjMetadataSet(hookObjs))
 		runner := taskrunner.NewTaskStatusRunner(deleteIds, d.StatusPoller)
 		klog.V(4).Infoln("destroyer running TaskStatusRunner...")
 		err = runner.Run(ctx, taskContext, taskQueue.ToChannel(), taskrunner.Options{
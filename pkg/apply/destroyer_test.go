@@ -5,6 +5,7 @@ package apply
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -153,6 +154,25 @@ func TestDestroyerCancel(t *testing.T) {
 				},
 				// Inventory cannot be deleted, because the objects still exist,
 				// even tho they've been deleted (ex: blocked by finalizer).
+				// The inventory is updated instead.
+				{
+					// DeleteInvTask start
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						Action:    event.InventoryAction,
+						GroupName: "delete-inventory-0",
+						Type:      event.Started,
+					},
+				},
+				{
+					// DeleteInvTask finished
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						Action:    event.InventoryAction,
+						GroupName: "delete-inventory-0",
+						Type:      event.Finished,
+					},
+				},
 				{
 					// Error
 					EventType: event.ErrorType,
@@ -389,3 +409,30 @@ func TestDestroyerCancel(t *testing.T) {
 		})
 	}
 }
+
+func TestDestroyerInventoryReadError(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "test",
+		id:        "test",
+		set: object.ObjMetadataSet{
+			testutil.ToIdentifier(t, resources["deployment"]),
+		},
+	}
+	clusterObjs := object.UnstructuredSet{
+		testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+	}
+	destroyer := newTestDestroyer(t, invInfo, clusterObjs, newFakePoller([]pollevent.Event{}))
+	// The objects to delete are read by the pruner, which still succeeds.
+	readErr := errors.New("inventory read failed")
+	destroyer.invClient = errorInventoryClient{Client: destroyer.invClient, err: readErr}
+
+	var events []event.Event
+	for e := range destroyer.Run(context.Background(), invInfo.toWrapped(), DestroyerOptions{}) {
+		events = append(events, e)
+	}
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, event.ErrorType, events[0].Type)
+		assert.Equal(t, readErr, events[0].ErrorEvent.Err)
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// NewDetacher returns a new Detacher.
+func NewDetacher(factory cmdutil.Factory, invClient inventory.Client) (*Detacher, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Detacher{
+		client:    client,
+		mapper:    mapper,
+		invClient: invClient,
+	}, nil
+}
+
+// Detacher removes objects from an inventory without deleting them, so
+// that they can be adopted by another inventory, or managed by other means.
+type Detacher struct {
+	client    dynamic.Interface
+	mapper    meta.RESTMapper
+	invClient inventory.Client
+}
+
+// DetacherOptions selects the objects to detach. An object must match all
+// the provided selectors to be detached. At least one selector must be
+// provided.
+type DetacherOptions struct {
+	// Selector matches the labels of the live objects.
+	Selector labels.Selector
+
+	// GroupKinds matches the objects of any of these kinds. A GroupKind
+	// without a group matches the kind in any group.
+	GroupKinds []schema.GroupKind
+
+	// Names matches the objects with any of these names.
+	Names []string
+
+	// DryRunStrategy defines whether changes should actually be performed,
+	// or if it is just talk and no action.
+	DryRunStrategy common.DryRunStrategy
+}
+
+// Detach removes the selected objects from the inventory, and removes the
+// owning-inventory annotation from the live objects owned by the inventory.
+// The live objects are otherwise left untouched. Objects that no longer
+// exist are removed from the inventory too. Returns the detached objects.
+// Objects whose annotation could not be removed are kept in the inventory,
+// and the errors are returned after the other objects were detached.
+func (d *Detacher) Detach(ctx context.Context, inv inventory.Info, options DetacherOptions) (object.ObjMetadataSet, error) {
+	if options.Selector == nil && len(options.GroupKinds) == 0 && len(options.Names) == 0 {
+		return nil, fmt.Errorf("no objects selected to detach")
+	}
+	ids, err := d.invClient.GetClusterObjs(inv)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("detach run for %d objects", len(ids))
+
+	var detached object.ObjMetadataSet
+	var errs []error
+	for _, id := range ids {
+		if !matchesGroupKinds(id, options.GroupKinds) || !matchesNames(id, options.Names) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		obj, err := getLiveObject(ctx, d.client, d.mapper, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if obj == nil {
+			// The object is gone, so only the inventory needs updating,
+			// unless it must match labels that can't be checked anymore.
+			if options.Selector == nil {
+				detached = append(detached, id)
+			}
+			continue
+		}
+		if options.Selector != nil && !options.Selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if err := d.removeInventoryAnnotation(ctx, inv, obj, options.DryRunStrategy); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		detached = append(detached, id)
+	}
+
+	if len(detached) > 0 {
+		klog.V(4).Infof("removing %d detached objects from the inventory", len(detached))
+		if err := d.invClient.Replace(inv, ids.Diff(detached), options.DryRunStrategy); err != nil {
+			return nil, err
+		}
+	}
+	return detached, utilerrors.NewAggregate(errs)
+}
+
+// getLiveObject returns the live object, or nil if it or its type does
+// not exist.
+func getLiveObject(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper,
+	id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	mapping, err := mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The type was removed, so the object is gone too.
+			return nil, nil
+		}
+		return nil, err
+	}
+	obj, err := client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get object %s: %w", id, err)
+	}
+	return obj, nil
+}
+
+// removeInventoryAnnotation removes the owning-inventory annotation from
+// the live object, if it is owned by the inventory. The annotation of
+// objects owned by other inventories is kept.
+func (d *Detacher) removeInventoryAnnotation(ctx context.Context, inv inventory.Info,
+	obj *unstructured.Unstructured, dryRun common.DryRunStrategy) error {
+	id := object.UnstructuredToObjMetadata(obj)
+	if inventory.IDMatch(inv, obj) != inventory.Match {
+		klog.V(4).Infof("object not owned by the inventory, keeping annotation (object: %q)", id)
+		return nil
+	}
+	if dryRun.ClientDryRun() {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				inventory.OwningInventoryKey: nil,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	mapping, err := d.mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return err
+	}
+	opts := metav1.PatchOptions{}
+	if dryRun.ServerDryRun() {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	klog.V(4).Infof("removing annotation (object: %q, annotation: %q)", id, inventory.OwningInventoryKey)
+	_, err = d.client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Patch(ctx, id.Name, types.MergePatchType, patch, opts)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove annotation %s from %s: %w", inventory.OwningInventoryKey, id, err)
+	}
+	return nil
+}
+
+// matchesGroupKinds returns true if the object is of any of the kinds, or
+// if no kinds are provided.
+func matchesGroupKinds(id object.ObjMetadata, gks []schema.GroupKind) bool {
+	if len(gks) == 0 {
+		return true
+	}
+	for _, gk := range gks {
+		if strings.EqualFold(gk.Kind, id.GroupKind.Kind) &&
+			(gk.Group == "" || gk.Group == id.GroupKind.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNames returns true if the object has any of the names, or if no
+// names are provided.
+func matchesNames(id object.ObjMetadata, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if name == id.Name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestDetacherDetach(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	pod1ID := testutil.ToIdentifier(t, resources["obj1"])
+	pod2ID := testutil.ToIdentifier(t, resources["obj2"])
+	invIDs := object.ObjMetadataSet{depID, secretID, pod1ID, pod2ID}
+
+	testCases := map[string]struct {
+		options          DetacherOptions
+		expectedDetached object.ObjMetadataSet
+		expectedInv      object.ObjMetadataSet
+		expectedOwners   map[object.ObjMetadata]string
+		expectedErr      string
+	}{
+		"nothing selected": {
+			options:     DetacherOptions{},
+			expectedInv: invIDs,
+			expectedErr: "no objects selected to detach",
+		},
+		"by kind": {
+			options: DetacherOptions{
+				GroupKinds: []schema.GroupKind{{Kind: "deployment"}},
+			},
+			expectedDetached: object.ObjMetadataSet{depID},
+			expectedInv:      object.ObjMetadataSet{secretID, pod1ID, pod2ID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "",
+				secretID: "test",
+			},
+		},
+		"by kind in other group": {
+			options: DetacherOptions{
+				GroupKinds: []schema.GroupKind{{Group: "extensions", Kind: "Deployment"}},
+			},
+			expectedInv: invIDs,
+			expectedOwners: map[object.ObjMetadata]string{
+				depID: "test",
+			},
+		},
+		"by name, including missing object": {
+			options: DetacherOptions{
+				Names: []string{"secret", "obj2"},
+			},
+			expectedDetached: object.ObjMetadataSet{secretID, pod2ID},
+			expectedInv:      object.ObjMetadataSet{depID, pod1ID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "test",
+				secretID: "",
+			},
+		},
+		"by selector": {
+			options: DetacherOptions{
+				Selector: labels.SelectorFromSet(labels.Set{"app": "web"}),
+			},
+			expectedDetached: object.ObjMetadataSet{depID},
+			expectedInv:      object.ObjMetadataSet{secretID, pod1ID, pod2ID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "",
+				secretID: "test",
+			},
+		},
+		"owned by other inventory": {
+			options: DetacherOptions{
+				GroupKinds: []schema.GroupKind{{Kind: "Pod"}},
+				Names:      []string{"obj1"},
+			},
+			expectedDetached: object.ObjMetadataSet{pod1ID},
+			expectedInv:      object.ObjMetadataSet{depID, secretID, pod2ID},
+			expectedOwners: map[object.ObjMetadata]string{
+				pod1ID: "other",
+			},
+		},
+		"dry-run": {
+			options: DetacherOptions{
+				GroupKinds:     []schema.GroupKind{{Group: "apps", Kind: "Deployment"}},
+				DryRunStrategy: common.DryRunClient,
+			},
+			expectedDetached: object.ObjMetadataSet{depID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID: "test",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dep := testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test"))
+			dep.SetLabels(map[string]string{"app": "web"})
+			secret := testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "test"))
+			pod1 := testutil.Unstructured(t, resources["obj1"], testutil.AddOwningInv(t, "other"))
+
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, []runtime.Object{dep, secret, pod1}...)
+			mapper := testutil.NewFakeRESTMapper(
+				schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"},
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+			)
+			invClient := inventory.NewFakeClient(invIDs)
+			d := &Detacher{
+				client:    client,
+				mapper:    mapper,
+				invClient: invClient,
+			}
+			inv := inventoryInfo{name: "abc-123", namespace: "default", id: "test"}.toWrapped()
+
+			detached, err := d.Detach(context.TODO(), inv, tc.options)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedDetached, detached)
+			if tc.expectedInv != nil {
+				testutil.AssertEqual(t, tc.expectedInv, invClient.Objs)
+			}
+
+			for id, owner := range tc.expectedOwners {
+				mapping, err := mapper.RESTMapping(id.GroupKind)
+				require.NoError(t, err)
+				obj, err := client.Resource(mapping.Resource).Namespace(id.Namespace).
+					Get(context.TODO(), id.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, owner, obj.GetAnnotations()[inventory.OwningInventoryKey], "owner of %s", id)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/drift"
+)
+
+// driftGroupName is the name of the action group of the drift events.
+const driftGroupName = "drift-0"
+
+// NewDrifter returns a new Drifter.
+func NewDrifter(factory cmdutil.Factory, invClient inventory.Client) (*Drifter, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Drifter{
+		client:    client,
+		mapper:    mapper,
+		invClient: invClient,
+	}, nil
+}
+
+// Drifter compares the live state of the objects in an inventory with
+// their last applied state, to detect changes made outside of apply.
+type Drifter struct {
+	client    dynamic.Interface
+	mapper    meta.RESTMapper
+	invClient inventory.Client
+}
+
+// DrifterOptions defines how drift is detected.
+type DrifterOptions struct {
+	// FieldManager is the field manager that applied the objects with
+	// server-side apply. If this is not provided, the default is to use
+	// the kubectl field manager.
+	FieldManager string
+
+	// IgnoreManagers are field managers whose changes are not reported
+	// as drift, like controllers that update the objects they manage.
+	IgnoreManagers []string
+}
+
+func setDrifterDefaults(o *DrifterOptions) {
+	if o.FieldManager == "" {
+		o.FieldManager = common.DefaultFieldManager
+	}
+}
+
+// Run checks the objects in the inventory for drift. This happens
+// asynchronously, and a DriftEvent for each object and any errors are
+// reported back on the event channel. All objects are reported in a
+// single action group.
+func (d *Drifter) Run(ctx context.Context, inv inventory.Info, options DrifterOptions) <-chan event.Event {
+	eventChannel := make(chan event.Event)
+	setDrifterDefaults(&options)
+	go func() {
+		defer close(eventChannel)
+		ids, err := d.invClient.GetClusterObjs(inv)
+		if err != nil {
+			handleError(eventChannel, err)
+			return
+		}
+		klog.V(4).Infof("drift run for %d objects", len(ids))
+
+		eventChannel <- event.Event{
+			Type: event.InitType,
+			InitEvent: event.InitEvent{
+				ActionGroups: event.ActionGroupList{
+					{
+						Name:        driftGroupName,
+						Action:      event.DriftAction,
+						Identifiers: ids,
+					},
+				},
+			},
+		}
+		eventChannel <- driftActionGroupEvent(event.Started)
+		for _, id := range ids {
+			if ctx.Err() != nil {
+				handleError(eventChannel, ctx.Err())
+				return
+			}
+			eventChannel <- d.detect(ctx, id, options)
+		}
+		eventChannel <- driftActionGroupEvent(event.Finished)
+	}()
+	return eventChannel
+}
+
+// detect compares a single object with its last applied state, and
+// returns the DriftEvent with the result.
+func (d *Drifter) detect(ctx context.Context, id object.ObjMetadata, options DrifterOptions) event.Event {
+	e := event.Event{
+		Type: event.DriftType,
+		DriftEvent: event.DriftEvent{
+			GroupName:  driftGroupName,
+			Identifier: id,
+		},
+	}
+	mapping, err := d.mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The type was removed, so the object is gone too.
+			e.DriftEvent.Operation = event.DriftMissing
+			return e
+		}
+		e.DriftEvent.Error = err
+		return e
+	}
+	live, err := d.client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			e.DriftEvent.Operation = event.DriftMissing
+			return e
+		}
+		e.DriftEvent.Error = fmt.Errorf("failed to get object: %w", err)
+		return e
+	}
+	result, err := drift.Detect(live, drift.Options{
+		FieldManager:   options.FieldManager,
+		IgnoreManagers: options.IgnoreManagers,
+	})
+	if err != nil {
+		e.DriftEvent.Error = err
+		return e
+	}
+	e.DriftEvent.Source = result.Source.String()
+	e.DriftEvent.Fields = result.Fields
+	switch {
+	case result.Source == drift.SourceNone:
+		e.DriftEvent.Operation = event.DriftUnknown
+	case result.Drifted():
+		e.DriftEvent.Operation = event.DriftDetected
+	default:
+		e.DriftEvent.Operation = event.DriftInSync
+	}
+	return e
+}
+
+func driftActionGroupEvent(t event.ActionGroupEventType) event.Event {
+	return event.Event{
+		Type: event.ActionGroupType,
+		ActionGroupEvent: event.ActionGroupEvent{
+			GroupName: driftGroupName,
+			Action:    event.DriftAction,
+			Type:      t,
+		},
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestDrifterRun(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	podID := testutil.ToIdentifier(t, resources["obj1"])
+
+	testCases := map[string]struct {
+		lastApplied    string
+		expectedDepOp  event.DriftEventOperation
+		expectedFields []string
+	}{
+		"in sync": {
+			lastApplied:   `{"spec":{"replicas":1}}`,
+			expectedDepOp: event.DriftInSync,
+		},
+		"drifted": {
+			lastApplied:    `{"spec":{"replicas":3}}`,
+			expectedDepOp:  event.DriftDetected,
+			expectedFields: []string{".spec.replicas"},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dep := testutil.Unstructured(t, resources["deployment"])
+			dep.SetAnnotations(map[string]string{
+				v1.LastAppliedConfigAnnotation: tc.lastApplied,
+			})
+			// The secret was not applied; the pod was deleted.
+			secret := testutil.Unstructured(t, resources["secret"])
+
+			d := &Drifter{
+				client: fake.NewSimpleDynamicClient(scheme.Scheme, []runtime.Object{dep, secret}...),
+				mapper: testutil.NewFakeRESTMapper(
+					schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"},
+					schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+				),
+				invClient: inventory.NewFakeClient(object.ObjMetadataSet{depID, secretID, podID}),
+			}
+
+			var events []event.Event
+			for e := range d.Run(context.TODO(), nil, DrifterOptions{}) {
+				events = append(events, e)
+			}
+
+			expected := []testutil.ExpEvent{
+				{
+					EventType: event.InitType,
+					InitEvent: &testutil.ExpInitEvent{},
+				},
+				{
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						GroupName: driftGroupName,
+						Action:    event.DriftAction,
+						Type:      event.Started,
+					},
+				},
+				{
+					EventType: event.DriftType,
+					DriftEvent: &testutil.ExpDriftEvent{
+						GroupName:  driftGroupName,
+						Identifier: depID,
+						Operation:  tc.expectedDepOp,
+					},
+				},
+				{
+					EventType: event.DriftType,
+					DriftEvent: &testutil.ExpDriftEvent{
+						GroupName:  driftGroupName,
+						Identifier: secretID,
+						Operation:  event.DriftUnknown,
+					},
+				},
+				{
+					EventType: event.DriftType,
+					DriftEvent: &testutil.ExpDriftEvent{
+						GroupName:  driftGroupName,
+						Identifier: podID,
+						Operation:  event.DriftMissing,
+					},
+				},
+				{
+					EventType: event.ActionGroupType,
+					ActionGroupEvent: &testutil.ExpActionGroupEvent{
+						GroupName: driftGroupName,
+						Action:    event.DriftAction,
+						Type:      event.Finished,
+					},
+				},
+			}
+			assert.NoError(t, testutil.VerifyEvents(expected, events))
+			assert.Equal(t, tc.expectedFields, events[2].DriftEvent.Fields)
+		})
+	}
+}
@@ -2,6 +2,13 @@
 // SPDX-License-Identifier: Apache-2.0
 package error
 
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
 type UnknownTypeError struct {
 	err error
 }
@@ -37,3 +44,23 @@ func (e *InitializeApplyOptionError) Error() string {
 func NewInitializeApplyOptionError(err error) *InitializeApplyOptionError {
 	return &InitializeApplyOptionError{err: err}
 }
+
+// DryRunUnsupportedError is returned before a server-side dry-run, if
+// some of the resources do not support dry-run. The API server would
+// persist the changes to these resources instead.
+type DryRunUnsupportedError struct {
+	Resources []schema.GroupVersionResource
+}
+
+func (e *DryRunUnsupportedError) Error() string {
+	resources := make([]string, len(e.Resources))
+	for i, gvr := range e.Resources {
+		resources[i] = fmt.Sprintf("%s/%s", gvr.GroupVersion(), gvr.Resource)
+	}
+	return fmt.Sprintf("server-side dry-run is not supported by resources: %s",
+		strings.Join(resources, ", "))
+}
+
+func NewDryRunUnsupportedError(resources []schema.GroupVersionResource) *DryRunUnsupportedError {
+	return &DryRunUnsupportedError{Resources: resources}
+}
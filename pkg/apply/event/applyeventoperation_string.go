@@ -13,11 +13,14 @@ func _() {
 	_ = x[Created-2]
 	_ = x[Unchanged-3]
 	_ = x[Configured-4]
+	_ = x[Replaced-5]
+	_ = x[ApplySkipped-6]
+	_ = x[Migrated-7]
 }
 
-const _ApplyEventOperation_name = "ApplyUnspecifiedServersideAppliedCreatedUnchangedConfigured"
+const _ApplyEventOperation_name = "ApplyUnspecifiedServersideAppliedCreatedUnchangedConfiguredReplacedApplySkippedMigrated"
 
-var _ApplyEventOperation_index = [...]uint8{0, 16, 33, 40, 49, 59}
+var _ApplyEventOperation_index = [...]uint8{0, 16, 33, 40, 49, 59, 67, 79, 87}
 
 func (i ApplyEventOperation) String() string {
 	if i < 0 || i >= ApplyEventOperation(len(_ApplyEventOperation_index)-1) {
@@ -6,6 +6,7 @@ package event
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
@@ -26,6 +27,10 @@ const (
 	DeleteType
 	WaitType
 	ValidationType
+	RollbackType
+	HookType
+	DriftType
+	RetryType
 )
 
 // Event is the type of the objects that will be returned through
@@ -69,6 +74,22 @@ type Event struct {
 
 	// ValidationEvent contains information about validation errors.
 	ValidationEvent ValidationEvent
+
+	// RollbackEvent contains information about objects that have been
+	// reverted after a failed apply.
+	RollbackEvent RollbackEvent
+
+	// HookEvent contains information about hook objects that have been
+	// run at a point of the apply or destroy lifecycle.
+	HookEvent HookEvent
+
+	// DriftEvent contains information about objects whose live state
+	// differs from the last applied state.
+	DriftEvent DriftEvent
+
+	// RetryEvent contains information about operations that failed with
+	// a transient error and will be retried.
+	RetryEvent RetryEvent
 }
 
 // String returns a string suitable for logging
@@ -94,6 +115,14 @@ func (e Event) String() string {
 		sb.WriteString(e.WaitEvent.String())
 	case ValidationType:
 		sb.WriteString(e.ValidationEvent.String())
+	case RollbackType:
+		sb.WriteString(e.RollbackEvent.String())
+	case HookType:
+		sb.WriteString(e.HookEvent.String())
+	case DriftType:
+		sb.WriteString(e.DriftEvent.String())
+	case RetryType:
+		sb.WriteString(e.RetryEvent.String())
 	}
 	sb.WriteString(" }")
 	return sb.String()
@@ -117,6 +146,9 @@ const (
 	DeleteAction                          // Delete
 	WaitAction                            // Wait
 	InventoryAction                       // Inventory
+	RollbackAction                        // Rollback
+	HookAction                            // Hook
+	DriftAction                           // Drift
 )
 
 type ActionGroupList []ActionGroup
@@ -173,12 +205,15 @@ type WaitEvent struct {
 	GroupName  string
 	Identifier object.ObjMetadata
 	Operation  WaitEventOperation
+	// Reason explains why the object is still pending or timed out, like
+	// the finalizers that block the deletion of a terminating object.
+	Reason string
 }
 
 // String returns a string suitable for logging
 func (we WaitEvent) String() string {
-	return fmt.Sprintf("WaitEvent{ GroupName: %q, Operation: %q, Identifier: %q }",
-		we.GroupName, we.Operation, we.Identifier)
+	return fmt.Sprintf("WaitEvent{ GroupName: %q, Operation: %q, Identifier: %q, Reason: %q }",
+		we.GroupName, we.Operation, we.Identifier, we.Reason)
 }
 
 //go:generate stringer -type=ActionGroupEventType
@@ -210,6 +245,9 @@ const (
 	Created
 	Unchanged
 	Configured
+	Replaced
+	ApplySkipped
+	Migrated
 )
 
 type ApplyEvent struct {
@@ -217,13 +255,27 @@ type ApplyEvent struct {
 	Identifier object.ObjMetadata
 	Operation  ApplyEventOperation
 	Resource   *unstructured.Unstructured
-	Error      error
+	// If apply is skipped, this reason string explains why
+	Reason string
+	Error  error
+	// If server-side apply failed because fields are owned by other
+	// field managers, Conflicts lists these fields and their managers.
+	Conflicts []ApplyConflict
+}
+
+// ApplyConflict is a field that could not be applied server-side,
+// because it is owned by another field manager.
+type ApplyConflict struct {
+	// Field is the path of the field, like ".spec.replicas".
+	Field string
+	// Manager is the field manager that owns the field.
+	Manager string
 }
 
 // String returns a string suitable for logging
 func (ae ApplyEvent) String() string {
-	return fmt.Sprintf("ApplyEvent{ GroupName: %q, Operation: %q, Identifier: %q, Error: %q }",
-		ae.GroupName, ae.Operation, ae.Identifier, ae.Error)
+	return fmt.Sprintf("ApplyEvent{ GroupName: %q, Operation: %q, Identifier: %q, Reason: %q, Error: %q }",
+		ae.GroupName, ae.Operation, ae.Identifier, ae.Reason, ae.Error)
 }
 
 type StatusEvent struct {
@@ -307,3 +359,100 @@ func (ve ValidationEvent) String() string {
 	return fmt.Sprintf("ValidationEvent{ Identifiers: %+v, Error: %q }",
 		ve.Identifiers, ve.Error)
 }
+
+//go:generate stringer -type=RollbackEventOperation -linecomment
+type RollbackEventOperation int
+
+const (
+	RollbackUnspecified RollbackEventOperation = iota // Unspecified
+	RollbackRestored                                  // Restored
+	RollbackRecreated                                 // Recreated
+	RollbackRemoved                                   // Removed
+)
+
+type RollbackEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	Operation  RollbackEventOperation
+	Error      error
+}
+
+// String returns a string suitable for logging
+func (re RollbackEvent) String() string {
+	return fmt.Sprintf("RollbackEvent{ GroupName: %q, Operation: %q, Identifier: %q, Error: %q }",
+		re.GroupName, re.Operation, re.Identifier, re.Error)
+}
+
+//go:generate stringer -type=HookEventOperation -linecomment
+type HookEventOperation int
+
+const (
+	HookUnspecified HookEventOperation = iota // Unspecified
+	HookStarted                               // Started
+	HookSucceeded                             // Succeeded
+	HookFailed                                // Failed
+	HookDeleted                               // Deleted
+)
+
+type HookEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	Operation  HookEventOperation
+	Error      error
+}
+
+// String returns a string suitable for logging
+func (he HookEvent) String() string {
+	return fmt.Sprintf("HookEvent{ GroupName: %q, Operation: %q, Identifier: %q, Error: %q }",
+		he.GroupName, he.Operation, he.Identifier, he.Error)
+}
+
+//go:generate stringer -type=DriftEventOperation -linecomment
+type DriftEventOperation int
+
+const (
+	DriftUnspecified DriftEventOperation = iota // Unspecified
+	DriftInSync                                 // InSync
+	DriftDetected                               // Drifted
+	DriftMissing                                // Missing
+	DriftUnknown                                // Unknown
+)
+
+type DriftEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	Operation  DriftEventOperation
+	// Source is where the last applied state was read from.
+	Source string
+	// Fields are the paths of the fields that changed since the object
+	// was applied.
+	Fields []string
+	Error  error
+}
+
+// String returns a string suitable for logging
+func (de DriftEvent) String() string {
+	return fmt.Sprintf("DriftEvent{ GroupName: %q, Operation: %q, Identifier: %q, Source: %q, Fields: %q, Error: %q }",
+		de.GroupName, de.Operation, de.Identifier, de.Source, de.Fields, de.Error)
+}
+
+type RetryEvent struct {
+	GroupName  string
+	Identifier object.ObjMetadata
+	// Action is the action of the operation that failed.
+	Action ResourceAction
+	// Attempt is the number of the attempt that failed, starting at one.
+	Attempt int
+	// MaxAttempts is the maximum number of attempts.
+	MaxAttempts int
+	// Delay is how long to wait before the next attempt.
+	Delay time.Duration
+	// Error is the error of the attempt that failed.
+	Error error
+}
+
+// String returns a string suitable for logging
+func (re RetryEvent) String() string {
+	return fmt.Sprintf("RetryEvent{ GroupName: %q, Action: %q, Identifier: %q, Attempt: %d, MaxAttempts: %d, Delay: %q, Error: %q }",
+		re.GroupName, re.Action, re.Identifier, re.Attempt, re.MaxAttempts, re.Delay, re.Error)
+}
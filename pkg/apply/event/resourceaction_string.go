@@ -13,11 +13,14 @@ func _() {
 	_ = x[DeleteAction-2]
 	_ = x[WaitAction-3]
 	_ = x[InventoryAction-4]
+	_ = x[RollbackAction-5]
+	_ = x[HookAction-6]
+	_ = x[DriftAction-7]
 }
 
-const _ResourceAction_name = "ApplyPruneDeleteWaitInventory"
+const _ResourceAction_name = "ApplyPruneDeleteWaitInventoryRollbackHookDrift"
 
-var _ResourceAction_index = [...]uint8{0, 5, 10, 16, 20, 29}
+var _ResourceAction_index = [...]uint8{0, 5, 10, 16, 20, 29, 37, 41, 46}
 
 func (i ResourceAction) String() string {
 	if i < 0 || i >= ResourceAction(len(_ResourceAction_index)-1) {
@@ -18,7 +18,9 @@ import (
 
 // InventoryPolicyApplyFilter implements ValidationFilter interface to determine
 // if an object should be applied based on the cluster object's inventory id,
-// the id for the inventory object, and the inventory policy.
+// the id for the inventory object, and the inventory policy. The inventory
+// policy of the run is overridden by the inventory.PolicyKey annotation of
+// the applied object.
 type InventoryPolicyApplyFilter struct {
 	Client    dynamic.Interface
 	Mapper    meta.RESTMapper
@@ -38,7 +40,11 @@ func (ipaf InventoryPolicyApplyFilter) Filter(obj *unstructured.Unstructured) (b
 	if obj == nil {
 		return true, "missing object", nil
 	}
-	if ipaf.InvPolicy == inventory.PolicyAdoptAll {
+	policy, source, err := inventory.ObjectPolicy(obj, ipaf.InvPolicy)
+	if err != nil {
+		return true, "invalid inventory policy annotation", err
+	}
+	if policy == inventory.PolicyAdoptAll {
 		return false, "", nil
 	}
 	// Object must be retrieved from the cluster to get the inventory id.
@@ -52,16 +58,29 @@ func (ipaf InventoryPolicyApplyFilter) Filter(obj *unstructured.Unstructured) (b
 	}
 	// Check the inventory id "match" and the adopt policy to determine
 	// if an object should be applied.
-	canApply, err := inventory.CanApply(ipaf.Inv, clusterObj, ipaf.InvPolicy)
+	canApply, err := inventory.CanApply(ipaf.Inv, clusterObj, policy)
 	if !canApply {
 		invMatch := inventory.IDMatch(ipaf.Inv, clusterObj)
-		reason := fmt.Sprintf("inventory policy prevented apply (inventoryIDMatchStatus: %q, inventoryPolicy: %q)",
-			invMatch, ipaf.InvPolicy)
-		return true, reason, err
+		reason := fmt.Sprintf("inventory policy prevented apply (inventoryIDMatchStatus: %q, inventoryPolicy: %q, inventoryPolicySource: %q)",
+			invMatch, policy, source)
+		return true, reason, explainPolicyError(err, reason)
 	}
 	return false, "", nil
 }
 
+// explainPolicyError adds the reason to the passed error returned by
+// inventory.CanApply, so that the policy which prevented the apply is
+// reported. The type of the error is kept.
+func explainPolicyError(err error, reason string) error {
+	switch err.(type) {
+	case *inventory.NeedAdoptionError:
+		return inventory.NewNeedAdoptionError(fmt.Errorf("%v: %s", err, reason))
+	case *inventory.InventoryOverlapError:
+		return inventory.NewInventoryOverlapError(fmt.Errorf("%v: %s", err, reason))
+	}
+	return err
+}
+
 // getObject retrieves the passed object from the cluster, or an error if one occurred.
 func (ipaf InventoryPolicyApplyFilter) getObject(id object.ObjMetadata) (*unstructured.Unstructured, error) {
 	mapping, err := ipaf.Mapper.RESTMapping(id.GroupKind)
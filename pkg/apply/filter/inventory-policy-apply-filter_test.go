@@ -4,6 +4,8 @@
 package filter
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
@@ -30,8 +32,10 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 		inventoryID    string
 		objInventoryID string
 		policy         inventory.Policy
+		objPolicy      string
 		filtered       bool
 		isError        bool
+		source         inventory.PolicySource
 	}{
 		"inventory and object ids match, not filtered": {
 			inventoryID:    "foo",
@@ -82,15 +86,60 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 			filtered:       true,
 			isError:        true,
 		},
+		"object policy adopt all overrides must match, not filtered": {
+			inventoryID:    "foo",
+			objInventoryID: "bar",
+			policy:         inventory.PolicyMustMatch,
+			objPolicy:      "adopt-all",
+			filtered:       false,
+			isError:        false,
+		},
+		"object id empty and object policy adopt if no inventory overrides must match, not filtered": {
+			inventoryID:    "foo",
+			objInventoryID: "",
+			policy:         inventory.PolicyMustMatch,
+			objPolicy:      "adopt-if-no-inventory",
+			filtered:       false,
+			isError:        false,
+		},
+		"object policy must match overrides adopt all, filtered and error": {
+			inventoryID:    "foo",
+			objInventoryID: "bar",
+			policy:         inventory.PolicyAdoptAll,
+			objPolicy:      "must-match",
+			filtered:       true,
+			isError:        true,
+			source:         inventory.PolicySourceAnnotation,
+		},
+		"inventory and object ids do no match without object policy, filtered and error": {
+			inventoryID:    "foo",
+			objInventoryID: "bar",
+			policy:         inventory.PolicyAdoptIfNoInventory,
+			filtered:       true,
+			isError:        true,
+			source:         inventory.PolicySourceRun,
+		},
+		"invalid object policy, filtered and error": {
+			inventoryID:    "foo",
+			objInventoryID: "foo",
+			policy:         inventory.PolicyMustMatch,
+			objPolicy:      "adopt",
+			filtered:       true,
+			isError:        true,
+		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
 			obj := defaultObj.DeepCopy()
-			objIDAnnotation := map[string]string{
-				"config.k8s.io/owning-inventory": tc.objInventoryID,
+			objAnnotations := map[string]string{}
+			if tc.objInventoryID != "" {
+				objAnnotations["config.k8s.io/owning-inventory"] = tc.objInventoryID
 			}
-			obj.SetAnnotations(objIDAnnotation)
+			if tc.objPolicy != "" {
+				objAnnotations["config.k8s.io/inventory-policy"] = tc.objPolicy
+			}
+			obj.SetAnnotations(objAnnotations)
 			invIDLabel := map[string]string{
 				common.InventoryLabel: tc.inventoryID,
 			}
@@ -113,6 +162,12 @@ func TestInventoryPolicyApplyFilter(t *testing.T) {
 			if tc.filtered && len(reason) == 0 {
 				t.Errorf("InventoryPolicyFilter filtered; expected but missing Reason")
 			}
+			if tc.source != "" {
+				source := fmt.Sprintf("inventoryPolicySource: %q", tc.source)
+				if !strings.Contains(reason, source) || !strings.Contains(err.Error(), source) {
+					t.Errorf("InventoryPolicyFilter expected policy source (%s), got reason (%s) and error (%v)", source, reason, err)
+				}
+			}
 			if !tc.filtered && len(reason) > 0 {
 				t.Errorf("InventoryPolicyFilter not filtered; received unexpected Reason: %s", reason)
 			}
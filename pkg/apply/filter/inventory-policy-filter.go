@@ -13,6 +13,9 @@ import (
 // InventoryPolicyFilter implements ValidationFilter interface to determine
 // if an object should be pruned (deleted) because of the InventoryPolicy
 // and if the objects owning inventory identifier matchs the inventory id.
+// The inventory policy of the run is overridden by the inventory.PolicyKey
+// annotation of the cluster object, unless the object belongs to another
+// inventory, which set the annotation for its own runs.
 type InventoryPolicyFilter struct {
 	Inv       inventory.Info
 	InvPolicy inventory.Policy
@@ -24,15 +27,26 @@ func (ipf InventoryPolicyFilter) Name() string {
 }
 
 // Filter returns true if the passed object should NOT be pruned (deleted)
-// because the "prevent remove" annotation is present; otherwise returns
-// false. Never returns an error.
+// because of the inventory policy; otherwise returns false. Returns an
+// error if the inventory policy annotation of the object is invalid.
 func (ipf InventoryPolicyFilter) Filter(obj *unstructured.Unstructured) (bool, string, error) {
+	if obj == nil {
+		return true, "missing object", nil
+	}
+	invMatch := inventory.IDMatch(ipf.Inv, obj)
+	policy, source := ipf.InvPolicy, inventory.PolicySourceRun
+	if invMatch != inventory.NoMatch {
+		var err error
+		policy, source, err = inventory.ObjectPolicy(obj, ipf.InvPolicy)
+		if err != nil {
+			return true, "invalid inventory policy annotation", err
+		}
+	}
 	// Check the inventory id "match" and the adopt policy to determine
 	// if an object should be pruned (deleted).
-	if !inventory.CanPrune(ipf.Inv, obj, ipf.InvPolicy) {
-		invMatch := inventory.IDMatch(ipf.Inv, obj)
-		reason := fmt.Sprintf("inventory policy prevented deletion (inventoryIDMatchStatus: %q, inventoryPolicy: %q)",
-			invMatch, ipf.InvPolicy)
+	if !inventory.CanPrune(ipf.Inv, obj, policy) {
+		reason := fmt.Sprintf("inventory policy prevented deletion (inventoryIDMatchStatus: %q, inventoryPolicy: %q, inventoryPolicySource: %q)",
+			invMatch, policy, source)
 		return true, reason, nil
 	}
 	return false, "", nil
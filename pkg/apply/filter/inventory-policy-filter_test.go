@@ -4,6 +4,8 @@
 package filter
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -27,7 +29,10 @@ func TestInventoryPolicyFilter(t *testing.T) {
 		inventoryID    string
 		objInventoryID string
 		policy         inventory.Policy
+		objPolicy      string
 		filtered       bool
+		isError        bool
+		source         inventory.PolicySource
 	}{
 		"inventory and object ids match, not filtered": {
 			inventoryID:    "foo",
@@ -71,6 +76,37 @@ func TestInventoryPolicyFilter(t *testing.T) {
 			policy:         inventory.PolicyMustMatch,
 			filtered:       true,
 		},
+		"object id empty and object policy adopt if no inventory overrides must match, not filtered": {
+			inventoryID:    "foo",
+			objInventoryID: "",
+			policy:         inventory.PolicyMustMatch,
+			objPolicy:      "adopt-if-no-inventory",
+			filtered:       false,
+		},
+		"object id empty and object policy must match overrides adopt all, filtered": {
+			inventoryID:    "foo",
+			objInventoryID: "",
+			policy:         inventory.PolicyAdoptAll,
+			objPolicy:      "must-match",
+			filtered:       true,
+			source:         inventory.PolicySourceAnnotation,
+		},
+		"inventory and object ids do no match and object policy of the other inventory ignored, filtered": {
+			inventoryID:    "foo",
+			objInventoryID: "bar",
+			policy:         inventory.PolicyMustMatch,
+			objPolicy:      "adopt-all",
+			filtered:       true,
+			source:         inventory.PolicySourceRun,
+		},
+		"invalid object policy, filtered and error": {
+			inventoryID:    "foo",
+			objInventoryID: "",
+			policy:         inventory.PolicyAdoptAll,
+			objPolicy:      "adopt",
+			filtered:       true,
+			isError:        true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -84,14 +120,18 @@ func TestInventoryPolicyFilter(t *testing.T) {
 				Inv:       inventory.WrapInventoryInfoObj(invObj),
 				InvPolicy: tc.policy,
 			}
-			objIDAnnotation := map[string]string{
-				"config.k8s.io/owning-inventory": tc.objInventoryID,
+			objAnnotations := map[string]string{}
+			if tc.objInventoryID != "" {
+				objAnnotations["config.k8s.io/owning-inventory"] = tc.objInventoryID
+			}
+			if tc.objPolicy != "" {
+				objAnnotations["config.k8s.io/inventory-policy"] = tc.objPolicy
 			}
 			obj := defaultObj.DeepCopy()
-			obj.SetAnnotations(objIDAnnotation)
+			obj.SetAnnotations(objAnnotations)
 			actual, reason, err := filter.Filter(obj)
-			if err != nil {
-				t.Fatalf("InventoryPolicyFilter unexpected error (%s)", err)
+			if tc.isError != (err != nil) {
+				t.Fatalf("Expected InventoryPolicyFilter error (%v), got (%v)", tc.isError, err)
 			}
 			if tc.filtered != actual {
 				t.Errorf("InventoryPolicyFilter expected filter (%t), got (%t)", tc.filtered, actual)
@@ -102,6 +142,9 @@ func TestInventoryPolicyFilter(t *testing.T) {
 			if !tc.filtered && len(reason) > 0 {
 				t.Errorf("InventoryPolicyFilter not filtered; received unexpected Reason: %s", reason)
 			}
+			if tc.source != "" && !strings.Contains(reason, fmt.Sprintf("inventoryPolicySource: %q", tc.source)) {
+				t.Errorf("InventoryPolicyFilter expected policy source (%s), got Reason: %s", tc.source, reason)
+			}
 		})
 	}
 }
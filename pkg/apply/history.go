@@ -0,0 +1,32 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// recordRevision records the applied objects, and the passed manifests if
+// any, as the newest revision of the inventory, keeping at most limit
+// revisions.
+func recordRevision(historyClient inventory.HistoryClient, invInfo inventory.Info, appliedObjs object.ObjMetadataSet,
+	manifests object.UnstructuredSet, limit int, dryRun common.DryRunStrategy) error {
+	rev, err := historyClient.AddRevision(invInfo, inventory.Revision{
+		Objects:   appliedObjs,
+		Hash:      appliedObjs.Hash(),
+		Timestamp: time.Now(),
+		Manifests: manifests,
+	}, limit, dryRun)
+	if err != nil {
+		return fmt.Errorf("failed to record inventory revision: %w", err)
+	}
+	klog.V(4).Infof("recorded inventory revision %d (%d objects)", rev.Number, len(appliedObjs))
+	return nil
+}
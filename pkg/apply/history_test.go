@@ -0,0 +1,71 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestRecordRevision(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	manifests := object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}
+
+	invClient := inventory.NewFakeClient(object.ObjMetadataSet{})
+	for i := 0; i < 3; i++ {
+		err := recordRevision(invClient, nil, object.ObjMetadataSet{depID}, manifests, 2, common.DryRunNone)
+		require.NoError(t, err)
+	}
+	err := recordRevision(invClient, nil, object.ObjMetadataSet{depID}, manifests, 2, common.DryRunClient)
+	require.NoError(t, err)
+	err = recordRevision(invClient, nil, object.ObjMetadataSet{secretID, depID}, nil, 2, common.DryRunNone)
+	require.NoError(t, err)
+
+	// The oldest revisions are dropped beyond the limit.
+	require.Len(t, invClient.Revisions, 2)
+	rev := invClient.Revisions[0]
+	assert.Equal(t, 3, rev.Number)
+	assert.Equal(t, object.ObjMetadataSet{depID}, rev.Objects)
+	assert.Equal(t, object.ObjMetadataSet{depID}.Hash(), rev.Hash)
+	assert.Equal(t, manifests, rev.Manifests)
+	assert.False(t, rev.Timestamp.IsZero())
+	rev = invClient.Revisions[1]
+	assert.Equal(t, 4, rev.Number)
+	assert.Equal(t, object.ObjMetadataSet{secretID, depID}.Hash(), rev.Hash)
+	assert.Empty(t, rev.Manifests)
+}
+
+func TestApplierHistoryManifestsSecret(t *testing.T) {
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "default",
+		id:        "test",
+	}
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	secret := testutil.Unstructured(t, resources["secret"])
+	applier := newTestApplier(t, invInfo, object.UnstructuredSet{deployment, secret},
+		object.UnstructuredSet{}, newFakePoller([]pollevent.Event{}))
+
+	// The run fails before applying any object, because the manifests of
+	// the Secret can not be recorded.
+	var events []event.Event
+	for e := range applier.Run(context.Background(), invInfo.toWrapped(), object.UnstructuredSet{deployment, secret},
+		ApplierOptions{HistoryLimit: 2, HistoryManifests: true, InventoryPolicy: inventory.PolicyAdoptAll}) {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	require.Equal(t, event.ErrorType, events[0].Type)
+	assert.EqualError(t, events[0].ErrorEvent.Err,
+		"revision manifests can not include Secrets: "+object.UnstructuredToObjMetadata(secret).String())
+}
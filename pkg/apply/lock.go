@@ -0,0 +1,82 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// lockInventory takes the lock of the inventory for the length of a run.
+// If the options have no holder, the run is identified by the hostname
+// and a unique id. If the namespace of the inventory does not exist, and
+// it is one of the passed objects, it is applied first, so that the lock
+// can be created in it. Otherwise the run cannot be locked, and an error
+// is returned.
+func lockInventory(ctx context.Context, invClient inventory.Client, invInfo inventory.Info,
+	objs object.UnstructuredSet, options inventory.LockOptions) (*inventory.Lock, error) {
+	lockClient, ok := invClient.(inventory.LockClient)
+	if !ok {
+		return nil, fmt.Errorf("inventory client cannot lock the inventory")
+	}
+	if options.Holder == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = "unknown"
+		}
+		options.Holder = fmt.Sprintf("%s_%s", hostname, uuid.NewUUID())
+	}
+	lock, err := lockClient.Lock(ctx, invInfo, options)
+	var nsErr *inventory.LockNamespaceNotFoundError
+	if !errors.As(err, &nsErr) {
+		return lock, err
+	}
+	invNamespace := inventoryNamespace(invInfo, objs)
+	if invNamespace == nil {
+		return nil, err
+	}
+	klog.V(4).Infof("applying inventory namespace %s before locking", invNamespace.GetName())
+	if err := invClient.ApplyInventoryNamespace(invNamespace, common.DryRunNone); err != nil &&
+		!apierrors.IsAlreadyExists(err) {
+		return nil, fmt.Errorf("failed to apply inventory namespace: %w", err)
+	}
+	return lockClient.Lock(ctx, invInfo, options)
+}
+
+// inventoryNamespace returns a copy of the namespace of the passed
+// inventory from the passed objects, owned by the inventory, or nil if
+// it is not one of the objects.
+func inventoryNamespace(invInfo inventory.Info, objs object.UnstructuredSet) *unstructured.Unstructured {
+	for _, obj := range objs {
+		if object.IsKindNamespace(obj) && obj.GetName() == invInfo.Namespace() {
+			invNamespace := obj.DeepCopy()
+			inventory.AddInventoryIDAnnotation(invNamespace, invInfo)
+			return invNamespace
+		}
+	}
+	return nil
+}
+
+// releaseInventoryLock releases the lock of the inventory at the end of a
+// run. Reports an error if the lock was lost during the run, because
+// another run may have changed the inventory at the same time.
+func releaseInventoryLock(eventChannel chan event.Event, lock *inventory.Lock) {
+	if lock.Lost() {
+		handleError(eventChannel, fmt.Errorf("inventory lock was lost during the run"))
+	}
+	if err := lock.Release(); err != nil {
+		handleError(eventChannel, err)
+	}
+}
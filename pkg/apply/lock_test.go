@@ -0,0 +1,91 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestLockInventoryNamespace(t *testing.T) {
+	namespace := testutil.Unstructured(t, `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test-namespace
+`)
+	testCases := map[string]struct {
+		objs        object.UnstructuredSet
+		expectedErr bool
+	}{
+		"namespace is applied before locking": {
+			objs: object.UnstructuredSet{namespace},
+		},
+		"namespace not in the objects": {
+			objs:        object.UnstructuredSet{},
+			expectedErr: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("test-namespace")
+			defer tf.Cleanup()
+
+			// The Lease cannot be created until the namespace exists.
+			namespaceCreated := false
+			tf.FakeDynamicClient.PrependReactor("create", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+				namespaceCreated = true
+				return false, nil, nil
+			})
+			tf.FakeDynamicClient.PrependReactor("create", "leases", func(clienttesting.Action) (bool, runtime.Object, error) {
+				if !namespaceCreated {
+					return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "test-namespace")
+				}
+				return false, nil, nil
+			})
+
+			invClient, err := inventory.NewClient(tf, inventory.WrapInventoryObj, inventory.InvInfoToConfigMap)
+			require.NoError(t, err)
+			invInfo := inventoryInfo{
+				name:      "abc-123",
+				namespace: "test-namespace",
+				id:        "test",
+			}
+
+			lock, err := lockInventory(context.Background(), invClient, invInfo.toWrapped(), tc.objs,
+				inventory.LockOptions{Holder: "test"})
+			if tc.expectedErr {
+				var nsErr *inventory.LockNamespaceNotFoundError
+				assert.True(t, errors.As(err, &nsErr), "unexpected error: %v", err)
+				assert.False(t, namespaceCreated)
+				return
+			}
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, lock.Release())
+			}()
+
+			// The namespace is owned by the inventory, so that it can be
+			// applied with the other objects.
+			obj, err := tf.FakeDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).
+				Get(context.Background(), "test-namespace", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, "test", obj.GetAnnotations()[inventory.OwningInventoryKey])
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// dryRunVerifier verifies that the resources of a GroupVersionKind support
+// server-side dry-run.
+type dryRunVerifier interface {
+	HasSupport(gvk schema.GroupVersionKind) error
+}
+
+// dryRunVerifierFactoryFunc is a factory function for creating a new
+// dryRunVerifier implementation. Used to allow unit testing.
+var dryRunVerifierFactoryFunc = func(client dynamic.Interface,
+	openAPIGetter discovery.OpenAPISchemaInterface) dryRunVerifier {
+	return resource.NewDryRunVerifier(client, openAPIGetter)
+}
+
+// verifyServerDryRun returns a DryRunUnsupportedError if the resources of
+// any of the objects do not support server-side dry-run. The API server
+// would persist the changes to these resources instead, so this must be
+// checked before anything is sent to the cluster. Objects of types that
+// are not registered in the cluster are skipped, since they can't be
+// changed.
+func verifyServerDryRun(verifier dryRunVerifier, mapper meta.RESTMapper, objSets ...object.UnstructuredSet) error {
+	checked := make(map[schema.GroupVersionKind]bool)
+	var unsupported []schema.GroupVersionResource
+	for _, objs := range objSets {
+		for _, obj := range objs {
+			gvk := obj.GroupVersionKind()
+			if checked[gvk] || gvk.Kind == "" {
+				continue
+			}
+			checked[gvk] = true
+			mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+			if err != nil {
+				if meta.IsNoMatchError(err) {
+					klog.V(4).Infof("skipping dry-run check for unregistered type %q", gvk)
+					continue
+				}
+				return fmt.Errorf("failed to check dry-run support: %w", err)
+			}
+			if err := verifier.HasSupport(gvk); err != nil {
+				klog.V(4).Infof("dry-run not supported by %q: %v", mapping.Resource, err)
+				unsupported = append(unsupported, mapping.Resource)
+			}
+		}
+	}
+	if len(unsupported) > 0 {
+		return applyerror.NewDryRunUnsupportedError(unsupported)
+	}
+	return nil
+}
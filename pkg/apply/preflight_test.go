@@ -0,0 +1,163 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	applyerror "sigs.k8s.io/cli-utils/pkg/apply/error"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+type fakeDryRunVerifier struct {
+	unsupported map[schema.GroupKind]bool
+}
+
+func (f *fakeDryRunVerifier) HasSupport(gvk schema.GroupVersionKind) error {
+	if f.unsupported[gvk.GroupKind()] {
+		return fmt.Errorf("%v doesn't support dry-run", gvk)
+	}
+	return nil
+}
+
+func TestVerifyServerDryRun(t *testing.T) {
+	deploymentGK := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	mapper := testutil.NewFakeRESTMapper(
+		schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"},
+	)
+	unregistered := testutil.Unstructured(t, `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+  namespace: default
+`)
+
+	testCases := map[string]struct {
+		objSets     []object.UnstructuredSet
+		unsupported map[schema.GroupKind]bool
+		expectedErr error
+	}{
+		"no objects": {},
+		"all supported": {
+			objSets: []object.UnstructuredSet{
+				{testutil.Unstructured(t, resources["deployment"])},
+				{testutil.Unstructured(t, resources["secret"])},
+			},
+		},
+		"unsupported resource": {
+			objSets: []object.UnstructuredSet{
+				{testutil.Unstructured(t, resources["secret"])},
+				{
+					testutil.Unstructured(t, resources["deployment"]),
+					testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+				},
+			},
+			unsupported: map[schema.GroupKind]bool{deploymentGK: true},
+			expectedErr: applyerror.NewDryRunUnsupportedError([]schema.GroupVersionResource{
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+			}),
+		},
+		"unregistered type skipped": {
+			objSets: []object.UnstructuredSet{
+				{unregistered},
+			},
+			unsupported: map[schema.GroupKind]bool{
+				{Group: "example.com", Kind: "Widget"}: true,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			verifier := &fakeDryRunVerifier{unsupported: tc.unsupported}
+			err := verifyServerDryRun(verifier, mapper, tc.objSets...)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+}
+
+func TestServerDryRunUnsupported(t *testing.T) {
+	oldVerifier := dryRunVerifierFactoryFunc
+	dryRunVerifierFactoryFunc = func(dynamic.Interface, discovery.OpenAPISchemaInterface) dryRunVerifier {
+		return &fakeDryRunVerifier{
+			unsupported: map[schema.GroupKind]bool{
+				{Group: "apps", Kind: "Deployment"}: true,
+			},
+		}
+	}
+	defer func() { dryRunVerifierFactoryFunc = oldVerifier }()
+
+	invInfo := inventoryInfo{
+		name:      "abc-123",
+		namespace: "test",
+		id:        "test",
+		set: object.ObjMetadataSet{
+			testutil.ToIdentifier(t, resources["deployment"]),
+		},
+	}
+	clusterObjs := object.UnstructuredSet{
+		testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test")),
+	}
+
+	testCases := map[string]struct {
+		run func(ctx context.Context) <-chan event.Event
+	}{
+		"applier": {
+			run: func(ctx context.Context) <-chan event.Event {
+				objs := object.UnstructuredSet{
+					testutil.Unstructured(t, resources["deployment"]),
+				}
+				applier := newTestApplier(t, invInfo, objs, clusterObjs, newFakePoller(nil))
+				return applier.Run(ctx, invInfo.toWrapped(), objs, ApplierOptions{
+					DryRunStrategy: common.DryRunServer,
+				})
+			},
+		},
+		"destroyer": {
+			run: func(ctx context.Context) <-chan event.Event {
+				destroyer := newTestDestroyer(t, invInfo, clusterObjs, newFakePoller(nil))
+				return destroyer.Run(ctx, invInfo.toWrapped(), DestroyerOptions{
+					DryRunStrategy: common.DryRunServer,
+				})
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			var events []event.Event
+			for e := range tc.run(ctx) {
+				events = append(events, e)
+			}
+			require.NoError(t, ctx.Err())
+
+			// No tasks run, so only the error event is sent.
+			require.Len(t, events, 1)
+			assert.Equal(t, event.ErrorType, events[0].Type)
+			assert.Equal(t, applyerror.NewDryRunUnsupportedError([]schema.GroupVersionResource{
+				{Group: "apps", Version: "v1", Resource: "deployments"},
+			}), events[0].ErrorEvent.Err)
+		})
+	}
+}
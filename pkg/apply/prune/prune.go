@@ -13,6 +13,7 @@ package prune
 
 import (
 	"context"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -22,6 +23,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -67,6 +69,10 @@ type Options struct {
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+
+	// Retry defines how deletes that fail with transient errors are
+	// retried. The zero value does not retry.
+	Retry retry.Policy
 }
 
 // Prune deletes the set of passed objects. A prune skip/failure is
@@ -90,12 +96,32 @@ func (p *Pruner) Prune(
 	taskContext *taskrunner.TaskContext,
 	taskName string,
 	opts Options,
+) error {
+	return p.PruneWithContext(context.Background(), objs, pruneFilters, taskContext, taskName, opts)
+}
+
+// PruneWithContext deletes the set of passed objects, like Prune. If the
+// context is cancelled, the objects that have not been deleted yet are
+// skipped.
+func (p *Pruner) PruneWithContext(
+	ctx context.Context,
+	objs object.UnstructuredSet,
+	pruneFilters []filter.ValidationFilter,
+	taskContext *taskrunner.TaskContext,
+	taskName string,
+	opts Options,
 ) error {
 	eventFactory := CreateEventFactory(opts.Destroy, taskName)
 	// Iterate through objects to prune (delete). If an object is not pruned
 	// and we need to keep it in the inventory, we must capture the prune failure.
 	for _, obj := range objs {
 		id := object.UnstructuredToObjMetadata(obj)
+		if ctx.Err() != nil {
+			klog.V(4).Infof("prune skipped (object: %q): %s", id, taskrunner.CancelledReason)
+			taskContext.SendEvent(eventFactory.CreateSkippedEvent(obj, taskrunner.CancelledReason))
+			taskContext.InventoryManager().AddSkippedDelete(id)
+			continue
+		}
 		klog.V(5).Infof("evaluating prune filters (object: %q)", id)
 
 		// UID will change if the object is deleted and re-created.
@@ -153,13 +179,19 @@ func (p *Pruner) Prune(
 		// Filters passed--actually delete object if not dry run.
 		if !opts.DryRunStrategy.ClientOrServerDryRun() {
 			klog.V(4).Infof("deleting object (object: %q)", id)
-			err := p.deleteObject(id, metav1.DeleteOptions{
-				// Only delete the resource if it hasn't already been deleted
-				// and recreated since the last GET. Otherwise error.
-				Preconditions: &metav1.Preconditions{
-					UID: &uid,
-				},
-				PropagationPolicy: &opts.PropagationPolicy,
+			err := opts.Retry.Do(ctx, func() error {
+				return p.deleteObject(id, metav1.DeleteOptions{
+					// Only delete the resource if it hasn't already been deleted
+					// and recreated since the last GET. Otherwise error.
+					Preconditions: &metav1.Preconditions{
+						UID: &uid,
+					},
+					PropagationPolicy: &opts.PropagationPolicy,
+				})
+			}, func(attempt int, err error, delay time.Duration) {
+				klog.V(4).Infof("delete failed, retrying in %s (object: %q, attempt: %d/%d): %v",
+					delay, id, attempt, opts.Retry.Attempts, err)
+				taskContext.SendEvent(eventFactory.CreateRetryEvent(id, attempt, opts.Retry.Attempts, delay, err))
 			})
 			if err != nil {
 				if klog.V(4).Enabled() {
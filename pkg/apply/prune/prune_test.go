@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,6 +26,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -855,6 +857,159 @@ func TestPrune_PropagationPolicy(t *testing.T) {
 	}
 }
 
+// throttledNamespaceClient fails the first deletes with a throttling error.
+type throttledNamespaceClient struct {
+	dynamic.ResourceInterface
+	failures int
+	calls    int
+}
+
+var _ dynamic.ResourceInterface = &throttledNamespaceClient{}
+
+func (c *throttledNamespaceClient) Delete(_ context.Context, _ string, _ metav1.DeleteOptions, _ ...string) error {
+	c.calls++
+	if c.calls <= c.failures {
+		return apierrors.NewTooManyRequests("slow down", 1)
+	}
+	return nil
+}
+
+func TestPrune_Retry(t *testing.T) {
+	pdbID := object.UnstructuredToObjMetadata(pdb)
+	testCases := map[string]struct {
+		failures       int
+		expectedCalls  int
+		expectedEvents []testutil.ExpEvent
+	}{
+		"retried; delete succeeds": {
+			failures:      2,
+			expectedCalls: 3,
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						Action:     event.PruneAction,
+						Identifier: pdbID,
+						Attempt:    1,
+						Error:      apierrors.NewTooManyRequests("slow down", 1),
+					},
+				},
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						Action:     event.PruneAction,
+						Identifier: pdbID,
+						Attempt:    2,
+						Error:      apierrors.NewTooManyRequests("slow down", 1),
+					},
+				},
+				{
+					EventType: event.PruneType,
+					PruneEvent: &testutil.ExpPruneEvent{
+						Identifier: pdbID,
+						Operation:  event.Pruned,
+					},
+				},
+			},
+		},
+		"attempts exhausted; delete fails": {
+			failures:      5,
+			expectedCalls: 3,
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						Action:     event.PruneAction,
+						Identifier: pdbID,
+						Attempt:    1,
+						Error:      apierrors.NewTooManyRequests("slow down", 1),
+					},
+				},
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						Action:     event.PruneAction,
+						Identifier: pdbID,
+						Attempt:    2,
+						Error:      apierrors.NewTooManyRequests("slow down", 1),
+					},
+				},
+				{
+					EventType: event.PruneType,
+					PruneEvent: &testutil.ExpPruneEvent{
+						Identifier: pdbID,
+						Error:      apierrors.NewTooManyRequests("slow down", 1),
+					},
+				},
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &throttledNamespaceClient{failures: tc.failures}
+			po := Pruner{
+				InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+				Client: &fakeDynamicClient{
+					resourceInterface: client,
+				},
+				Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+					scheme.Scheme.PrioritizedVersionsAllGroups()...),
+			}
+
+			eventChannel := make(chan event.Event, 3)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+			err := po.Prune([]*unstructured.Unstructured{pdb}, []filter.ValidationFilter{}, taskContext, "test-0", Options{
+				Retry: retry.Policy{Attempts: 3, Backoff: time.Millisecond},
+			})
+			assert.NoError(t, err)
+			close(eventChannel)
+
+			var actualEvents []event.Event
+			for e := range eventChannel {
+				actualEvents = append(actualEvents, e)
+			}
+			assert.NoError(t, testutil.VerifyEvents(tc.expectedEvents, actualEvents))
+			assert.Equal(t, tc.expectedCalls, client.calls)
+		})
+	}
+}
+
+func TestPrune_Cancelled(t *testing.T) {
+	client := &throttledNamespaceClient{}
+	po := Pruner{
+		InvClient: inventory.NewFakeClient(object.ObjMetadataSet{}),
+		Client: &fakeDynamicClient{
+			resourceInterface: client,
+		},
+		Mapper: testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme,
+			scheme.Scheme.PrioritizedVersionsAllGroups()...),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	eventChannel := make(chan event.Event, 1)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+	err := po.PruneWithContext(ctx, []*unstructured.Unstructured{pdb}, []filter.ValidationFilter{}, taskContext, "test-0", Options{})
+	assert.NoError(t, err)
+	close(eventChannel)
+
+	var actualEvents []event.Event
+	for e := range eventChannel {
+		actualEvents = append(actualEvents, e)
+	}
+	require.Len(t, actualEvents, 1)
+	assert.Equal(t, event.PruneType, actualEvents[0].Type)
+	assert.Equal(t, event.PruneSkipped, actualEvents[0].PruneEvent.Operation)
+	assert.Equal(t, taskrunner.CancelledReason, actualEvents[0].PruneEvent.Reason)
+	assert.Equal(t, 0, client.calls)
+
+	pdbID := object.UnstructuredToObjMetadata(pdb)
+	assert.True(t, taskContext.InventoryManager().IsSkippedDelete(pdbID))
+}
+
 type fakeDynamicClient struct {
 	resourceInterface dynamic.ResourceInterface
 }
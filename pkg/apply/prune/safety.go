@@ -0,0 +1,106 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package prune
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// DefaultProtectedGroupKinds are the kinds of objects that usually hold
+// data or other objects, so that pruning them by mistake loses more than
+// the objects themselves.
+var DefaultProtectedGroupKinds = []schema.GroupKind{
+	{Group: "", Kind: "Namespace"},
+	{Group: "", Kind: "PersistentVolumeClaim"},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+}
+
+// SafetyOptions limits the objects that may be pruned in a single run.
+// The zero value does not limit pruning.
+type SafetyOptions struct {
+	// MaxCount is the maximum number of objects that may be pruned.
+	// Zero means no limit.
+	MaxCount int
+
+	// MaxPercent is the maximum percentage of the objects in the
+	// inventory that may be pruned. Zero means no limit.
+	MaxPercent int
+
+	// ProtectedGroupKinds are the kinds of objects that are only pruned
+	// if AllowProtected is true.
+	ProtectedGroupKinds []schema.GroupKind
+
+	// AllowProtected confirms that objects of the ProtectedGroupKinds may
+	// be pruned.
+	AllowProtected bool
+}
+
+// CheckSafety returns a SafetyError if pruning the objects would exceed
+// the limits of the options. The invCount is the number of objects in the
+// inventory, used to calculate the percentage of objects pruned.
+func CheckSafety(pruneObjs object.UnstructuredSet, invCount int, opts SafetyOptions) error {
+	ids := object.UnstructuredSetToObjMetadataSet(pruneObjs)
+	if !opts.AllowProtected && len(opts.ProtectedGroupKinds) > 0 {
+		protected := make(map[schema.GroupKind]bool, len(opts.ProtectedGroupKinds))
+		for _, gk := range opts.ProtectedGroupKinds {
+			protected[gk] = true
+		}
+		var protectedIDs object.ObjMetadataSet
+		for _, id := range ids {
+			if protected[id.GroupKind] {
+				protectedIDs = append(protectedIDs, id)
+			}
+		}
+		if len(protectedIDs) > 0 {
+			return &SafetyError{
+				Reason:  "pruning protected kinds requires confirmation",
+				Objects: protectedIDs,
+			}
+		}
+	}
+	if opts.MaxCount > 0 && len(ids) > opts.MaxCount {
+		return &SafetyError{
+			Reason:  fmt.Sprintf("%d objects exceed the limit of %d pruned objects", len(ids), opts.MaxCount),
+			Objects: ids,
+		}
+	}
+	if opts.MaxPercent > 0 && invCount > 0 && len(ids)*100 > opts.MaxPercent*invCount {
+		return &SafetyError{
+			Reason: fmt.Sprintf("%d of %d inventory objects exceed the limit of %d%% pruned objects",
+				len(ids), invCount, opts.MaxPercent),
+			Objects: ids,
+		}
+	}
+	return nil
+}
+
+// SafetyError is returned if pruning objects would exceed the limits of
+// the SafetyOptions. Nothing is pruned.
+type SafetyError struct {
+	// Reason explains which limit was exceeded.
+	Reason string
+	// Objects are the objects that exceed the limit.
+	Objects object.ObjMetadataSet
+}
+
+func (e *SafetyError) Error() string {
+	objs := make([]string, len(e.Objects))
+	for i, id := range e.Objects {
+		objs[i] = objectString(id)
+	}
+	return fmt.Sprintf("prune aborted: %s: %s", e.Reason, strings.Join(objs, ", "))
+}
+
+// objectString returns a readable string for the object, like
+// "Deployment.apps default/foo".
+func objectString(id object.ObjMetadata) string {
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s %s", id.GroupKind, id.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", id.GroupKind, id.Namespace, id.Name)
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package prune
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestCheckSafety(t *testing.T) {
+	objs := object.UnstructuredSet{namespace, pod, pdb}
+
+	testCases := map[string]struct {
+		objs        object.UnstructuredSet
+		invCount    int
+		opts        SafetyOptions
+		expectedErr string
+	}{
+		"no limits": {
+			objs:     objs,
+			invCount: 3,
+		},
+		"within limits": {
+			objs:     object.UnstructuredSet{pod, pdb},
+			invCount: 4,
+			opts: SafetyOptions{
+				MaxCount:            2,
+				MaxPercent:          50,
+				ProtectedGroupKinds: DefaultProtectedGroupKinds,
+			},
+		},
+		"count exceeded": {
+			objs:     object.UnstructuredSet{pod, pdb},
+			invCount: 4,
+			opts: SafetyOptions{
+				MaxCount: 1,
+			},
+			expectedErr: "prune aborted: 2 objects exceed the limit of 1 pruned objects: " +
+				"Pod test-inventory-namespace/pod-1, PodDisruptionBudget.policy test-inventory-namespace/pdb",
+		},
+		"percentage exceeded": {
+			objs:     object.UnstructuredSet{pod, pdb},
+			invCount: 3,
+			opts: SafetyOptions{
+				MaxPercent: 50,
+			},
+			expectedErr: "prune aborted: 2 of 3 inventory objects exceed the limit of 50% pruned objects: " +
+				"Pod test-inventory-namespace/pod-1, PodDisruptionBudget.policy test-inventory-namespace/pdb",
+		},
+		"protected kind": {
+			objs:     objs,
+			invCount: 3,
+			opts: SafetyOptions{
+				MaxCount:            1,
+				ProtectedGroupKinds: DefaultProtectedGroupKinds,
+			},
+			expectedErr: "prune aborted: pruning protected kinds requires confirmation: " +
+				"Namespace test-inventory-namespace",
+		},
+		"protected kind confirmed": {
+			objs:     objs,
+			invCount: 3,
+			opts: SafetyOptions{
+				ProtectedGroupKinds: DefaultProtectedGroupKinds,
+				AllowProtected:      true,
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			err := CheckSafety(tc.objs, tc.invCount, tc.opts)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedErr)
+			assert.IsType(t, &SafetyError{}, err)
+		})
+	}
+}
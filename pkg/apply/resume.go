@@ -0,0 +1,99 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/solver"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// resumeTaskQueue removes the tasks that completed in an interrupted run
+// from the task queue, using the object status persisted in the inventory
+// object. The persisted status of the objects that were applied or deleted
+// successfully is added to the inventory manager, so that the inventory
+// keeps the applied objects and forgets the deleted ones, as if the removed
+// tasks had run again.
+func resumeTaskQueue(statusClient inventory.StatusClient, invInfo inventory.Info, taskQueue *solver.TaskQueue,
+	taskContext *taskrunner.TaskContext, ids object.ObjMetadataSet) error {
+	objStatuses, err := statusClient.GetClusterObjStatus(invInfo)
+	if err != nil {
+		return err
+	}
+	prev := inventory.NewManager()
+	for _, objStatus := range objStatuses {
+		id := inventory.ObjMetadataFromObjectReference(objStatus.ObjectReference)
+		if !ids.Contains(id) || objStatus.Actuation != actuation.ActuationSucceeded {
+			continue
+		}
+		prev.SetObjectStatus(id, objStatus)
+	}
+	removed := taskQueue.Resume(func(t taskrunner.Task) bool {
+		return taskCompleted(t, prev)
+	})
+	klog.V(4).Infof("resume: removed %d completed tasks", removed)
+
+	im := taskContext.InventoryManager()
+	for _, objStatus := range prev.ObjectStatuses() {
+		im.SetObjectStatus(inventory.ObjMetadataFromObjectReference(objStatus.ObjectReference), objStatus)
+	}
+	return nil
+}
+
+// taskCompleted returns true if all the objects of the task were applied,
+// deleted or reconciled successfully, according to the passed manager.
+func taskCompleted(t taskrunner.Task, im *inventory.Manager) bool {
+	ids := t.Identifiers()
+	if len(ids) == 0 {
+		return false
+	}
+	for _, id := range ids {
+		var completed bool
+		switch t.Action() {
+		case event.ApplyAction:
+			completed = im.IsSuccessfulApply(id)
+		case event.PruneAction, event.DeleteAction:
+			completed = im.IsSuccessfulDelete(id)
+		case event.WaitAction:
+			completed = im.IsSuccessfulReconcile(id)
+		}
+		if !completed {
+			return false
+		}
+	}
+	return true
+}
+
+// runCompleted returns true if no object failed to be applied, deleted or
+// reconciled, according to the passed manager.
+func runCompleted(im *inventory.Manager) bool {
+	return len(im.FailedApplies()) == 0 && len(im.FailedDeletes()) == 0 &&
+		len(im.FailedReconciles()) == 0 && len(im.TimeoutReconciles()) == 0
+}
+
+// persistObjStatus returns a function that persists the object status
+// of the inventory manager in the inventory object after each apply, prune
+// and wait task. A failure to persist the status aborts the run, because
+// the run could not be resumed.
+func persistObjStatus(statusClient inventory.StatusClient, invInfo inventory.Info,
+	dryRun common.DryRunStrategy) func(taskrunner.Task, *taskrunner.TaskContext) error {
+	return func(t taskrunner.Task, taskContext *taskrunner.TaskContext) error {
+		if !solver.IsActuationTask(t) {
+			return nil
+		}
+		objStatuses := taskContext.InventoryManager().ObjectStatuses()
+		klog.V(4).Infof("persisting status of %d objects (task: %q)", len(objStatuses), t.Name())
+		if err := statusClient.UpdateObjStatus(invInfo, objStatuses, dryRun); err != nil {
+			return fmt.Errorf("failed to persist object status in inventory: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/task"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestTaskCompleted(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+
+	im := inventory.NewManager()
+	im.AddSuccessfulApply(depID, "dep-uid", 1)
+	im.AddSuccessfulDelete(secretID, "secret-uid")
+
+	testCases := map[string]struct {
+		task     taskrunner.Task
+		expected bool
+	}{
+		"applied": {
+			task: &task.ApplyTask{
+				Objects: object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])},
+			},
+			expected: true,
+		},
+		"partially applied": {
+			task: &task.ApplyTask{
+				Objects: object.UnstructuredSet{
+					testutil.Unstructured(t, resources["deployment"]),
+					testutil.Unstructured(t, resources["obj1"]),
+				},
+			},
+			expected: false,
+		},
+		"deleted object not applied": {
+			task: &task.ApplyTask{
+				Objects: object.UnstructuredSet{testutil.Unstructured(t, resources["secret"])},
+			},
+			expected: false,
+		},
+		"pruned": {
+			task: &task.PruneTask{
+				Objects: object.UnstructuredSet{testutil.Unstructured(t, resources["secret"])},
+			},
+			expected: true,
+		},
+		"applied, but not reconciled": {
+			task: &taskrunner.WaitTask{
+				Ids: object.ObjMetadataSet{depID},
+			},
+			expected: false,
+		},
+		"no objects": {
+			task:     &task.InvSetTask{},
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, taskCompleted(tc.task, im))
+		})
+	}
+}
+
+func TestPersistObjStatus(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+
+	testCases := map[string]struct {
+		task        taskrunner.Task
+		err         error
+		expected    []actuation.ObjectStatus
+		expectedErr string
+	}{
+		"persisted after apply": {
+			task: &task.ApplyTask{},
+			expected: []actuation.ObjectStatus{
+				{
+					ObjectReference: inventory.ObjectReferenceFromObjMetadata(depID),
+					Strategy:        actuation.ActuationStrategyApply,
+					Actuation:       actuation.ActuationSucceeded,
+					Reconcile:       actuation.ReconcilePending,
+					UID:             "dep-uid",
+					Generation:      1,
+				},
+			},
+		},
+		"not persisted after inventory update": {
+			task:     &task.InvAddTask{},
+			expected: nil,
+		},
+		"inventory cannot store status": {
+			task:        &task.ApplyTask{},
+			err:         fmt.Errorf("no status subresource"),
+			expectedErr: "failed to persist object status in inventory: no status subresource",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			invClient := inventory.NewFakeClient(object.ObjMetadataSet{})
+			invClient.Err = tc.err
+			taskContext := taskrunner.NewTaskContext(make(chan event.Event), cache.NewResourceCacheMap())
+			taskContext.InventoryManager().AddSuccessfulApply(depID, "dep-uid", 1)
+
+			invInfo := inventoryInfo{name: "abc-123", namespace: "default", id: "test"}
+			err := persistObjStatus(invClient, invInfo.toWrapped(), common.DryRunNone)(tc.task, taskContext)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expected, invClient.ObjStatus)
+		})
+	}
+}
+
+func TestRunCompleted(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+
+	testCases := map[string]struct {
+		update   func(im *inventory.Manager)
+		expected bool
+	}{
+		"applied and reconciled": {
+			update: func(im *inventory.Manager) {
+				im.AddSuccessfulApply(depID, "dep-uid", 1)
+				_ = im.SetSuccessfulReconcile(depID)
+				im.AddSuccessfulDelete(secretID, "secret-uid")
+			},
+			expected: true,
+		},
+		"apply failed": {
+			update: func(im *inventory.Manager) {
+				im.AddFailedApply(depID)
+			},
+			expected: false,
+		},
+		"delete failed": {
+			update: func(im *inventory.Manager) {
+				im.AddFailedDelete(secretID)
+			},
+			expected: false,
+		},
+		"reconcile timed out": {
+			update: func(im *inventory.Manager) {
+				im.AddSuccessfulApply(depID, "dep-uid", 1)
+				_ = im.SetTimeoutReconcile(depID)
+			},
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			im := inventory.NewManager()
+			tc.update(im)
+			assert.Equal(t, tc.expected, runCompleted(im))
+		})
+	}
+}
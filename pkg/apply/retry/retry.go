@@ -0,0 +1,177 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retry retries API calls that fail with transient errors, like
+// conflicts, throttling and webhook timeouts, with exponential backoff.
+package retry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// DefaultBackoff is the delay before the first retry, if the policy
+	// does not set one.
+	DefaultBackoff = 500 * time.Millisecond
+	// DefaultFactor is how much the delay grows after each retry, if the
+	// policy does not set it.
+	DefaultFactor = 2.0
+	// DefaultMaxBackoff is the longest delay between retries, if the
+	// policy does not set it.
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Policy defines how often and how long to wait before an operation
+// that failed with a retriable error is retried. The zero value does
+// not retry.
+type Policy struct {
+	// Attempts is the maximum number of times an operation is tried,
+	// including the first. Values less than two disable retries.
+	Attempts int
+	// Backoff is the delay before the first retry.
+	Backoff time.Duration
+	// Factor is how much the delay grows after each retry.
+	Factor float64
+	// MaxBackoff is the longest delay between retries.
+	MaxBackoff time.Duration
+	// Retriable classifies which errors are retried. If nil, IsRetriable
+	// is used.
+	Retriable func(error) bool
+}
+
+// Enabled returns true if the policy retries failed operations.
+func (p Policy) Enabled() bool {
+	return p.Attempts > 1
+}
+
+// Do calls the operation until it succeeds, it fails with an error that
+// is not retriable, the attempts are exhausted or the context is done.
+// Before each retry, onRetry is called, if not nil, with the number of
+// the attempt that failed, starting at one, the error and the delay
+// before the next attempt. Returns the error of the last attempt.
+func (p Policy) Do(ctx context.Context, operation func() error,
+	onRetry func(attempt int, err error, delay time.Duration)) error {
+	retriable := p.Retriable
+	if retriable == nil {
+		retriable = IsRetriable
+	}
+	backoff := p.backoff()
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt >= p.Attempts || !retriable(err) {
+			return err
+		}
+		delay := backoff.Step()
+		if onRetry != nil {
+			onRetry(attempt, err, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (p Policy) backoff() wait.Backoff {
+	b := wait.Backoff{
+		Duration: p.Backoff,
+		Factor:   p.Factor,
+		Cap:      p.MaxBackoff,
+		Steps:    p.Attempts,
+	}
+	if b.Duration <= 0 {
+		b.Duration = DefaultBackoff
+	}
+	if b.Factor <= 0 {
+		b.Factor = DefaultFactor
+	}
+	if b.Cap <= 0 {
+		b.Cap = DefaultMaxBackoff
+	}
+	return b
+}
+
+// ExhaustedError is implemented by errors returned by operations which
+// already retried the errors they wrap, so that the callers do not retry
+// them again.
+type ExhaustedError interface {
+	error
+	// RetriesExhausted returns true if the retries of the operation ran
+	// out.
+	RetriesExhausted() bool
+}
+
+// IsRetriable returns true if the error is transient, so the operation
+// may succeed if it is tried again:
+//   - conflicts (409), unless caused by a field manager conflict or a
+//     failed precondition, which fail again
+//   - throttling (429)
+//   - server timeouts, including admission webhooks that timed out
+//   - HTTP/2 stream errors
+//
+// Errors that implement ExhaustedError were already retried, and are not
+// retriable, whatever error they wrap.
+func IsRetriable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var exhausted ExhaustedError
+	if errors.As(err, &exhausted) && exhausted.RetriesExhausted() {
+		return false
+	}
+	switch {
+	case apierrors.IsConflict(err):
+		return !isFieldManagerConflict(err) && !strings.Contains(err.Error(), "Precondition failed")
+	case apierrors.IsTooManyRequests(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err):
+		return true
+	case IsStreamError(err), IsWebhookTimeout(err):
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStreamError checks if the error is a StreamError. Since kubectl wraps
+// the actual StreamError, we can't check the error type.
+func IsStreamError(err error) bool {
+	return strings.Contains(err.Error(), "stream error: stream ID ")
+}
+
+// IsWebhookTimeout checks if the error was caused by an admission webhook
+// that did not respond in time. The server returns these as internal
+// errors, so the error message is checked.
+func IsWebhookTimeout(err error) bool {
+	msg := err.Error()
+	if !strings.Contains(msg, "failed calling webhook") {
+		return false
+	}
+	return strings.Contains(msg, "context deadline exceeded") ||
+		strings.Contains(msg, "Client.Timeout exceeded") ||
+		strings.Contains(msg, "i/o timeout")
+}
+
+// isFieldManagerConflict checks if the conflict was caused by a server-side
+// apply field manager conflict.
+func isFieldManagerConflict(err error) bool {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		if details := status.Status().Details; details != nil {
+			for _, cause := range details.Causes {
+				if cause.Type == metav1.CauseTypeFieldManagerConflict {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
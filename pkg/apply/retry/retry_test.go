@@ -0,0 +1,217 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var deployments = schema.GroupResource{Group: "apps", Resource: "deployments"}
+
+func TestIsRetriable(t *testing.T) {
+	fieldManagerConflict := apierrors.NewApplyConflict([]metav1.StatusCause{
+		{
+			Type:    metav1.CauseTypeFieldManagerConflict,
+			Message: `conflict with "kubectl-edit"`,
+			Field:   ".spec.replicas",
+		},
+	}, "Apply failed with 1 conflict")
+
+	testCases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil": {
+			err:      nil,
+			expected: false,
+		},
+		"conflict": {
+			err:      apierrors.NewConflict(deployments, "foo", errors.New("the object has been modified")),
+			expected: true,
+		},
+		"wrapped conflict": {
+			err: fmt.Errorf("failed to update: %w",
+				apierrors.NewConflict(deployments, "foo", errors.New("the object has been modified"))),
+			expected: true,
+		},
+		"field manager conflict": {
+			err:      fieldManagerConflict,
+			expected: false,
+		},
+		"precondition failed": {
+			err: apierrors.NewConflict(deployments, "foo",
+				errors.New("Precondition failed: UID in precondition: 123, UID in object meta: 456")),
+			expected: false,
+		},
+		"too many requests": {
+			err:      apierrors.NewTooManyRequests("slow down", 1),
+			expected: true,
+		},
+		"server timeout": {
+			err:      apierrors.NewServerTimeout(deployments, "patch", 1),
+			expected: true,
+		},
+		"timeout": {
+			err:      apierrors.NewTimeoutError("request did not complete", 1),
+			expected: true,
+		},
+		"stream error": {
+			err:      errors.New("stream error: stream ID 7; INTERNAL_ERROR"),
+			expected: true,
+		},
+		"webhook timeout": {
+			err: apierrors.NewInternalError(errors.New(`failed calling webhook "validate.example.com": ` +
+				`Post "https://webhook.example.svc:443/validate": context deadline exceeded`)),
+			expected: true,
+		},
+		"webhook denied": {
+			err:      apierrors.NewInternalError(errors.New(`failed calling webhook "validate.example.com": denied`)),
+			expected: false,
+		},
+		"not found": {
+			err:      apierrors.NewNotFound(deployments, "foo"),
+			expected: false,
+		},
+		"invalid": {
+			err:      errors.New(`Deployment.apps "foo" is invalid: spec.selector: field is immutable`),
+			expected: false,
+		},
+		"exhausted conflict": {
+			err: fmt.Errorf("failed to update: %w", exhaustedError{
+				apierrors.NewConflict(deployments, "foo", errors.New("the object has been modified"))}),
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsRetriable(tc.err))
+		})
+	}
+}
+
+func TestPolicyDo(t *testing.T) {
+	conflict := apierrors.NewConflict(deployments, "foo", errors.New("the object has been modified"))
+	notFound := apierrors.NewNotFound(deployments, "foo")
+
+	testCases := map[string]struct {
+		policy           Policy
+		errs             []error
+		expectedCalls    int
+		expectedDelays   []time.Duration
+		expectedAttempts []int
+		expectedErr      error
+	}{
+		"zero policy does not retry": {
+			policy:        Policy{},
+			errs:          []error{conflict, nil},
+			expectedCalls: 1,
+			expectedErr:   conflict,
+		},
+		"succeeds after retries": {
+			policy: Policy{
+				Attempts: 5,
+				Backoff:  time.Millisecond,
+				Factor:   2,
+			},
+			errs:             []error{conflict, conflict, nil},
+			expectedCalls:    3,
+			expectedAttempts: []int{1, 2},
+			expectedDelays:   []time.Duration{time.Millisecond, 2 * time.Millisecond},
+			expectedErr:      nil,
+		},
+		"attempts exhausted": {
+			policy: Policy{
+				Attempts:   3,
+				Backoff:    time.Millisecond,
+				Factor:     10,
+				MaxBackoff: 5 * time.Millisecond,
+			},
+			errs:             []error{conflict, conflict, conflict, nil},
+			expectedCalls:    3,
+			expectedAttempts: []int{1, 2},
+			expectedDelays:   []time.Duration{time.Millisecond, 5 * time.Millisecond},
+			expectedErr:      conflict,
+		},
+		"error not retriable": {
+			policy: Policy{
+				Attempts: 3,
+				Backoff:  time.Millisecond,
+			},
+			errs:          []error{notFound, nil},
+			expectedCalls: 1,
+			expectedErr:   notFound,
+		},
+		"custom classification": {
+			policy: Policy{
+				Attempts:  3,
+				Backoff:   time.Millisecond,
+				Retriable: apierrors.IsNotFound,
+			},
+			errs:             []error{notFound, nil},
+			expectedCalls:    2,
+			expectedAttempts: []int{1},
+			expectedDelays:   []time.Duration{time.Millisecond},
+			expectedErr:      nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			calls := 0
+			var attempts []int
+			var delays []time.Duration
+			err := tc.policy.Do(context.TODO(), func() error {
+				err := tc.errs[calls]
+				calls++
+				return err
+			}, func(attempt int, err error, delay time.Duration) {
+				assert.Error(t, err)
+				attempts = append(attempts, attempt)
+				delays = append(delays, delay)
+			})
+			assert.Equal(t, tc.expectedErr, err)
+			assert.Equal(t, tc.expectedCalls, calls)
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			assert.Equal(t, tc.expectedDelays, delays)
+		})
+	}
+}
+
+func TestPolicyDoCancelled(t *testing.T) {
+	conflict := apierrors.NewConflict(deployments, "foo", errors.New("the object has been modified"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	policy := Policy{Attempts: 5, Backoff: time.Hour}
+	err := policy.Do(ctx, func() error {
+		calls++
+		return conflict
+	}, nil)
+	assert.Equal(t, conflict, err)
+	assert.Equal(t, 1, calls)
+}
+
+// exhaustedError wraps an error whose retries ran out.
+type exhaustedError struct {
+	error
+}
+
+func (e exhaustedError) Unwrap() error {
+	return e.error
+}
+
+func (e exhaustedError) RetriesExhausted() bool {
+	return true
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	pollevent "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestSnapshotObjects(t *testing.T) {
+	deployment := testutil.Unstructured(t, resources["deployment"])
+	secret := testutil.Unstructured(t, resources["secret"])
+	unregistered := testutil.Unstructured(t, `
+apiVersion: example.com/v1
+kind: Unregistered
+metadata:
+  name: unregistered
+  namespace: default
+`)
+
+	testCases := map[string]struct {
+		resources   object.UnstructuredSet
+		clusterObjs object.UnstructuredSet
+		objs        object.UnstructuredSet
+		expected    object.ObjMetadataSet
+	}{
+		"existing objects are snapshot": {
+			resources:   object.UnstructuredSet{deployment, secret},
+			clusterObjs: object.UnstructuredSet{deployment, secret},
+			objs:        object.UnstructuredSet{deployment, secret},
+			expected: object.ObjMetadataSet{
+				object.UnstructuredToObjMetadata(deployment),
+				object.UnstructuredToObjMetadata(secret),
+			},
+		},
+		"objects not found are omitted": {
+			resources:   object.UnstructuredSet{deployment, secret},
+			clusterObjs: object.UnstructuredSet{deployment},
+			objs:        object.UnstructuredSet{deployment, secret},
+			expected: object.ObjMetadataSet{
+				object.UnstructuredToObjMetadata(deployment),
+			},
+		},
+		"objects of unregistered types are omitted": {
+			objs:     object.UnstructuredSet{unregistered},
+			expected: object.ObjMetadataSet{},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			invInfo := inventoryInfo{
+				name:      "abc-123",
+				namespace: "default",
+				id:        "test",
+			}
+			applier := newTestApplier(t, invInfo, tc.resources, tc.clusterObjs,
+				newFakePoller([]pollevent.Event{}))
+
+			snapshots, err := applier.snapshotObjects(tc.objs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, object.UnstructuredSetToObjMetadataSet(snapshots))
+		})
+	}
+}
+
+func TestApplierRollbackTask(t *testing.T) {
+	testCases := map[string]struct {
+		options  ApplierOptions
+		expected bool
+	}{
+		"rollback disabled": {
+			options:  ApplierOptions{},
+			expected: false,
+		},
+		"rollback enabled": {
+			options:  ApplierOptions{RollbackOnFailure: true},
+			expected: true,
+		},
+		"rollback ignored for dry-run": {
+			options: ApplierOptions{
+				RollbackOnFailure: true,
+				DryRunStrategy:    common.DryRunClient,
+			},
+			expected: false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			invInfo := inventoryInfo{
+				name:      "abc-123",
+				namespace: "default",
+				id:        "test",
+			}
+			deployment := testutil.Unstructured(t, resources["deployment"])
+			poller := newFakePoller([]pollevent.Event{})
+			poller.Start()
+			applier := newTestApplier(t, invInfo,
+				object.UnstructuredSet{deployment},
+				object.UnstructuredSet{deployment},
+				poller)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			tc.options.InventoryPolicy = inventory.PolicyAdoptAll
+
+			// The task queue is reported by the first event, so the run
+			// is cancelled once it is received.
+			var groups []event.ActionGroup
+			for e := range applier.Run(ctx, invInfo.toWrapped(), object.UnstructuredSet{deployment}, tc.options) {
+				if e.Type == event.InitType {
+					groups = e.InitEvent.ActionGroups
+					cancel()
+				}
+			}
+			require.NotEmpty(t, groups)
+
+			found := false
+			for _, group := range groups {
+				if group.Action == event.RollbackAction {
+					assert.Equal(t, "rollback-0", group.Name)
+					found = true
+				}
+			}
+			assert.Equal(t, tc.expected, found)
+		})
+	}
+}
@@ -28,12 +28,14 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/task"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 )
 
@@ -50,6 +52,9 @@ type TaskQueueBuilder struct {
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+	// Retry defines how the apply, prune and inventory tasks retry
+	// operations that fail with transient errors.
+	Retry retry.Policy
 	// The accumulated tasks and counter variables to name tasks.
 	invAddCounter    int
 	invSetCounter    int
@@ -57,6 +62,9 @@ type TaskQueueBuilder struct {
 	applyCounter     int
 	waitCounter      int
 	pruneCounter     int
+	rollbackCounter  int
+	dagApplyCounter  int
+	hookCounter      int
 	tasks            []taskrunner.Task
 }
 
@@ -76,6 +84,10 @@ func (tq *TaskQueue) ToActionGroups() []event.ActionGroup {
 	var ags []event.ActionGroup
 
 	for _, t := range tq.tasks {
+		if gt, ok := t.(taskrunner.GroupedTask); ok {
+			ags = append(ags, gt.ActionGroups()...)
+			continue
+		}
 		ags = append(ags, event.ActionGroup{
 			Name:        t.Name(),
 			Action:      t.Action(),
@@ -85,6 +97,53 @@ func (tq *TaskQueue) ToActionGroups() []event.ActionGroup {
 	return ags
 }
 
+// Resume removes the apply, prune and wait tasks that completed in a
+// previous run from the front of the queue, up to the first such task that
+// did not complete. That task and all the tasks after it are kept, as well
+// as the tasks that do not actuate or wait for objects, like the inventory
+// and hook tasks. Returns the number of removed tasks.
+func (tq *TaskQueue) Resume(completed func(taskrunner.Task) bool) int {
+	var tasks []taskrunner.Task
+	resumed := false
+	for _, t := range tq.tasks {
+		if !resumed && IsActuationTask(t) {
+			if completed(t) {
+				continue
+			}
+			resumed = true
+		}
+		tasks = append(tasks, t)
+	}
+	removed := len(tq.tasks) - len(tasks)
+	tq.tasks = tasks
+	return removed
+}
+
+// IsActuationTask returns true if the task applies, deletes or waits for
+// objects.
+func IsActuationTask(t taskrunner.Task) bool {
+	switch t.Action() {
+	case event.ApplyAction, event.PruneAction, event.DeleteAction, event.WaitAction:
+		return true
+	default:
+		return false
+	}
+}
+
+// Scheduler determines when objects are applied, relative to their
+// dependencies.
+type Scheduler int
+
+const (
+	// LevelScheduler applies the objects in levels of the dependency graph,
+	// waiting for all the objects in a level to reconcile before applying
+	// the next level.
+	LevelScheduler Scheduler = iota
+	// DAGScheduler applies each object as soon as all of its own
+	// dependencies are reconciled.
+	DAGScheduler
+)
+
 type Options struct {
 	ServerSideOptions      common.ServerSideOptions
 	ReconcileTimeout       time.Duration
@@ -93,6 +152,21 @@ type Options struct {
 	PrunePropagationPolicy metav1.DeletionPropagation
 	PruneTimeout           time.Duration
 	InventoryPolicy        inventory.Policy
+	ApplyConcurrency       int
+	ApplyScheduler         Scheduler
+	HookTimeout            time.Duration
+	// ReplaceOnImmutableChange and ReplaceFilters configure the apply
+	// tasks to replace objects whose immutable fields were changed.
+	ReplaceOnImmutableChange bool
+	ReplaceFilters           []filter.ValidationFilter
+	// MigrateClientSideApply configures the apply tasks to move the fields
+	// of objects applied client-side to the field manager.
+	MigrateClientSideApply bool
+	// RemoveFinalizers and RemoveFinalizersAfter configure the prune wait
+	// tasks to remove the named finalizers from objects still terminating
+	// after the grace period.
+	RemoveFinalizers      []string
+	RemoveFinalizersAfter time.Duration
 }
 
 // Build returns the queue of tasks that have been created
@@ -112,36 +186,110 @@ func (t *TaskQueueBuilder) AppendInvAddTask(inv inventory.Info, applyObjs object
 		InvInfo:   inv,
 		Objects:   applyObjs,
 		DryRun:    dryRun,
+		Retry:     t.Retry,
 	})
 	t.invAddCounter++
 	return t
 }
 
 // AppendInvSetTask appends an inventory set task to the task queue.
+// prevInvIds is the set of objects in the inventory before the run.
 // Returns a pointer to the Builder to chain function calls.
-func (t *TaskQueueBuilder) AppendInvSetTask(inv inventory.Info, dryRun common.DryRunStrategy) *TaskQueueBuilder {
+func (t *TaskQueueBuilder) AppendInvSetTask(inv inventory.Info, prevInvIds object.ObjMetadataSet,
+	dryRun common.DryRunStrategy) *TaskQueueBuilder {
 	klog.V(2).Infoln("adding inventory set task")
-	prevInvIds, _ := t.InvClient.GetClusterObjs(inv)
 	t.tasks = append(t.tasks, &task.InvSetTask{
 		TaskName:      fmt.Sprintf("inventory-set-%d", t.invSetCounter),
 		InvClient:     t.InvClient,
 		InvInfo:       inv,
 		PrevInventory: prevInvIds,
 		DryRun:        dryRun,
+		Retry:         t.Retry,
 	})
 	t.invSetCounter++
 	return t
 }
 
+// AppendRollbackTask appends a task to revert the apply and prune tasks
+// if any of the objects failed to apply or timed out reconciling, or if the
+// run was aborted. The snapshots are the live state of the apply objects
+// before the run, and prevInvIds the objects in the inventory before the run.
+// Returns a pointer to the Builder to chain function calls.
+func (t *TaskQueueBuilder) AppendRollbackTask(inv inventory.Info, applyObjs object.UnstructuredSet,
+	snapshots object.UnstructuredSet, pruneObjs object.UnstructuredSet, prevInvIds object.ObjMetadataSet,
+	o Options) *TaskQueueBuilder {
+	applyObjs = t.Collector.FilterInvalidObjects(applyObjs)
+	pruneObjs = t.Collector.FilterInvalidObjects(pruneObjs)
+	if !o.Prune {
+		pruneObjs = object.UnstructuredSet{}
+	}
+	klog.V(2).Infof("adding rollback task (%d objects)", len(applyObjs)+len(pruneObjs))
+	t.tasks = append(t.tasks, &task.RollbackTask{
+		TaskName:      fmt.Sprintf("rollback-%d", t.rollbackCounter),
+		DynamicClient: t.DynamicClient,
+		Mapper:        t.Mapper,
+		InvClient:     t.InvClient,
+		InvInfo:       inv,
+		ApplyIds:      object.UnstructuredSetToObjMetadataSet(sortedObjs(applyObjs)),
+		Snapshots:     snapshots,
+		PruneObjs:     sortedObjs(pruneObjs),
+		PrevInventory: prevInvIds,
+		DryRun:        o.DryRunStrategy,
+	})
+	t.rollbackCounter++
+	return t
+}
+
+// sortedObjs returns the objects in apply order. Errors were already
+// collected when sorting the objects of the apply and prune tasks, so the
+// passed order is kept if the objects can't be sorted.
+func sortedObjs(objs object.UnstructuredSet) object.UnstructuredSet {
+	objSets, err := graph.SortObjs(objs)
+	if err != nil {
+		return objs
+	}
+	sorted := object.UnstructuredSet{}
+	for _, objSet := range objSets {
+		sorted = append(sorted, objSet...)
+	}
+	return sorted
+}
+
+// AppendHookTask appends a task to run the hooks of the passed lifecycle
+// phase, if there are any. Hooks are not run for dry-run.
+// Returns a pointer to the Builder to chain function calls.
+func (t *TaskQueueBuilder) AppendHookTask(phase hook.Phase, hookObjs object.UnstructuredSet,
+	o Options) *TaskQueueBuilder {
+	hookObjs = t.Collector.FilterInvalidObjects(hook.FilterPhase(hookObjs, phase))
+	if len(hookObjs) == 0 || o.DryRunStrategy.ClientOrServerDryRun() {
+		return t
+	}
+	klog.V(2).Infof("adding %s hook task (%d objects)", phase, len(hookObjs))
+	t.tasks = append(t.tasks, &task.HookTask{
+		TaskName:      fmt.Sprintf("%s-hook-%d", phase, t.hookCounter),
+		DynamicClient: t.DynamicClient,
+		Mapper:        t.Mapper,
+		Phase:         phase,
+		Objects:       hookObjs,
+		Timeout:       o.HookTimeout,
+	})
+	t.hookCounter++
+	return t
+}
+
 // AppendDeleteInvTask appends to the task queue a task to delete the inventory object.
+// prevInvIds is the set of objects in the inventory before the run.
 // Returns a pointer to the Builder to chain function calls.
-func (t *TaskQueueBuilder) AppendDeleteInvTask(inv inventory.Info, dryRun common.DryRunStrategy) *TaskQueueBuilder {
+func (t *TaskQueueBuilder) AppendDeleteInvTask(inv inventory.Info, prevInvIds object.ObjMetadataSet,
+	dryRun common.DryRunStrategy) *TaskQueueBuilder {
 	klog.V(2).Infoln("adding delete inventory task")
 	t.tasks = append(t.tasks, &task.DeleteInvTask{
-		TaskName:  fmt.Sprintf("delete-inventory-%d", t.deleteInvCounter),
-		InvClient: t.InvClient,
-		InvInfo:   inv,
-		DryRun:    dryRun,
+		TaskName:      fmt.Sprintf("delete-inventory-%d", t.deleteInvCounter),
+		InvClient:     t.InvClient,
+		InvInfo:       inv,
+		PrevInventory: prevInvIds,
+		DryRun:        dryRun,
+		Retry:         t.Retry,
 	})
 	t.deleteInvCounter++
 	return t
@@ -164,6 +312,12 @@ func (t *TaskQueueBuilder) AppendApplyTask(applyObjs object.UnstructuredSet,
 		OpenAPIGetter:     t.OpenAPIGetter,
 		InfoHelper:        t.InfoHelper,
 		Mapper:            t.Mapper,
+		Concurrency:       o.ApplyConcurrency,
+		Retry:             t.Retry,
+
+		ReplaceOnImmutableChange: o.ReplaceOnImmutableChange,
+		ReplaceFilters:           o.ReplaceFilters,
+		MigrateClientSideApply:   o.MigrateClientSideApply,
 	})
 	t.applyCounter++
 	return t
@@ -201,6 +355,7 @@ func (t *TaskQueueBuilder) AppendPruneTask(pruneObjs object.UnstructuredSet,
 			PropagationPolicy: o.PrunePropagationPolicy,
 			DryRunStrategy:    o.DryRunStrategy,
 			Destroy:           t.Destroy,
+			Retry:             t.Retry,
 		},
 	)
 	t.pruneCounter++
@@ -218,6 +373,43 @@ func (t *TaskQueueBuilder) AppendApplyWaitTasks(applyObjs object.UnstructuredSet
 	if err != nil {
 		t.Collector.Collect(err)
 	}
+	if o.ApplyScheduler == DAGScheduler {
+		// The same apply and wait tasks are run by a single task, which
+		// starts each object as soon as its dependencies are done.
+		// Errors were already collected when sorting.
+		deps, _ := graph.Dependencies(applyObjs)
+		start := len(t.tasks)
+		t.appendApplyWaitLevels(applySets, applyFilters, applyMutators, o)
+		if len(t.tasks) == start {
+			// nothing to apply
+			return t
+		}
+		dagTask := &task.DAGApplyTask{
+			TaskName:     fmt.Sprintf("dag-apply-%d", t.dagApplyCounter),
+			Dependencies: deps,
+			Concurrency:  o.ApplyConcurrency,
+		}
+		for _, tsk := range t.tasks[start:] {
+			switch tsk := tsk.(type) {
+			case *task.ApplyTask:
+				dagTask.ApplyTasks = append(dagTask.ApplyTasks, tsk)
+			case *taskrunner.WaitTask:
+				dagTask.WaitTasks = append(dagTask.WaitTasks, tsk)
+			}
+		}
+		t.tasks = append(t.tasks[:start], dagTask)
+		t.dagApplyCounter++
+		return t
+	}
+	return t.appendApplyWaitLevels(applySets, applyFilters, applyMutators, o)
+}
+
+// appendApplyWaitLevels adds an apply task and a wait task to the task
+// queue for each of the sorted sets of objects to apply, skipping the
+// wait tasks for dry-run. Returns a pointer to the Builder to chain
+// function calls.
+func (t *TaskQueueBuilder) appendApplyWaitLevels(applySets []object.UnstructuredSet,
+	applyFilters []filter.ValidationFilter, applyMutators []mutator.Interface, o Options) *TaskQueueBuilder {
 	for _, applySet := range applySets {
 		applySet = t.Collector.FilterInvalidObjects(applySet)
 		if len(applySet) == 0 {
@@ -255,6 +447,14 @@ func (t *TaskQueueBuilder) AppendPruneWaitTasks(pruneObjs object.UnstructuredSet
 			if !o.DryRunStrategy.ClientOrServerDryRun() {
 				pruneIds := object.UnstructuredSetToObjMetadataSet(pruneSet)
 				t.AppendWaitTask(pruneIds, taskrunner.AllNotFound, o.PruneTimeout)
+				if len(o.RemoveFinalizers) > 0 {
+					waitTask := t.tasks[len(t.tasks)-1].(*taskrunner.WaitTask)
+					waitTask.FinalizerRemoval = &taskrunner.FinalizerRemoval{
+						Client:      t.DynamicClient,
+						Finalizers:  o.RemoveFinalizers,
+						GracePeriod: o.RemoveFinalizersAfter,
+					}
+				}
 			}
 		}
 	}
@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
@@ -20,6 +21,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/object/graph"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
 	"sigs.k8s.io/cli-utils/pkg/object/validation"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
@@ -110,6 +112,7 @@ func TestTaskQueueBuilder_AppendApplyWaitTasks(t *testing.T) {
 	asserter := testutil.NewAsserter(
 		cmpopts.EquateErrors(),
 		waitTaskComparer(),
+		cmpopts.IgnoreUnexported(task.DAGApplyTask{}, task.ApplyTask{}),
 	)
 
 	testCases := map[string]struct {
@@ -342,6 +345,107 @@ func TestTaskQueueBuilder_AppendApplyWaitTasks(t *testing.T) {
 				},
 			},
 		},
+		"dag scheduler creates single task with apply and wait tasks": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["namespace"]),
+				testutil.Unstructured(t, resources["pod"]),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			options: Options{
+				ApplyScheduler:   DAGScheduler,
+				ApplyConcurrency: 2,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.DAGApplyTask{
+					TaskName: "dag-apply-0",
+					ApplyTasks: []*task.ApplyTask{
+						{
+							TaskName: "apply-0",
+							Objects: []*unstructured.Unstructured{
+								testutil.Unstructured(t, resources["namespace"]),
+							},
+							DryRunStrategy: common.DryRunNone,
+							Concurrency:    2,
+						},
+						{
+							TaskName: "apply-1",
+							Objects: []*unstructured.Unstructured{
+								testutil.Unstructured(t, resources["secret"]),
+								testutil.Unstructured(t, resources["pod"]),
+							},
+							DryRunStrategy: common.DryRunNone,
+							Concurrency:    2,
+						},
+					},
+					WaitTasks: []*taskrunner.WaitTask{
+						{
+							TaskName: "wait-0",
+							Ids: object.ObjMetadataSet{
+								testutil.ToIdentifier(t, resources["namespace"]),
+							},
+							Condition: taskrunner.AllCurrent,
+						},
+						{
+							TaskName: "wait-1",
+							Ids: object.ObjMetadataSet{
+								testutil.ToIdentifier(t, resources["secret"]),
+								testutil.ToIdentifier(t, resources["pod"]),
+							},
+							Condition: taskrunner.AllCurrent,
+						},
+					},
+					Dependencies: map[object.ObjMetadata]object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["namespace"]): {},
+						testutil.ToIdentifier(t, resources["pod"]): {
+							testutil.ToIdentifier(t, resources["namespace"]),
+						},
+						testutil.ToIdentifier(t, resources["secret"]): {
+							testutil.ToIdentifier(t, resources["namespace"]),
+						},
+					},
+					Concurrency: 2,
+				},
+			},
+		},
+		"dag scheduler with dryrun skips wait tasks": {
+			applyObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddDependsOn(t, testutil.ToIdentifier(t, resources["secret"]))),
+				testutil.Unstructured(t, resources["secret"]),
+			},
+			options: Options{
+				ApplyScheduler: DAGScheduler,
+				DryRunStrategy: common.DryRunClient,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.DAGApplyTask{
+					TaskName: "dag-apply-0",
+					ApplyTasks: []*task.ApplyTask{
+						{
+							TaskName: "apply-0",
+							Objects: []*unstructured.Unstructured{
+								testutil.Unstructured(t, resources["secret"]),
+							},
+							DryRunStrategy: common.DryRunClient,
+						},
+						{
+							TaskName: "apply-1",
+							Objects: []*unstructured.Unstructured{
+								testutil.Unstructured(t, resources["deployment"],
+									testutil.AddDependsOn(t, testutil.ToIdentifier(t, resources["secret"]))),
+							},
+							DryRunStrategy: common.DryRunClient,
+						},
+					},
+					Dependencies: map[object.ObjMetadata]object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["deployment"]): {
+							testutil.ToIdentifier(t, resources["secret"]),
+						},
+						testutil.ToIdentifier(t, resources["secret"]): {},
+					},
+				},
+			},
+		},
 		"deployment depends on secret creates multiple tasks": {
 			applyObjs: []*unstructured.Unstructured{
 				testutil.Unstructured(t, resources["deployment"],
@@ -417,6 +521,13 @@ func TestTaskQueueBuilder_AppendApplyWaitTasks(t *testing.T) {
 					typedTask.Mapper = mapper
 				case *taskrunner.WaitTask:
 					typedTask.Mapper = mapper
+				case *task.DAGApplyTask:
+					for _, at := range typedTask.ApplyTasks {
+						at.Mapper = mapper
+					}
+					for _, wt := range typedTask.WaitTasks {
+						wt.Mapper = mapper
+					}
 				}
 			}
 
@@ -453,6 +564,7 @@ func TestTaskQueueBuilder_AppendPruneWaitTasks(t *testing.T) {
 	asserter := testutil.NewAsserter(
 		cmpopts.EquateErrors(),
 		waitTaskComparer(),
+		cmpopts.IgnoreUnexported(task.PruneTask{}),
 	)
 
 	testCases := map[string]struct {
@@ -511,6 +623,35 @@ func TestTaskQueueBuilder_AppendPruneWaitTasks(t *testing.T) {
 				},
 			},
 		},
+		"remove finalizers, wait task removes finalizers": {
+			pruneObjs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["default-pod"]),
+			},
+			options: Options{
+				Prune:                 true,
+				RemoveFinalizers:      []string{"example.com/cleanup"},
+				RemoveFinalizersAfter: time.Minute,
+			},
+			expectedTasks: []taskrunner.Task{
+				&task.PruneTask{
+					TaskName: "prune-0",
+					Objects: []*unstructured.Unstructured{
+						testutil.Unstructured(t, resources["default-pod"]),
+					},
+				},
+				&taskrunner.WaitTask{
+					TaskName: "wait-0",
+					Ids: object.ObjMetadataSet{
+						testutil.ToIdentifier(t, resources["default-pod"]),
+					},
+					Condition: taskrunner.AllNotFound,
+					FinalizerRemoval: &taskrunner.FinalizerRemoval{
+						Finalizers:  []string{"example.com/cleanup"},
+						GracePeriod: time.Minute,
+					},
+				},
+			},
+		},
 		"dependent resources, two prune tasks, two wait tasks": {
 			pruneObjs: []*unstructured.Unstructured{
 				testutil.Unstructured(t, resources["pod"],
@@ -807,6 +948,148 @@ func TestTaskQueueBuilder_AppendPruneWaitTasks(t *testing.T) {
 	}
 }
 
+func TestTaskQueueBuilder_AppendHookTask(t *testing.T) {
+	preApply := testutil.Unstructured(t, resources["pod"])
+	preApply.SetAnnotations(map[string]string{hook.Annotation: string(hook.PreApply)})
+	postApply := testutil.Unstructured(t, resources["default-pod"])
+	postApply.SetAnnotations(map[string]string{hook.Annotation: string(hook.PostApply)})
+	invalid := testutil.Unstructured(t, resources["deployment"])
+	invalid.SetAnnotations(map[string]string{hook.Annotation: string(hook.PreApply)})
+
+	// Use a custom Asserter to customize the comparison options
+	asserter := testutil.NewAsserter(
+		cmpopts.EquateErrors(),
+		cmpopts.IgnoreUnexported(task.HookTask{}),
+	)
+
+	testCases := map[string]struct {
+		hooks         object.UnstructuredSet
+		invalidIds    object.ObjMetadataSet
+		options       Options
+		expectedTasks []taskrunner.Task
+	}{
+		"no hooks, no tasks": {
+			hooks:         object.UnstructuredSet{},
+			expectedTasks: nil,
+		},
+		"hooks of each phase in their own task": {
+			hooks:   object.UnstructuredSet{preApply, postApply},
+			options: Options{HookTimeout: time.Minute},
+			expectedTasks: []taskrunner.Task{
+				&task.HookTask{
+					TaskName: "pre-apply-hook-0",
+					Phase:    hook.PreApply,
+					Objects:  object.UnstructuredSet{preApply},
+					Timeout:  time.Minute,
+				},
+				&task.HookTask{
+					TaskName: "post-apply-hook-1",
+					Phase:    hook.PostApply,
+					Objects:  object.UnstructuredSet{postApply},
+					Timeout:  time.Minute,
+				},
+			},
+		},
+		"invalid hooks are skipped": {
+			hooks:      object.UnstructuredSet{preApply, invalid},
+			invalidIds: object.ObjMetadataSet{object.UnstructuredToObjMetadata(invalid)},
+			expectedTasks: []taskrunner.Task{
+				&task.HookTask{
+					TaskName: "pre-apply-hook-0",
+					Phase:    hook.PreApply,
+					Objects:  object.UnstructuredSet{preApply},
+				},
+			},
+		},
+		"no hooks for dry-run": {
+			hooks:         object.UnstructuredSet{preApply, postApply},
+			options:       Options{DryRunStrategy: common.DryRunClient},
+			expectedTasks: nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			mapper := testutil.NewFakeRESTMapper()
+			// inject mapper for equality comparison
+			for _, t := range tc.expectedTasks {
+				t.(*task.HookTask).Mapper = mapper
+			}
+
+			tqb := TaskQueueBuilder{
+				Mapper:    mapper,
+				Collector: &validation.Collector{InvalidIds: tc.invalidIds},
+			}
+			tq := tqb.
+				AppendHookTask(hook.PreApply, tc.hooks, tc.options).
+				AppendHookTask(hook.PostApply, tc.hooks, tc.options).
+				Build()
+			asserter.Equal(t, tc.expectedTasks, tq.tasks)
+		})
+	}
+}
+
+func TestTaskQueue_Resume(t *testing.T) {
+	pod := testutil.ToIdentifier(t, resources["pod"])
+	deployment := testutil.ToIdentifier(t, resources["deployment"])
+	tasks := []taskrunner.Task{
+		&task.HookTask{TaskName: "pre-apply-hook-0"},
+		&task.InvAddTask{TaskName: "inventory-add-0"},
+		&task.ApplyTask{TaskName: "apply-0", Objects: object.UnstructuredSet{testutil.Unstructured(t, resources["pod"])}},
+		&taskrunner.WaitTask{TaskName: "wait-0", Ids: object.ObjMetadataSet{pod}},
+		&task.ApplyTask{TaskName: "apply-1", Objects: object.UnstructuredSet{testutil.Unstructured(t, resources["deployment"])}},
+		&taskrunner.WaitTask{TaskName: "wait-1", Ids: object.ObjMetadataSet{deployment}},
+		&task.PruneTask{TaskName: "prune-0"},
+		&task.InvSetTask{TaskName: "inventory-set-0"},
+	}
+
+	testCases := map[string]struct {
+		completed       []string
+		expectedTasks   []string
+		expectedRemoved int
+	}{
+		"nothing completed": {
+			completed: nil,
+			expectedTasks: []string{"pre-apply-hook-0", "inventory-add-0", "apply-0", "wait-0",
+				"apply-1", "wait-1", "prune-0", "inventory-set-0"},
+			expectedRemoved: 0,
+		},
+		"resume from first pending task": {
+			completed: []string{"apply-0", "wait-0", "apply-1"},
+			expectedTasks: []string{"pre-apply-hook-0", "inventory-add-0",
+				"wait-1", "prune-0", "inventory-set-0"},
+			expectedRemoved: 3,
+		},
+		"completed tasks after the first pending task are kept": {
+			completed: []string{"apply-0", "apply-1", "wait-1"},
+			expectedTasks: []string{"pre-apply-hook-0", "inventory-add-0",
+				"wait-0", "apply-1", "wait-1", "prune-0", "inventory-set-0"},
+			expectedRemoved: 1,
+		},
+		"everything completed": {
+			completed:       []string{"apply-0", "wait-0", "apply-1", "wait-1", "prune-0"},
+			expectedTasks:   []string{"pre-apply-hook-0", "inventory-add-0", "inventory-set-0"},
+			expectedRemoved: 5,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			tq := &TaskQueue{tasks: tasks}
+			completed := sets.NewString(tc.completed...)
+			removed := tq.Resume(func(t taskrunner.Task) bool {
+				return completed.Has(t.Name())
+			})
+			assert.Equal(t, tc.expectedRemoved, removed)
+			var names []string
+			for _, t := range tq.tasks {
+				names = append(names, t.Name())
+			}
+			assert.Equal(t, tc.expectedTasks, names)
+		})
+	}
+}
+
 // waitTaskComparer allows comparion of WaitTasks, ignoring private fields.
 func waitTaskComparer() cmp.Option {
 	return cmp.Comparer(func(x, y *taskrunner.WaitTask) bool {
@@ -820,6 +1103,7 @@ func waitTaskComparer() cmp.Option {
 			x.Ids.Hash() == y.Ids.Hash() && // exact order match
 			x.Condition == y.Condition &&
 			x.Timeout == y.Timeout &&
-			cmp.Equal(x.Mapper, y.Mapper)
+			cmp.Equal(x.Mapper, y.Mapper) &&
+			cmp.Equal(x.FinalizerRemoval, y.FinalizerRemoval)
 	})
 }
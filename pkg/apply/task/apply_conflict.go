@@ -0,0 +1,150 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+// conflictManagerRegexp matches the quoted field manager in the server-side
+// apply conflict messages, like `conflict with "kubectl" using apps/v1`.
+var conflictManagerRegexp = regexp.MustCompile(`conflicts? with ("(?:[^"\\]|\\.)*")`)
+
+// conflictMessagePrefix starts the message of server-side apply conflicts.
+const conflictMessagePrefix = "Apply failed with "
+
+// parseApplyConflicts returns the fields and field managers of the
+// server-side apply conflicts that caused the error, if any. Since kubectl
+// wraps the actual StatusError without its causes, the conflicts are
+// parsed from the error message, if the causes are not available.
+func parseApplyConflicts(err error) []event.ApplyConflict {
+	if err == nil {
+		return nil
+	}
+	var conflicts []event.ApplyConflict
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Details != nil {
+		for _, cause := range status.Status().Details.Causes {
+			if cause.Type != metav1.CauseTypeFieldManagerConflict {
+				continue
+			}
+			conflicts = append(conflicts, event.ApplyConflict{
+				Field:   cause.Field,
+				Manager: parseConflictManager(cause.Message),
+			})
+		}
+		if len(conflicts) > 0 {
+			return conflicts
+		}
+	}
+
+	msg := err.Error()
+	i := strings.Index(msg, conflictMessagePrefix)
+	if i < 0 {
+		return nil
+	}
+	// The message lists a single conflict on one line:
+	//   Apply failed with 1 conflict: conflict with "a" using v1: .spec.x
+	// and multiple conflicts grouped by field manager:
+	//   Apply failed with 2 conflicts: conflicts with "a" using v1:
+	//   - .spec.x
+	//   conflicts with "b":
+	//   - .spec.y
+	manager := ""
+	for _, line := range strings.Split(msg[i:], "\n") {
+		if loc := conflictManagerRegexp.FindStringSubmatchIndex(line); loc != nil {
+			manager = unquoteManager(line[loc[2]:loc[3]])
+			if j := strings.LastIndex(line, ": "); j > loc[1] {
+				conflicts = append(conflicts, event.ApplyConflict{
+					Field:   strings.TrimSpace(line[j+2:]),
+					Manager: manager,
+				})
+			}
+			continue
+		}
+		if manager == "" || !strings.HasPrefix(line, "- ") {
+			break
+		}
+		conflicts = append(conflicts, event.ApplyConflict{
+			Field:   strings.TrimSpace(strings.TrimPrefix(line, "- ")),
+			Manager: manager,
+		})
+	}
+	return conflicts
+}
+
+// parseConflictManager returns the field manager quoted in the message.
+func parseConflictManager(msg string) string {
+	match := conflictManagerRegexp.FindStringSubmatch(msg)
+	if match == nil {
+		return msg
+	}
+	return unquoteManager(match[1])
+}
+
+// unquoteManager returns the field manager quoted by the server.
+func unquoteManager(quoted string) string {
+	manager, err := strconv.Unquote(quoted)
+	if err != nil {
+		return quoted
+	}
+	return manager
+}
+
+// forceableFields returns the paths of the fields that may be taken from
+// other field managers, listed in the force-conflicts annotation.
+func forceableFields(obj *unstructured.Unstructured) []string {
+	value, found := obj.GetAnnotations()[common.ForceConflictsAnnotation]
+	if !found {
+		return nil
+	}
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// canForceConflicts returns true if all the conflicting fields are listed
+// in the force-conflicts annotation of the object, or are below one of the
+// listed fields.
+func canForceConflicts(obj *unstructured.Unstructured, conflicts []event.ApplyConflict) bool {
+	fields := forceableFields(obj)
+	if len(conflicts) == 0 || len(fields) == 0 {
+		return false
+	}
+	for _, conflict := range conflicts {
+		if !isForceableField(conflict.Field, fields) {
+			return false
+		}
+	}
+	return true
+}
+
+// isForceableField returns true if the field is one of the passed fields,
+// or is below one of them.
+func isForceableField(field string, fields []string) bool {
+	for _, f := range fields {
+		if field == f {
+			return true
+		}
+		if strings.HasPrefix(field, f) {
+			if next := field[len(f)]; next == '.' || next == '[' {
+				return true
+			}
+		}
+	}
+	return false
+}
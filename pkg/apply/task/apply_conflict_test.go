@@ -0,0 +1,134 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/common"
+)
+
+func TestParseApplyConflicts(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected []event.ApplyConflict
+	}{
+		"nil": {
+			err:      nil,
+			expected: nil,
+		},
+		"not a conflict": {
+			err:      errors.New("field is immutable"),
+			expected: nil,
+		},
+		"status error with causes": {
+			err: apierrors.NewApplyConflict([]metav1.StatusCause{
+				{
+					Type:    metav1.CauseTypeFieldManagerConflict,
+					Message: `conflict with "kubectl-edit" using apps/v1`,
+					Field:   ".spec.replicas",
+				},
+			}, "Apply failed with 1 conflict"),
+			expected: []event.ApplyConflict{
+				{Field: ".spec.replicas", Manager: "kubectl-edit"},
+			},
+		},
+		"single conflict wrapped by kubectl": {
+			err: fmt.Errorf("%v\nPlease review the fields above--they currently have other managers.",
+				errors.New(`Apply failed with 1 conflict: conflict with "kubectl-edit" using apps/v1 at 2022-01-02T03:04:05Z: .spec.replicas`)),
+			expected: []event.ApplyConflict{
+				{Field: ".spec.replicas", Manager: "kubectl-edit"},
+			},
+		},
+		"multiple conflicts wrapped by kubectl": {
+			err: fmt.Errorf("%v\nPlease review the fields above--they currently have other managers.\n- not a field",
+				errors.New(`Apply failed with 3 conflicts: conflicts with "controller" using apps/v1:
+- .spec.replicas
+- .metadata.labels.app
+conflicts with "other" with subresource "scale":
+- .spec.paused`)),
+			expected: []event.ApplyConflict{
+				{Field: ".spec.replicas", Manager: "controller"},
+				{Field: ".metadata.labels.app", Manager: "controller"},
+				{Field: ".spec.paused", Manager: "other"},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			assert.Equal(t, tc.expected, parseApplyConflicts(tc.err))
+		})
+	}
+}
+
+func TestCanForceConflicts(t *testing.T) {
+	conflicts := []event.ApplyConflict{
+		{Field: ".spec.replicas", Manager: "controller"},
+		{Field: `.spec.template.spec.containers[name="app"].image`, Manager: "controller"},
+	}
+
+	testCases := map[string]struct {
+		annotation *string
+		conflicts  []event.ApplyConflict
+		expected   bool
+	}{
+		"no annotation": {
+			conflicts: conflicts,
+			expected:  false,
+		},
+		"empty annotation": {
+			annotation: stringPtr(""),
+			conflicts:  conflicts,
+			expected:   false,
+		},
+		"no conflicts": {
+			annotation: stringPtr(".spec"),
+			expected:   false,
+		},
+		"all fields listed": {
+			annotation: stringPtr(`.spec.replicas,.spec.template.spec.containers[name="app"].image`),
+			conflicts:  conflicts,
+			expected:   true,
+		},
+		"parent fields listed": {
+			annotation: stringPtr(" .spec.replicas , .spec.template.spec.containers "),
+			conflicts:  conflicts,
+			expected:   true,
+		},
+		"field prefix listed": {
+			annotation: stringPtr(".spec.replicas,.spec.temp"),
+			conflicts:  conflicts,
+			expected:   false,
+		},
+		"some fields listed": {
+			annotation: stringPtr(".spec.replicas"),
+			conflicts:  conflicts,
+			expected:   false,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if tc.annotation != nil {
+				obj.SetAnnotations(map[string]string{
+					common.ForceConflictsAnnotation: *tc.annotation,
+				})
+			}
+			assert.Equal(t, tc.expected, canForceConflicts(obj, tc.conflicts))
+		})
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
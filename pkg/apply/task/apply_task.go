@@ -8,10 +8,16 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
@@ -24,9 +30,11 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/info"
 	"sigs.k8s.io/cli-utils/pkg/apply/mutator"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/csaupgrade"
 )
 
 // applyOptions defines the two key functions on the ApplyOptions
@@ -56,8 +64,39 @@ type ApplyTask struct {
 	Mutators          []mutator.Interface
 	DryRunStrategy    common.DryRunStrategy
 	ServerSideOptions common.ServerSideOptions
+	// Concurrency is the maximum number of objects to apply in parallel.
+	// Values less than two apply the objects one at a time.
+	Concurrency int
+	// ReplaceOnImmutableChange defines whether objects whose apply fails
+	// because an immutable field was changed are deleted and recreated.
+	// Objects can also opt in with the on-immutable-change annotation.
+	ReplaceOnImmutableChange bool
+	// ReplaceFilters are checked against the object in the cluster before
+	// it is deleted to be replaced. If any filters the object, the object
+	// is not replaced and the apply fails.
+	ReplaceFilters []filter.ValidationFilter
+	// Retry defines how applies that fail with transient errors are
+	// retried. The zero value does not retry.
+	Retry retry.Policy
+	// MigrateClientSideApply defines whether the fields of objects that
+	// were applied client-side are moved to the field manager after they
+	// are applied server-side, and the objects are applied again, so that
+	// fields removed from the objects are removed from the cluster. Only
+	// used with server-side apply.
+	MigrateClientSideApply bool
+
+	// cancelFunc cancels the applies of the task.
+	cancelFunc context.CancelFunc
 }
 
+// replacePollInterval is how often to check whether an object deleted
+// to be replaced is gone.
+var replacePollInterval = time.Second
+
+// replaceTimeout is how long to wait for an object deleted to be
+// replaced to be gone.
+var replaceTimeout = 2 * time.Minute
+
 // applyOptionsFactoryFunc is a factory function for creating a new
 // applyOptions implementation. Used to allow unit testing.
 var applyOptionsFactoryFunc = newApplyOptions
@@ -82,104 +121,178 @@ func (a *ApplyTask) Identifiers() object.ObjMetadataSet {
 // after the Run function has completed. This information is then added
 // to the taskContext. The generation is increased every time
 // the desired state of a resource is changed.
+// If Concurrency is greater than one, objects are spread over a pool
+// of that many workers. Events for each object are still sent in order.
+// If the task is cancelled, the objects that have not been applied yet
+// are skipped.
 func (a *ApplyTask) Start(taskContext *taskrunner.TaskContext) {
+	var ctx context.Context
+	ctx, a.cancelFunc = context.WithCancel(context.Background())
 	go func() {
-		// TODO: pipe Context through TaskContext
-		ctx := context.TODO()
+		defer a.cancelFunc()
 		objects := a.Objects
-		klog.V(2).Infof("apply task starting (name: %q, objects: %d)",
-			a.Name(), len(objects))
-		for _, obj := range objects {
-			// Set the client and mapping fields on the provided
-			// info so they can be applied to the cluster.
-			info, err := a.InfoHelper.BuildInfo(obj)
-			// BuildInfo strips path annotations.
-			// Use modified object for filters, mutations, and events.
-			obj = info.Object.(*unstructured.Unstructured)
-			id := object.UnstructuredToObjMetadata(obj)
-			if err != nil {
-				if klog.V(4).Enabled() {
-					klog.Errorf("unable to convert obj to info for %s/%s (%s)--continue",
-						obj.GetNamespace(), obj.GetName(), err)
-				}
-				taskContext.SendEvent(a.createApplyFailedEvent(
-					id,
-					applyerror.NewUnknownTypeError(err),
-				))
-				taskContext.InventoryManager().AddFailedApply(id)
-				continue
-			}
-
-			// Check filters to see if we're prevented from applying.
-			var filtered bool
-			var filterErr error
-			for _, filter := range a.Filters {
-				klog.V(6).Infof("apply filter %s: %s", filter.Name(), id)
-				var reason string
-				filtered, reason, filterErr = filter.Filter(obj)
-				if filterErr != nil {
-					if klog.V(5).Enabled() {
-						klog.Errorf("error during %s, (%s): %s", filter.Name(), id, filterErr)
+		klog.V(2).Infof("apply task starting (name: %q, objects: %d, concurrency: %d)",
+			a.Name(), len(objects), a.concurrency())
+		objCh := make(chan *unstructured.Unstructured)
+		var wg sync.WaitGroup
+		for i := 0; i < a.concurrency(); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for obj := range objCh {
+					if ctx.Err() != nil {
+						a.skipObject(taskContext, obj, taskrunner.CancelledReason)
+						continue
 					}
-					taskContext.SendEvent(a.createApplyFailedEvent(id, filterErr))
-					taskContext.InventoryManager().AddFailedApply(id)
-					break
-				}
-				if filtered {
-					klog.V(4).Infof("apply filtered (filter: %q, resource: %q, reason: %q)", filter.Name(), id, reason)
-					taskContext.SendEvent(a.createApplyEvent(id, event.Unchanged, obj))
-					taskContext.InventoryManager().AddSkippedApply(id)
-					break
+					a.applyObject(ctx, taskContext, obj)
 				}
-			}
-			if filtered || filterErr != nil {
-				continue
-			}
+			}()
+		}
+		for _, obj := range objects {
+			objCh <- obj
+		}
+		close(objCh)
+		wg.Wait()
+		a.sendTaskResult(taskContext)
+	}()
+}
 
-			// Execute mutators, if any apply
-			err = a.mutate(ctx, obj)
-			if err != nil {
-				if klog.V(5).Enabled() {
-					klog.Errorf("error mutating: %w", err)
-				}
-				taskContext.SendEvent(a.createApplyFailedEvent(id, err))
-				taskContext.InventoryManager().AddFailedApply(id)
-				continue
-			}
+// concurrency returns the number of workers used to apply objects.
+func (a *ApplyTask) concurrency() int {
+	if a.Concurrency < 1 {
+		return 1
+	}
+	if a.Concurrency > len(a.Objects) && len(a.Objects) > 0 {
+		return len(a.Objects)
+	}
+	return a.Concurrency
+}
 
-			// Create a new instance of the applyOptions interface and use it
-			// to apply the objects.
-			ao := applyOptionsFactoryFunc(a.Name(), taskContext.EventChannel(),
-				a.ServerSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
-			ao.SetObjects([]*resource.Info{info})
-			klog.V(5).Infof("applying %s/%s...", info.Namespace, info.Name)
-			err = ao.Run()
-			if err != nil && a.ServerSideOptions.ServerSideApply && isAPIService(obj) && isStreamError(err) {
-				// Server-side Apply doesn't work with APIService before k8s 1.21
-				// https://github.com/kubernetes/kubernetes/issues/89264
-				// Thus APIService is handled specially using client-side apply.
-				err = a.clientSideApply(info, taskContext.EventChannel())
+// applyObject applies a single object to the cluster, sends the apply
+// events and records the result in the inventory manager.
+func (a *ApplyTask) applyObject(ctx context.Context, taskContext *taskrunner.TaskContext, obj *unstructured.Unstructured) {
+	// Set the client and mapping fields on the provided
+	// info so they can be applied to the cluster.
+	info, err := a.InfoHelper.BuildInfo(obj)
+	// BuildInfo strips path annotations.
+	// Use modified object for filters, mutations, and events.
+	obj = info.Object.(*unstructured.Unstructured)
+	id := object.UnstructuredToObjMetadata(obj)
+	if err != nil {
+		if klog.V(4).Enabled() {
+			klog.Errorf("unable to convert obj to info for %s/%s (%s)--continue",
+				obj.GetNamespace(), obj.GetName(), err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(
+			id,
+			applyerror.NewUnknownTypeError(err),
+		))
+		taskContext.InventoryManager().AddFailedApply(id)
+		return
+	}
+
+	// Check filters to see if we're prevented from applying.
+	for _, filter := range a.Filters {
+		klog.V(6).Infof("apply filter %s: %s", filter.Name(), id)
+		filtered, reason, filterErr := filter.Filter(obj)
+		if filterErr != nil {
+			if klog.V(5).Enabled() {
+				klog.Errorf("error during %s, (%s): %s", filter.Name(), id, filterErr)
 			}
-			if err != nil {
-				if klog.V(4).Enabled() {
-					klog.Errorf("error applying (%s/%s) %s", info.Namespace, info.Name, err)
-				}
-				taskContext.SendEvent(a.createApplyFailedEvent(
-					id,
-					applyerror.NewApplyRunError(err),
-				))
-				taskContext.InventoryManager().AddFailedApply(id)
-			} else if info.Object != nil {
-				acc, err := meta.Accessor(info.Object)
-				if err == nil {
-					uid := acc.GetUID()
-					gen := acc.GetGeneration()
-					taskContext.InventoryManager().AddSuccessfulApply(id, uid, gen)
-				}
+			taskContext.SendEvent(a.createApplyFailedEvent(id, filterErr))
+			taskContext.InventoryManager().AddFailedApply(id)
+			return
+		}
+		if filtered {
+			klog.V(4).Infof("apply filtered (filter: %q, resource: %q, reason: %q)", filter.Name(), id, reason)
+			a.skipObject(taskContext, obj, reason)
+			return
+		}
+	}
+
+	// Execute mutators, if any apply
+	err = a.mutate(ctx, obj)
+	if err != nil {
+		if klog.V(5).Enabled() {
+			klog.Errorf("error mutating: %w", err)
+		}
+		taskContext.SendEvent(a.createApplyFailedEvent(id, err))
+		taskContext.InventoryManager().AddFailedApply(id)
+		return
+	}
+
+	// Hold back the apply events if the object may be migrated, until it
+	// is known whether it is.
+	eventChannel := taskContext.EventChannel()
+	closeEventChannel := func() []event.Event { return nil }
+	if a.shouldMigrate() {
+		eventChannel, closeEventChannel = holdEventChannel()
+	}
+
+	// Create a new instance of the applyOptions interface for each
+	// attempt and use it to apply the objects.
+	err = a.retryPolicy(obj).Do(ctx, func() error {
+		// kubectl replaces the object of the info with the object from
+		// the cluster, so restore the local object before each attempt.
+		info.Object = obj
+		ao := applyOptionsFactoryFunc(a.Name(), eventChannel,
+			a.ServerSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
+		ao.SetObjects([]*resource.Info{info})
+		klog.V(5).Infof("applying %s/%s...", info.Namespace, info.Name)
+		return ao.Run()
+	}, retryEventFunc(taskContext, a.Name(), id, event.ApplyAction, a.Retry))
+	if err != nil && a.ServerSideOptions.ServerSideApply && isAPIService(obj) && retry.IsStreamError(err) {
+		// Server-side Apply doesn't work with APIService before k8s 1.21
+		// https://github.com/kubernetes/kubernetes/issues/89264
+		// Thus APIService is handled specially using client-side apply.
+		err = a.clientSideApply(info, eventChannel)
+	}
+	if err != nil && !a.ServerSideOptions.ForceConflicts {
+		if conflicts := parseApplyConflicts(err); canForceConflicts(obj, conflicts) {
+			klog.V(4).Infof("forcing conflicts allowed by annotation (object: %q, conflicts: %d)", id, len(conflicts))
+			err = a.forceApply(info, obj, eventChannel)
+		}
+	}
+	heldEvents := closeEventChannel()
+	// Migrate the fields applied client-side to the field manager, and
+	// send the apply events as Migrated instead.
+	if err == nil && a.shouldMigrate() {
+		migrated, err := a.migrateToServerSideApply(ctx, taskContext, info, obj)
+		if err != nil {
+			if klog.V(4).Enabled() {
+				klog.Errorf("error migrating (%s/%s) %s", info.Namespace, info.Name, err)
 			}
+			taskContext.SendEvent(a.createApplyFailedEvent(id, err))
+			taskContext.InventoryManager().AddFailedApply(id)
+			return
 		}
-		a.sendTaskResult(taskContext)
-	}()
+		if migrated {
+			heldEvents = nil
+		}
+	}
+	for _, e := range heldEvents {
+		taskContext.SendEvent(e)
+	}
+	if err != nil && isImmutableFieldError(err) && a.shouldReplace(obj) {
+		klog.V(4).Infof("replacing object with immutable field changes (object: %q)", id)
+		err = a.replace(ctx, taskContext, info, obj, err)
+	}
+	if err != nil {
+		if klog.V(4).Enabled() {
+			klog.Errorf("error applying (%s/%s) %s", info.Namespace, info.Name, err)
+		}
+		e := a.createApplyFailedEvent(id, applyerror.NewApplyRunError(err))
+		e.ApplyEvent.Conflicts = parseApplyConflicts(err)
+		taskContext.SendEvent(e)
+		taskContext.InventoryManager().AddFailedApply(id)
+	} else if info.Object != nil {
+		acc, err := meta.Accessor(info.Object)
+		if err == nil {
+			uid := acc.GetUID()
+			gen := acc.GetGeneration()
+			taskContext.InventoryManager().AddSuccessfulApply(id, uid, gen)
+		}
+	}
 }
 
 func newApplyOptions(taskName string, eventChannel chan<- event.Event, serverSideOptions common.ServerSideOptions,
@@ -223,8 +336,24 @@ func (a *ApplyTask) sendTaskResult(taskContext *taskrunner.TaskContext) {
 	taskContext.TaskChannel() <- taskrunner.TaskResult{}
 }
 
-// Cancel is not supported by the ApplyTask.
-func (a *ApplyTask) Cancel(_ *taskrunner.TaskContext) {}
+// Cancel stops applying objects. Applies in progress are allowed to
+// finish, and the remaining objects are skipped.
+func (a *ApplyTask) Cancel(_ *taskrunner.TaskContext) {
+	if a.cancelFunc != nil {
+		a.cancelFunc()
+	}
+}
+
+// skipObject sends a Skipped event for an object that is not applied,
+// and records it in the inventory manager.
+func (a *ApplyTask) skipObject(taskContext *taskrunner.TaskContext, obj *unstructured.Unstructured, reason string) {
+	id := object.UnstructuredToObjMetadata(obj)
+	klog.V(4).Infof("apply skipped (object: %q): %s", id, reason)
+	e := a.createApplyEvent(id, event.ApplySkipped, obj)
+	e.ApplyEvent.Reason = reason
+	taskContext.SendEvent(e)
+	taskContext.InventoryManager().AddSkippedApply(id)
+}
 
 // StatusUpdate is not supported by the ApplyTask.
 func (a *ApplyTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
@@ -274,10 +403,253 @@ func isAPIService(obj *unstructured.Unstructured) bool {
 	return gk.Group == "apiregistration.k8s.io" && gk.Kind == "APIService"
 }
 
-// isStreamError checks if the error is a StreamError. Since kubectl wraps the actual StreamError,
-// we can't check the error type.
-func isStreamError(err error) bool {
-	return strings.Contains(err.Error(), "stream error: stream ID ")
+// retryPolicy returns the retry policy for applying the object.
+// Server-side apply of an APIService fails with a stream error before
+// k8s 1.21, which is handled with client-side apply instead, so these
+// errors are not retried.
+func (a *ApplyTask) retryPolicy(obj *unstructured.Unstructured) retry.Policy {
+	policy := a.Retry
+	if !a.ServerSideOptions.ServerSideApply || !isAPIService(obj) {
+		return policy
+	}
+	retriable := policy.Retriable
+	if retriable == nil {
+		retriable = retry.IsRetriable
+	}
+	policy.Retriable = func(err error) bool {
+		return !retry.IsStreamError(err) && retriable(err)
+	}
+	return policy
+}
+
+// immutableFieldErrorMessages are parts of the error messages returned
+// by the server when an update changes a field that cannot be changed.
+var immutableFieldErrorMessages = []string{
+	"field is immutable",
+	"may not change once set",
+	"updates to statefulset spec for fields other than",
+}
+
+// isImmutableFieldError checks if the error is caused by changing an
+// immutable field. Since kubectl wraps the actual StatusError, the
+// error message is checked, instead of the error type.
+func isImmutableFieldError(err error) bool {
+	msg := err.Error()
+	for _, immutableMsg := range immutableFieldErrorMessages {
+		if strings.Contains(msg, immutableMsg) {
+			return true
+		}
+	}
+	return false
+}
+
+// shouldReplace returns true if the object should be deleted and
+// recreated when an immutable field was changed.
+func (a *ApplyTask) shouldReplace(obj *unstructured.Unstructured) bool {
+	if a.DryRunStrategy.ClientOrServerDryRun() {
+		return false
+	}
+	if a.ReplaceOnImmutableChange {
+		return true
+	}
+	return obj.GetAnnotations()[common.OnImmutableChangeAnnotation] == common.OnImmutableChangeReplace
+}
+
+// replace deletes the object from the cluster, waits for it to be gone
+// and applies the object again. The object is only deleted if none of
+// the ReplaceFilters filter the object in the cluster. The apply event
+// is sent with the Replaced operation. Returns the passed apply error,
+// if the object could not be replaced.
+func (a *ApplyTask) replace(ctx context.Context, taskContext *taskrunner.TaskContext,
+	info *resource.Info, obj *unstructured.Unstructured, applyErr error) error {
+	id := object.UnstructuredToObjMetadata(obj)
+	mapping, err := a.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return fmt.Errorf("failed to get object to replace: %w", err)
+	}
+	client := a.DynamicClient.Resource(mapping.Resource).Namespace(id.Namespace)
+	clusterObj, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get object to replace: %w", err)
+	}
+	for _, filter := range a.ReplaceFilters {
+		klog.V(6).Infof("replace filter %s: %s", filter.Name(), id)
+		filtered, reason, err := filter.Filter(clusterObj)
+		if err != nil {
+			return fmt.Errorf("failed to replace object: %w", err)
+		}
+		if filtered {
+			klog.V(4).Infof("replace filtered (filter: %q, resource: %q, reason: %q)", filter.Name(), id, reason)
+			return fmt.Errorf("replace prevented: %s: %w", reason, applyErr)
+		}
+	}
+
+	uid := clusterObj.GetUID()
+	propagation := metav1.DeletePropagationBackground
+	err = client.Delete(ctx, id.Name, metav1.DeleteOptions{
+		Preconditions:     &metav1.Preconditions{UID: &uid},
+		PropagationPolicy: &propagation,
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete object to replace: %w", err)
+	}
+	// The object is deleted, so the wait and the apply are not cancelled
+	// with the task, to not leave the object deleted.
+	replaceCtx, cancel := context.WithTimeout(context.Background(), replaceTimeout)
+	defer cancel()
+	klog.V(4).Infof("waiting for object to be deleted (object: %q)", id)
+	err = wait.PollImmediateUntil(replacePollInterval, func() (bool, error) {
+		obj, err := client.Get(replaceCtx, id.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return obj.GetUID() != uid, nil
+	}, replaceCtx.Done())
+	if err != nil {
+		return fmt.Errorf("failed waiting for object to be deleted: %w", err)
+	}
+
+	// Apply the object again, and send the apply events as Replaced.
+	// Client-side apply replaces the info object with the object in the
+	// cluster, so it is reset to the local object.
+	info.Object = obj
+	info.ResourceVersion = ""
+	eventChannel, closeEventChannel := operationEventChannel(taskContext, event.Replaced)
+	ao := applyOptionsFactoryFunc(a.Name(), eventChannel,
+		a.ServerSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
+	ao.SetObjects([]*resource.Info{info})
+	err = ao.Run()
+	closeEventChannel()
+	return err
+}
+
+// operationEventChannel returns a channel that forwards the events sent to
+// it to the task context, with the operation of the successful apply
+// events set to the passed operation. The returned function closes the
+// channel and waits for the events to be forwarded.
+func operationEventChannel(taskContext *taskrunner.TaskContext,
+	operation event.ApplyEventOperation) (chan event.Event, func()) {
+	eventChannel := make(chan event.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range eventChannel {
+			if e.Type == event.ApplyType && e.ApplyEvent.Error == nil {
+				e.ApplyEvent.Operation = operation
+			}
+			taskContext.SendEvent(e)
+		}
+	}()
+	return eventChannel, func() {
+		close(eventChannel)
+		<-done
+	}
+}
+
+// holdEventChannel returns a channel that collects the events sent to it.
+// The returned function closes the channel and returns the events.
+func holdEventChannel() (chan event.Event, func() []event.Event) {
+	eventChannel := make(chan event.Event)
+	var events []event.Event
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for e := range eventChannel {
+			events = append(events, e)
+		}
+	}()
+	return eventChannel, func() []event.Event {
+		close(eventChannel)
+		<-done
+		return events
+	}
+}
+
+// shouldMigrate returns true if the fields of objects applied client-side
+// should be moved to the field manager after the objects are applied.
+func (a *ApplyTask) shouldMigrate() bool {
+	return a.MigrateClientSideApply && a.ServerSideOptions.ServerSideApply
+}
+
+// migrateToServerSideApply moves the ownership of the fields of the applied
+// object that were applied client-side to the field manager, removes the
+// last-applied-configuration annotation, and applies the object again, so
+// that the fields removed from the object since it was applied client-side
+// are deleted. The fields owned by kubectl client-side apply, and by
+// updates of the field manager itself, are moved. Only the object returned
+// by the apply is checked, so objects without such fields are not read
+// again. Returns true if the object was migrated, in which case the events
+// of the second apply are sent as Migrated. With client-side dry-run, the
+// object is read from the cluster instead, and is not changed.
+func (a *ApplyTask) migrateToServerSideApply(ctx context.Context, taskContext *taskrunner.TaskContext,
+	info *resource.Info, obj *unstructured.Unstructured) (bool, error) {
+	id := object.UnstructuredToObjMetadata(obj)
+	mapping, err := a.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return false, fmt.Errorf("failed to migrate object: %w", err)
+	}
+	client := a.DynamicClient.Resource(mapping.Resource).Namespace(id.Namespace)
+	applied, ok := info.Object.(*unstructured.Unstructured)
+	if !ok || a.DryRunStrategy.ClientDryRun() {
+		// The apply did not return the object in the cluster.
+		applied, err = client.Get(ctx, id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to get object to migrate: %w", err)
+		}
+	}
+	fieldManager := a.ServerSideOptions.FieldManager
+	if fieldManager == "" {
+		fieldManager = common.DefaultFieldManager
+	}
+	csaManagers := sets.NewString(csaupgrade.ClientSideApplyManager, fieldManager)
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(applied, csaManagers, fieldManager)
+	if err != nil {
+		return false, fmt.Errorf("failed to migrate object: %w", err)
+	}
+	if patch == nil {
+		return false, nil
+	}
+	klog.V(4).Infof("migrating object to server-side apply (object: %q, field manager: %q)", id, fieldManager)
+	if !a.DryRunStrategy.ClientDryRun() {
+		opts := metav1.PatchOptions{}
+		if a.DryRunStrategy.ServerDryRun() {
+			opts.DryRun = []string{metav1.DryRunAll}
+		}
+		_, err = client.Patch(ctx, id.Name, types.JSONPatchType, patch, opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to migrate object: %w", err)
+		}
+	}
+
+	info.Object = obj
+	eventChannel, closeEventChannel := operationEventChannel(taskContext, event.Migrated)
+	ao := applyOptionsFactoryFunc(a.Name(), eventChannel,
+		a.ServerSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
+	ao.SetObjects([]*resource.Info{info})
+	err = ao.Run()
+	closeEventChannel()
+	if err != nil {
+		return false, fmt.Errorf("failed to apply migrated object: %w", err)
+	}
+	return true, nil
+}
+
+// forceApply applies the object server-side again, taking the ownership
+// of the conflicting fields from the other field managers.
+func (a *ApplyTask) forceApply(info *resource.Info, obj *unstructured.Unstructured, eventChannel chan<- event.Event) error {
+	info.Object = obj
+	serverSideOptions := a.ServerSideOptions
+	serverSideOptions.ForceConflicts = true
+	ao := applyOptionsFactoryFunc(a.Name(), eventChannel,
+		serverSideOptions, a.DryRunStrategy, a.DynamicClient, a.OpenAPIGetter)
+	ao.SetObjects([]*resource.Info{info})
+	return ao.Run()
 }
 
 func (a *ApplyTask) clientSideApply(info *resource.Info, eventChannel chan<- event.Event) error {
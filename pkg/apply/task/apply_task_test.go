@@ -4,22 +4,37 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/filter"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
 	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
@@ -513,6 +528,992 @@ func TestApplyTaskWithError(t *testing.T) {
 	}
 }
 
+func TestApplyTask_Concurrency(t *testing.T) {
+	testCases := map[string]struct {
+		objectCount  int
+		failureCount int
+		concurrency  int
+	}{
+		"sequential": {
+			objectCount:  10,
+			failureCount: 2,
+			concurrency:  0,
+		},
+		"fewer workers than objects": {
+			objectCount:  50,
+			failureCount: 5,
+			concurrency:  4,
+		},
+		"more workers than objects": {
+			objectCount:  3,
+			failureCount: 1,
+			concurrency:  10,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			eventChannel := make(chan event.Event)
+			resourceCache := cache.NewResourceCacheMap()
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			var rss []resourceInfo
+			var expectedFailed object.ObjMetadataSet
+			for i := 0; i < tc.objectCount; i++ {
+				name := fmt.Sprintf("cm-%d", i)
+				if i < tc.failureCount {
+					name = fmt.Sprintf("failure-%d", i)
+				}
+				rss = append(rss, resourceInfo{
+					apiVersion: "v1",
+					kind:       "ConfigMap",
+					name:       name,
+					namespace:  "default",
+					uid:        types.UID(fmt.Sprintf("uid-%d", i)),
+				})
+				if i < tc.failureCount {
+					expectedFailed = append(expectedFailed, object.ObjMetadata{
+						GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+						Name:      name,
+						Namespace: "default",
+					})
+				}
+			}
+			objs := toUnstructureds(rss)
+			applyIds := object.UnstructuredSetToObjMetadataSet(objs)
+
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+				dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+				return &fakeApplyOptions{}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			applyTask := &ApplyTask{
+				Objects:     objs,
+				InfoHelper:  &fakeInfoHelper{},
+				Concurrency: tc.concurrency,
+			}
+
+			var events []event.Event
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for msg := range eventChannel {
+					events = append(events, msg)
+				}
+			}()
+
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+			wg.Wait()
+
+			// Only failures send events with the fake apply options.
+			var failedIds object.ObjMetadataSet
+			for _, e := range events {
+				assert.Equal(t, event.ApplyType, e.Type)
+				assert.Error(t, e.ApplyEvent.Error)
+				failedIds = append(failedIds, e.ApplyEvent.Identifier)
+			}
+			assert.Truef(t, expectedFailed.Equal(failedIds),
+				"expected failure events for (%s), got (%s)", expectedFailed, failedIds)
+
+			im := taskContext.InventoryManager()
+			actualFailed := im.FailedApplies()
+			assert.Truef(t, expectedFailed.Equal(actualFailed),
+				"expected (%s) failed applies, got (%s)", expectedFailed, actualFailed)
+			expectedApplied := applyIds.Diff(expectedFailed)
+			actualApplied := im.SuccessfulApplies()
+			assert.Truef(t, expectedApplied.Equal(actualApplied),
+				"expected (%s) successful applies, got (%s)", expectedApplied, actualApplied)
+		})
+	}
+}
+
+func TestApplyTask_Cancel(t *testing.T) {
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+	var rss []resourceInfo
+	for i := 0; i < 3; i++ {
+		rss = append(rss, resourceInfo{
+			apiVersion: "v1",
+			kind:       "ConfigMap",
+			name:       fmt.Sprintf("cm-%d", i),
+			namespace:  "default",
+			uid:        types.UID(fmt.Sprintf("uid-%d", i)),
+		})
+	}
+	objs := toUnstructureds(rss)
+	applyIds := object.UnstructuredSetToObjMetadataSet(objs)
+
+	applyTask := &ApplyTask{
+		TaskName:   "apply-0",
+		Objects:    objs,
+		InfoHelper: &fakeInfoHelper{},
+	}
+
+	// Cancel the task while the first object is applied.
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+		dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+		return &fakeApplyOptions{
+			onRun: func() { applyTask.Cancel(taskContext) },
+		}
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	var events []event.Event
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range eventChannel {
+			events = append(events, msg)
+		}
+	}()
+
+	applyTask.Start(taskContext)
+	result := <-taskContext.TaskChannel()
+	close(eventChannel)
+	wg.Wait()
+	assert.NoError(t, result.Err)
+
+	// The apply in progress finishes, and the remaining objects are skipped.
+	expectedSkipped := applyIds[1:]
+	var skippedIds object.ObjMetadataSet
+	for _, e := range events {
+		assert.Equal(t, event.ApplyType, e.Type)
+		assert.Equal(t, event.ApplySkipped, e.ApplyEvent.Operation)
+		assert.Equal(t, taskrunner.CancelledReason, e.ApplyEvent.Reason)
+		skippedIds = append(skippedIds, e.ApplyEvent.Identifier)
+	}
+	assert.Equal(t, expectedSkipped, skippedIds)
+
+	im := taskContext.InventoryManager()
+	assert.Equal(t, applyIds[:1], im.SuccessfulApplies())
+	assert.Truef(t, expectedSkipped.Equal(im.SkippedApplies()),
+		"expected (%s) skipped applies, got (%s)", expectedSkipped, im.SkippedApplies())
+}
+
+// skipFilter filters every object with the same reason.
+type skipFilter struct {
+	reason string
+}
+
+func (sf skipFilter) Name() string {
+	return "SkipFilter"
+}
+
+func (sf skipFilter) Filter(*unstructured.Unstructured) (bool, string, error) {
+	return true, sf.reason, nil
+}
+
+func TestApplyTask_Filtered(t *testing.T) {
+	eventChannel := make(chan event.Event, 10)
+	taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+
+	objs := toUnstructureds([]resourceInfo{
+		{
+			apiVersion: "v1",
+			kind:       "ConfigMap",
+			name:       "cm-0",
+			namespace:  "default",
+			uid:        types.UID("uid-0"),
+		},
+	})
+	applyIds := object.UnstructuredSetToObjMetadataSet(objs)
+
+	applyTask := &ApplyTask{
+		TaskName:   "apply-0",
+		Objects:    objs,
+		InfoHelper: &fakeInfoHelper{},
+		Filters:    []filter.ValidationFilter{skipFilter{reason: "skipped for testing"}},
+	}
+	applyTask.Start(taskContext)
+	result := <-taskContext.TaskChannel()
+	close(eventChannel)
+	assert.NoError(t, result.Err)
+
+	// The filtered object is skipped with the reason of the filter.
+	var events []event.Event
+	for e := range eventChannel {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.Equal(t, event.ApplyType, events[0].Type)
+	assert.Equal(t, event.ApplySkipped, events[0].ApplyEvent.Operation)
+	assert.Equal(t, "skipped for testing", events[0].ApplyEvent.Reason)
+	assert.Equal(t, applyIds, taskContext.InventoryManager().SkippedApplies())
+}
+
+func TestApplyTask_Replace(t *testing.T) {
+	localJob := func(annotations map[string]string) *unstructured.Unstructured {
+		u := toUnstructured(map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      "job",
+				"namespace": namespace,
+			},
+		})
+		u.SetAnnotations(annotations)
+		return u
+	}
+	clusterJob := func(annotations map[string]string) *unstructured.Unstructured {
+		u := localJob(annotations)
+		u.SetUID("old-uid")
+		return u
+	}
+	owned := map[string]string{
+		inventory.OwningInventoryKey: localInv.ID(),
+	}
+	replaceAnnotation := map[string]string{
+		inventory.OwningInventoryKey:       localInv.ID(),
+		common.OnImmutableChangeAnnotation: common.OnImmutableChangeReplace,
+	}
+	id := object.UnstructuredToObjMetadata(localJob(nil))
+
+	testCases := map[string]struct {
+		obj            *unstructured.Unstructured
+		clusterObj     *unstructured.Unstructured
+		replaceAll     bool
+		invPolicy      inventory.Policy
+		dryRun         common.DryRunStrategy
+		expectedOp     event.ApplyEventOperation
+		expectedErr    string
+		expectReplaced bool
+	}{
+		"not opted in; apply fails": {
+			obj:         localJob(owned),
+			clusterObj:  clusterJob(owned),
+			expectedErr: "field is immutable",
+		},
+		"opted in with annotation; replaced": {
+			obj:            localJob(replaceAnnotation),
+			clusterObj:     clusterJob(owned),
+			expectedOp:     event.Replaced,
+			expectReplaced: true,
+		},
+		"opted in with option; replaced": {
+			obj:            localJob(owned),
+			clusterObj:     clusterJob(owned),
+			replaceAll:     true,
+			expectedOp:     event.Replaced,
+			expectReplaced: true,
+		},
+		"prevent deletion annotation; apply fails": {
+			obj: localJob(owned),
+			clusterObj: clusterJob(map[string]string{
+				inventory.OwningInventoryKey:     localInv.ID(),
+				common.LifecycleDeleteAnnotation: common.PreventDeletion,
+			}),
+			replaceAll:  true,
+			expectedErr: "replace prevented",
+		},
+		"owned by another inventory; apply fails": {
+			obj: localJob(owned),
+			clusterObj: clusterJob(map[string]string{
+				inventory.OwningInventoryKey: "other-inventory",
+			}),
+			replaceAll:  true,
+			invPolicy:   inventory.PolicyAdoptIfNoInventory,
+			expectedErr: "replace prevented",
+		},
+		"dry-run; apply fails": {
+			obj:         localJob(owned),
+			clusterObj:  clusterJob(owned),
+			replaceAll:  true,
+			dryRun:      common.DryRunClient,
+			expectedErr: "field is immutable",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			oldInterval := replacePollInterval
+			replacePollInterval = time.Millisecond
+			defer func() { replacePollInterval = oldInterval }()
+
+			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+				Group:   "batch",
+				Version: "v1",
+				Kind:    "Job",
+			})
+			dynamicClient := fake.NewSimpleDynamicClient(scheme.Scheme, tc.clusterObj)
+
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ string, ch chan<- event.Event, _ common.ServerSideOptions,
+				_ common.DryRunStrategy, _ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+				return &immutableApplyOptions{
+					ch:     ch,
+					client: dynamicClient,
+					mapper: restMapper,
+				}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			eventChannel := make(chan event.Event, 10)
+			taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+
+			applyTask := &ApplyTask{
+				TaskName:                 taskName,
+				Objects:                  object.UnstructuredSet{tc.obj},
+				InfoHelper:               &fakeInfoHelper{},
+				Mapper:                   restMapper,
+				DynamicClient:            dynamicClient,
+				DryRunStrategy:           tc.dryRun,
+				ReplaceOnImmutableChange: tc.replaceAll,
+				ReplaceFilters: []filter.ValidationFilter{
+					filter.PreventRemoveFilter{},
+					filter.InventoryPolicyFilter{
+						Inv:       localInv,
+						InvPolicy: tc.invPolicy,
+					},
+				},
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			if !assert.Len(t, events, 1) {
+				return
+			}
+			e := events[0]
+			assert.Equal(t, event.ApplyType, e.Type)
+			assert.Equal(t, id, e.ApplyEvent.Identifier)
+			im := taskContext.InventoryManager()
+			if tc.expectedErr != "" {
+				assert.Error(t, e.ApplyEvent.Error)
+				assert.Contains(t, e.ApplyEvent.Error.Error(), tc.expectedErr)
+				assert.True(t, im.IsFailedApply(id))
+			} else {
+				assert.NoError(t, e.ApplyEvent.Error)
+				assert.Equal(t, tc.expectedOp, e.ApplyEvent.Operation)
+				assert.False(t, im.IsFailedApply(id))
+			}
+
+			clusterObj, err := getObject(dynamicClient, restMapper, id)
+			assert.NoError(t, err)
+			if tc.expectReplaced {
+				assert.Equal(t, types.UID("new-uid"), clusterObj.GetUID())
+			} else {
+				assert.Equal(t, types.UID("old-uid"), clusterObj.GetUID())
+			}
+		})
+	}
+}
+
+func TestApplyTask_ReplaceCancelled(t *testing.T) {
+	oldInterval := replacePollInterval
+	replacePollInterval = time.Millisecond
+	defer func() { replacePollInterval = oldInterval }()
+
+	obj := toUnstructured(map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      "job",
+			"namespace": namespace,
+			"annotations": map[string]interface{}{
+				inventory.OwningInventoryKey: localInv.ID(),
+			},
+		},
+	})
+	clusterObj := obj.DeepCopy()
+	clusterObj.SetUID("old-uid")
+	id := object.UnstructuredToObjMetadata(obj)
+
+	restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+		Group:   "batch",
+		Version: "v1",
+		Kind:    "Job",
+	})
+	fakeClient := fake.NewSimpleDynamicClient(scheme.Scheme, clusterObj)
+
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(_ string, ch chan<- event.Event, _ common.ServerSideOptions,
+		_ common.DryRunStrategy, _ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+		return &immutableApplyOptions{
+			ch:     ch,
+			client: fakeClient,
+			mapper: restMapper,
+		}
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	eventChannel := make(chan event.Event, 10)
+	taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+
+	applyTask := &ApplyTask{
+		TaskName:                 taskName,
+		Objects:                  object.UnstructuredSet{obj},
+		InfoHelper:               &fakeInfoHelper{},
+		Mapper:                   restMapper,
+		DynamicClient:            ctxDynamicClient{fakeClient},
+		ReplaceOnImmutableChange: true,
+	}
+	// Cancel the task once the object is deleted, before it is re-created.
+	fakeClient.PrependReactor("delete", "jobs", func(clienttesting.Action) (bool, runtime.Object, error) {
+		applyTask.Cancel(taskContext)
+		return false, nil, nil
+	})
+	applyTask.Start(taskContext)
+	<-taskContext.TaskChannel()
+	close(eventChannel)
+
+	var events []event.Event
+	for e := range eventChannel {
+		events = append(events, e)
+	}
+	require.Len(t, events, 1)
+	assert.NoError(t, events[0].ApplyEvent.Error)
+	assert.Equal(t, event.Replaced, events[0].ApplyEvent.Operation)
+
+	replaced, err := getObject(fakeClient, restMapper, id)
+	require.NoError(t, err)
+	assert.Equal(t, types.UID("new-uid"), replaced.GetUID())
+}
+
+// ctxDynamicClient fails the Get requests whose context is done, as the
+// real client does, but the fake client doesn't.
+type ctxDynamicClient struct {
+	dynamic.Interface
+}
+
+func (c ctxDynamicClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return ctxNamespaceableResource{c.Interface.Resource(resource)}
+}
+
+type ctxNamespaceableResource struct {
+	dynamic.NamespaceableResourceInterface
+}
+
+func (r ctxNamespaceableResource) Namespace(ns string) dynamic.ResourceInterface {
+	return ctxResource{r.NamespaceableResourceInterface.Namespace(ns)}
+}
+
+type ctxResource struct {
+	dynamic.ResourceInterface
+}
+
+func (r ctxResource) Get(ctx context.Context, name string, options metav1.GetOptions,
+	subresources ...string) (*unstructured.Unstructured, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.ResourceInterface.Get(ctx, name, options, subresources...)
+}
+
+func TestApplyTask_MigrateClientSideApply(t *testing.T) {
+	const csaFields = `{"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}}},"f:spec":{"f:replicas":{}}}`
+	localDeployment := func() *unstructured.Unstructured {
+		return toUnstructured(map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "dep",
+				"namespace": namespace,
+			},
+		})
+	}
+	clusterDeployment := func(manager string, op metav1.ManagedFieldsOperationType, lastApplied bool) *unstructured.Unstructured {
+		u := localDeployment()
+		u.SetResourceVersion("1")
+		if lastApplied {
+			u.SetAnnotations(map[string]string{
+				v1.LastAppliedConfigAnnotation: `{"spec":{"replicas":1}}`,
+			})
+		}
+		u.SetManagedFields([]metav1.ManagedFieldsEntry{
+			{
+				Manager:    manager,
+				Operation:  op,
+				APIVersion: "apps/v1",
+				FieldsType: "FieldsV1",
+				FieldsV1:   &metav1.FieldsV1{Raw: []byte(csaFields)},
+			},
+		})
+		return u
+	}
+	id := object.UnstructuredToObjMetadata(localDeployment())
+
+	testCases := map[string]struct {
+		clusterObj      *unstructured.Unstructured
+		migrate         bool
+		serverSide      bool
+		dryRun          common.DryRunStrategy
+		expectedOp      event.ApplyEventOperation
+		expectMigrated  bool
+		expectedManager string
+		expectedApplies int
+		expectedGets    int
+	}{
+		"not opted in; not migrated": {
+			clusterObj:      clusterDeployment("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, true),
+			serverSide:      true,
+			expectedOp:      event.Configured,
+			expectedManager: "kubectl-client-side-apply",
+			expectedApplies: 1,
+		},
+		"client-side apply; not migrated": {
+			clusterObj:      clusterDeployment("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, true),
+			migrate:         true,
+			expectedOp:      event.Configured,
+			expectedManager: "kubectl-client-side-apply",
+			expectedApplies: 1,
+		},
+		"applied client-side; migrated": {
+			clusterObj:      clusterDeployment("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, true),
+			migrate:         true,
+			serverSide:      true,
+			expectedOp:      event.Migrated,
+			expectMigrated:  true,
+			expectedManager: "test-manager",
+			expectedApplies: 2,
+		},
+		"applied client-side with the field manager; migrated": {
+			clusterObj:      clusterDeployment("test-manager", metav1.ManagedFieldsOperationUpdate, true),
+			migrate:         true,
+			serverSide:      true,
+			expectedOp:      event.Migrated,
+			expectMigrated:  true,
+			expectedManager: "test-manager",
+			expectedApplies: 2,
+		},
+		"applied server-side; not migrated": {
+			clusterObj:      clusterDeployment("test-manager", metav1.ManagedFieldsOperationApply, false),
+			migrate:         true,
+			serverSide:      true,
+			expectedOp:      event.Configured,
+			expectedManager: "test-manager",
+			expectedApplies: 1,
+		},
+		"dry-run; migration reported, not persisted": {
+			clusterObj:      clusterDeployment("kubectl-client-side-apply", metav1.ManagedFieldsOperationUpdate, true),
+			migrate:         true,
+			serverSide:      true,
+			dryRun:          common.DryRunClient,
+			expectedOp:      event.Migrated,
+			expectedManager: "kubectl-client-side-apply",
+			expectedApplies: 2,
+			expectedGets:    1,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			restMapper := testutil.NewFakeRESTMapper(schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "Deployment",
+			})
+			dynamicClient := fake.NewSimpleDynamicClient(scheme.Scheme, tc.clusterObj)
+
+			// The apply returns the object in the cluster, unless it is a
+			// client-side dry-run.
+			applies := 0
+			refreshing := false
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ string, ch chan<- event.Event, _ common.ServerSideOptions,
+				_ common.DryRunStrategy, _ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+				return &eventApplyOptions{ch: ch, operation: event.Configured, refresh: func(info *resource.Info) {
+					applies++
+					if tc.dryRun.ClientDryRun() {
+						return
+					}
+					refreshing = true
+					defer func() { refreshing = false }()
+					clusterObj, err := getObject(dynamicClient, restMapper, id)
+					require.NoError(t, err)
+					info.Object = clusterObj
+				}}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+			// Count the reads of the task, without those of the fake apply.
+			gets := 0
+			dynamicClient.PrependReactor("get", "*", func(clienttesting.Action) (bool, runtime.Object, error) {
+				if !refreshing {
+					gets++
+				}
+				return false, nil, nil
+			})
+
+			eventChannel := make(chan event.Event, 10)
+			taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+
+			applyTask := &ApplyTask{
+				TaskName:       taskName,
+				Objects:        object.UnstructuredSet{localDeployment()},
+				InfoHelper:     &fakeInfoHelper{},
+				Mapper:         restMapper,
+				DynamicClient:  dynamicClient,
+				DryRunStrategy: tc.dryRun,
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: tc.serverSide,
+					FieldManager:    "test-manager",
+				},
+				MigrateClientSideApply: tc.migrate,
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			assert.Equal(t, tc.expectedApplies, applies)
+			assert.Equal(t, tc.expectedGets, gets)
+			if !assert.Len(t, events, 1) {
+				return
+			}
+			e := events[0]
+			assert.Equal(t, event.ApplyType, e.Type)
+			assert.Equal(t, id, e.ApplyEvent.Identifier)
+			assert.NoError(t, e.ApplyEvent.Error)
+			assert.Equal(t, tc.expectedOp, e.ApplyEvent.Operation)
+			assert.True(t, taskContext.InventoryManager().IsSuccessfulApply(id))
+
+			clusterObj, err := getObject(dynamicClient, restMapper, id)
+			require.NoError(t, err)
+			entries := clusterObj.GetManagedFields()
+			require.Len(t, entries, 1)
+			assert.Equal(t, tc.expectedManager, entries[0].Manager)
+			_, lastApplied := clusterObj.GetAnnotations()[v1.LastAppliedConfigAnnotation]
+			if tc.expectMigrated {
+				assert.Equal(t, metav1.ManagedFieldsOperationApply, entries[0].Operation)
+				assert.False(t, lastApplied)
+			} else {
+				assert.Equal(t, tc.clusterObj.GetManagedFields()[0].Operation, entries[0].Operation)
+				assert.Equal(t, tc.clusterObj.GetAnnotations() != nil, lastApplied)
+			}
+		})
+	}
+}
+
+func TestApplyTask_ForceConflicts(t *testing.T) {
+	conflictErr := errors.New(`Apply failed with 2 conflicts: conflicts with "controller" using apps/v1:
+- .spec.replicas
+- .spec.template.spec.containers[name="app"].image
+Please review the fields above--they currently have other managers.`)
+	deployment := func(annotations map[string]string) *unstructured.Unstructured {
+		u := toUnstructureds([]resourceInfo{
+			{
+				group:      "apps",
+				apiVersion: "apps/v1",
+				kind:       "Deployment",
+				name:       "foo",
+				namespace:  "default",
+			},
+		})[0]
+		u.SetAnnotations(annotations)
+		return u
+	}
+	id := object.UnstructuredToObjMetadata(deployment(nil))
+	conflicts := []event.ApplyConflict{
+		{Field: ".spec.replicas", Manager: "controller"},
+		{Field: `.spec.template.spec.containers[name="app"].image`, Manager: "controller"},
+	}
+
+	testCases := map[string]struct {
+		obj               *unstructured.Unstructured
+		forceConflicts    bool
+		expectedConflicts []event.ApplyConflict
+		expectForced      bool
+	}{
+		"no annotation; apply fails with conflicts": {
+			obj:               deployment(nil),
+			expectedConflicts: conflicts,
+		},
+		"all fields listed; forced": {
+			obj: deployment(map[string]string{
+				common.ForceConflictsAnnotation: ".spec.replicas, .spec.template",
+			}),
+			expectForced: true,
+		},
+		"some fields listed; apply fails with conflicts": {
+			obj: deployment(map[string]string{
+				common.ForceConflictsAnnotation: ".spec.replicas,.spec.temp",
+			}),
+			expectedConflicts: conflicts,
+		},
+		"forced by option; no annotation needed": {
+			obj:            deployment(nil),
+			forceConflicts: true,
+			expectForced:   true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(_ string, ch chan<- event.Event, serverSideOptions common.ServerSideOptions,
+				_ common.DryRunStrategy, _ dynamic.Interface, _ discovery.OpenAPISchemaInterface) applyOptions {
+				if !serverSideOptions.ForceConflicts {
+					return &fakeApplyOptions{err: conflictErr}
+				}
+				return &eventApplyOptions{ch: ch, operation: event.ServersideApplied}
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			eventChannel := make(chan event.Event, 10)
+			taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+
+			applyTask := &ApplyTask{
+				TaskName:   taskName,
+				Objects:    object.UnstructuredSet{tc.obj},
+				InfoHelper: &fakeInfoHelper{},
+				ServerSideOptions: common.ServerSideOptions{
+					ServerSideApply: true,
+					ForceConflicts:  tc.forceConflicts,
+				},
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			if !assert.Len(t, events, 1) {
+				return
+			}
+			e := events[0]
+			assert.Equal(t, event.ApplyType, e.Type)
+			assert.Equal(t, id, e.ApplyEvent.Identifier)
+			assert.Equal(t, tc.expectedConflicts, e.ApplyEvent.Conflicts)
+			if tc.expectForced {
+				assert.NoError(t, e.ApplyEvent.Error)
+				assert.Equal(t, event.ServersideApplied, e.ApplyEvent.Operation)
+				assert.False(t, taskContext.InventoryManager().IsFailedApply(id))
+			} else {
+				assert.Error(t, e.ApplyEvent.Error)
+				assert.True(t, taskContext.InventoryManager().IsFailedApply(id))
+			}
+		})
+	}
+}
+
+func TestApplyTask_Retry(t *testing.T) {
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"},
+		"foo", errors.New("the object has been modified"))
+	obj := toUnstructureds([]resourceInfo{
+		{
+			group:      "apps",
+			apiVersion: "apps/v1",
+			kind:       "Deployment",
+			name:       "foo",
+			namespace:  "default",
+			uid:        types.UID("foo"),
+			generation: 1,
+		},
+	})[0]
+	id := object.UnstructuredToObjMetadata(obj)
+
+	testCases := map[string]struct {
+		policy         retry.Policy
+		errs           []error
+		expectedEvents []testutil.ExpEvent
+	}{
+		"no retry policy; apply fails": {
+			errs: []error{conflict},
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.ApplyType,
+					ApplyEvent: &testutil.ExpApplyEvent{
+						GroupName:  taskName,
+						Identifier: id,
+						Error:      testutil.EqualErrorString(conflict.Error()),
+					},
+				},
+			},
+		},
+		"retried; apply succeeds": {
+			policy: retry.Policy{Attempts: 3, Backoff: time.Millisecond},
+			errs:   []error{conflict, conflict, nil},
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						GroupName:  taskName,
+						Action:     event.ApplyAction,
+						Identifier: id,
+						Attempt:    1,
+						Error:      conflict,
+					},
+				},
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						GroupName:  taskName,
+						Action:     event.ApplyAction,
+						Identifier: id,
+						Attempt:    2,
+						Error:      conflict,
+					},
+				},
+			},
+		},
+		"attempts exhausted; apply fails": {
+			policy: retry.Policy{Attempts: 2, Backoff: time.Millisecond},
+			errs:   []error{conflict, conflict},
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RetryType,
+					RetryEvent: &testutil.ExpRetryEvent{
+						GroupName:  taskName,
+						Action:     event.ApplyAction,
+						Identifier: id,
+						Attempt:    1,
+						Error:      conflict,
+					},
+				},
+				{
+					EventType: event.ApplyType,
+					ApplyEvent: &testutil.ExpApplyEvent{
+						GroupName:  taskName,
+						Identifier: id,
+						Error:      testutil.EqualErrorString(conflict.Error()),
+					},
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			ao := &retryApplyOptions{errs: tc.errs}
+			oldAO := applyOptionsFactoryFunc
+			applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions,
+				common.DryRunStrategy, dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+				return ao
+			}
+			defer func() { applyOptionsFactoryFunc = oldAO }()
+
+			eventChannel := make(chan event.Event, 10)
+			taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+			applyTask := &ApplyTask{
+				TaskName:   taskName,
+				Objects:    object.UnstructuredSet{obj},
+				InfoHelper: &fakeInfoHelper{},
+				Retry:      tc.policy,
+			}
+			applyTask.Start(taskContext)
+			<-taskContext.TaskChannel()
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			testutil.AssertEqual(t, tc.expectedEvents, testutil.EventsToExpEvents(events))
+			assert.Equal(t, len(tc.errs), ao.calls)
+		})
+	}
+}
+
+// retryApplyOptions returns the errors in order, one for each run.
+type retryApplyOptions struct {
+	errs  []error
+	calls int
+}
+
+func (f *retryApplyOptions) Run() error {
+	err := f.errs[f.calls]
+	f.calls++
+	return err
+}
+
+func (f *retryApplyOptions) SetObjects([]*resource.Info) {}
+
+// immutableApplyOptions fails to apply objects that exist in the cluster
+// with an immutable field error, and creates the objects that don't.
+type immutableApplyOptions struct {
+	ch      chan<- event.Event
+	client  dynamic.Interface
+	mapper  meta.RESTMapper
+	objects []*resource.Info
+}
+
+func (f *immutableApplyOptions) Run() error {
+	for _, info := range f.objects {
+		obj := info.Object.(*unstructured.Unstructured)
+		id := object.UnstructuredToObjMetadata(obj)
+		mapping, err := f.mapper.RESTMapping(id.GroupKind)
+		if err != nil {
+			return err
+		}
+		client := f.client.Resource(mapping.Resource).Namespace(id.Namespace)
+		_, err = client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+		if err == nil {
+			return fmt.Errorf("Job.batch %q is invalid: spec.template: Invalid value: "+
+				"core.PodTemplateSpec{}: field is immutable", id.Name)
+		}
+		obj = obj.DeepCopy()
+		obj.SetUID("new-uid")
+		created, err := client.Create(context.TODO(), obj, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		info.Object = created
+		f.ch <- event.Event{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Identifier: id,
+				Operation:  event.Created,
+				Resource:   created,
+			},
+		}
+	}
+	return nil
+}
+
+func (f *immutableApplyOptions) SetObjects(objects []*resource.Info) {
+	f.objects = objects
+}
+
+// eventApplyOptions sends an apply event with the operation for each
+// object, without changing the objects in the cluster.
+type eventApplyOptions struct {
+	ch        chan<- event.Event
+	operation event.ApplyEventOperation
+	objects   []*resource.Info
+	// refresh, if not nil, replaces the applied object with the object
+	// returned by the server.
+	refresh func(info *resource.Info)
+}
+
+func (f *eventApplyOptions) Run() error {
+	for _, info := range f.objects {
+		if f.refresh != nil {
+			f.refresh(info)
+		}
+		obj := info.Object.(*unstructured.Unstructured)
+		f.ch <- event.Event{
+			Type: event.ApplyType,
+			ApplyEvent: event.ApplyEvent{
+				Identifier: object.UnstructuredToObjMetadata(obj),
+				Operation:  f.operation,
+				Resource:   obj,
+			},
+		}
+	}
+	return nil
+}
+
+func (f *eventApplyOptions) SetObjects(objects []*resource.Info) {
+	f.objects = objects
+}
+
 func toUnstructured(obj map[string]interface{}) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: obj,
@@ -545,9 +1546,19 @@ func toUnstructureds(rss []resourceInfo) []*unstructured.Unstructured {
 type fakeApplyOptions struct {
 	objects       []*resource.Info
 	passedObjects []*resource.Info
+	// onRun is called, if not nil, each time the objects are applied.
+	onRun func()
+	// err, if not nil, is returned each time the objects are applied.
+	err error
 }
 
 func (f *fakeApplyOptions) Run() error {
+	if f.onRun != nil {
+		f.onRun()
+	}
+	if f.err != nil {
+		return f.err
+	}
 	var err error
 	for _, obj := range f.objects {
 		if strings.Contains(obj.Name, "failure") {
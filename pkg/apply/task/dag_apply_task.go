@@ -0,0 +1,460 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// DAGApplyTask applies objects in dependency order, starting each object
+// as soon as all of its own dependencies are reconciled, instead of
+// waiting for every object in the preceding apply group.
+//
+// The objects are configured as the apply and wait tasks that the level
+// scheduler would run in sequence. Events are sent with the names of
+// those tasks, and their Started and Finished events are sent in order,
+// so printers can keep summarizing the results by action group.
+type DAGApplyTask struct {
+	TaskName string
+	// ApplyTasks apply the objects in each level of the dependency graph.
+	ApplyTasks []*ApplyTask
+	// WaitTasks wait for the objects of the ApplyTask with the same index.
+	// If empty (dry-run), objects are done as soon as they are applied.
+	WaitTasks []*taskrunner.WaitTask
+	// Dependencies are the objects that each object depends on.
+	// Dependencies that are not applied by this task are ignored.
+	Dependencies map[object.ObjMetadata]object.ObjMetadataSet
+	// Concurrency is the maximum number of objects to apply in parallel.
+	// Values less than two apply the objects one at a time.
+	Concurrency int
+
+	// ctx is cancelled to cancel the applies in progress.
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+
+	// mu protects the fields below
+	mu sync.Mutex
+	// sendMu is held while sending the events queued in the outbox, so
+	// that the events are sent in order, but without holding mu.
+	sendMu sync.Mutex
+	// outbox is the events to send, and goroutines to start, once mu is
+	// released.
+	outbox []func()
+	// objects is the state of each object, by id.
+	objects map[object.ObjMetadata]*dagObject
+	// order is the ids of the objects, in apply order.
+	order object.ObjMetadataSet
+	// applyRemaining and waitRemaining are the number of objects in each
+	// level that have not been applied, or are not done waiting.
+	applyRemaining []int
+	waitRemaining  []int
+	// applyFinished and waitFinished are the number of levels whose
+	// Finished event has been sent.
+	applyFinished int
+	waitFinished  int
+	// running is the number of applies in progress.
+	running int
+	// checking is the number of objects whose status is being checked.
+	checking  int
+	remaining int
+	cancelled bool
+	completed bool
+}
+
+// dagObjectState is the progress of an object in the DAGApplyTask.
+type dagObjectState int
+
+const (
+	dagObjectBlocked dagObjectState = iota
+	dagObjectApplying
+	dagObjectChecking
+	dagObjectPending
+	dagObjectDone
+)
+
+// dagObject tracks the progress of a single object.
+type dagObject struct {
+	obj   *unstructured.Unstructured
+	level int
+	state dagObjectState
+	timer *time.Timer
+	// updated and timedOut record the status updates and timeouts that
+	// happened while the status of the object was being checked.
+	updated  bool
+	timedOut bool
+}
+
+func (d *DAGApplyTask) Name() string {
+	return d.TaskName
+}
+
+func (d *DAGApplyTask) Action() event.ResourceAction {
+	return event.ApplyAction
+}
+
+func (d *DAGApplyTask) Identifiers() object.ObjMetadataSet {
+	var ids object.ObjMetadataSet
+	for _, at := range d.ApplyTasks {
+		ids = ids.Union(at.Identifiers())
+	}
+	return ids
+}
+
+// ActionGroups returns the action groups of the apply and wait tasks,
+// in the order they would run with the level scheduler.
+func (d *DAGApplyTask) ActionGroups() []event.ActionGroup {
+	var ags []event.ActionGroup
+	for i, at := range d.ApplyTasks {
+		ags = append(ags, event.ActionGroup{
+			Name:        at.Name(),
+			Action:      at.Action(),
+			Identifiers: at.Identifiers(),
+		})
+		if d.waiting() {
+			wt := d.WaitTasks[i]
+			ags = append(ags, event.ActionGroup{
+				Name:        wt.Name(),
+				Action:      wt.Action(),
+				Identifiers: wt.Identifiers(),
+			})
+		}
+	}
+	return ags
+}
+
+// Start sends the Started events for all the action groups and applies
+// the objects without dependencies. The other objects are applied as
+// their dependencies are done. A dependency is done when it is
+// reconciled, or failed, skipped or timed out, the same as when the
+// WaitTask of its level completes. A TaskResult is pushed on the
+// taskChannel when all objects are done.
+func (d *DAGApplyTask) Start(taskContext *taskrunner.TaskContext) {
+	d.mu.Lock()
+	defer d.unlock()
+
+	d.ctx, d.cancelFunc = context.WithCancel(context.Background())
+
+	klog.V(2).Infof("dag apply task starting (name: %q, objects: %d, concurrency: %d)",
+		d.Name(), len(d.Identifiers()), d.concurrency())
+
+	d.objects = make(map[object.ObjMetadata]*dagObject)
+	d.order = object.ObjMetadataSet{}
+	d.applyRemaining = make([]int, len(d.ApplyTasks))
+	d.waitRemaining = make([]int, len(d.ApplyTasks))
+	for i, at := range d.ApplyTasks {
+		for _, obj := range at.Objects {
+			id := object.UnstructuredToObjMetadata(obj)
+			d.objects[id] = &dagObject{obj: obj, level: i}
+			d.order = append(d.order, id)
+		}
+		d.applyRemaining[i] = len(at.Objects)
+		d.waitRemaining[i] = len(at.Objects)
+	}
+	d.remaining = len(d.order)
+
+	for _, ag := range d.ActionGroups() {
+		d.sendActionGroupEvent(taskContext, ag.Name, ag.Action, event.Started)
+	}
+	d.schedule(taskContext)
+}
+
+// unlock releases the lock, and sends the events queued while it was held.
+// The events are sent without holding the lock, so that a slow event
+// consumer doesn't block the status updates of the task runner.
+func (d *DAGApplyTask) unlock() {
+	outbox := d.outbox
+	d.outbox = nil
+	// Acquire sendMu before releasing mu, so that the events are sent in
+	// the order they were queued.
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+	d.mu.Unlock()
+	for _, send := range outbox {
+		send()
+	}
+}
+
+// concurrency returns the maximum number of objects applied in parallel.
+func (d *DAGApplyTask) concurrency() int {
+	if d.Concurrency < 1 {
+		return 1
+	}
+	return d.Concurrency
+}
+
+// waiting returns true if objects are waited for after they are applied.
+func (d *DAGApplyTask) waiting() bool {
+	return len(d.WaitTasks) > 0
+}
+
+// schedule starts applying the objects whose dependencies are done, up to
+// the concurrency limit, and completes the task if all objects are done.
+// Must be called with the lock held.
+func (d *DAGApplyTask) schedule(taskContext *taskrunner.TaskContext) {
+	for _, id := range d.order {
+		if d.cancelled || d.running >= d.concurrency() {
+			break
+		}
+		o := d.objects[id]
+		if o.state != dagObjectBlocked || !d.ready(id) {
+			continue
+		}
+		o.state = dagObjectApplying
+		d.running++
+		at := d.ApplyTasks[o.level]
+		id, obj := id, o.obj
+		// Start the apply after the queued events are sent, so that its
+		// events are sent after the Started event of its group.
+		d.outbox = append(d.outbox, func() {
+			go func() {
+				at.applyObject(d.ctx, taskContext, obj)
+				d.applied(taskContext, id)
+			}()
+		})
+	}
+	d.complete(taskContext)
+}
+
+// ready returns true if all of the dependencies of the object are done.
+// Must be called with the lock held.
+func (d *DAGApplyTask) ready(id object.ObjMetadata) bool {
+	for _, dep := range d.Dependencies[id] {
+		if o, found := d.objects[dep]; found && o.state != dagObjectDone {
+			return false
+		}
+	}
+	return true
+}
+
+// applied is called after an object has been applied, to start waiting
+// for it to reconcile.
+func (d *DAGApplyTask) applied(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	d.mu.Lock()
+	o := d.objects[id]
+	d.running--
+	d.applyRemaining[o.level]--
+	d.sendFinishedEvents(taskContext)
+
+	if !d.waiting() || d.cancelled {
+		d.done(taskContext, id)
+		d.schedule(taskContext)
+		d.unlock()
+		return
+	}
+	o.state = dagObjectChecking
+	d.checking++
+	d.unlock()
+
+	// The WaitTask sends its own events, so it is called without the lock.
+	reconciled := d.WaitTasks[o.level].CheckObject(taskContext, id)
+	d.checked(taskContext, id, reconciled)
+}
+
+// checked records the result of checking the status of an object, and
+// starts its timeout timer if it is still pending. Status updates and
+// timeouts that happened during the check are handled before the object
+// returns to pending.
+func (d *DAGApplyTask) checked(taskContext *taskrunner.TaskContext, id object.ObjMetadata, done bool) {
+	wt := d.WaitTasks[d.objects[id].level]
+	for {
+		d.mu.Lock()
+		o := d.objects[id]
+		switch {
+		case done:
+			d.checking--
+			d.done(taskContext, id)
+		case d.cancelled:
+			d.checking--
+			o.state = dagObjectPending
+		case o.timedOut:
+			o.timedOut = false
+			o.updated = false
+			d.unlock()
+			wt.TimeoutObject(taskContext, id)
+			done = true
+			continue
+		case o.updated:
+			o.updated = false
+			d.unlock()
+			done = wt.UpdateObject(taskContext, id)
+			continue
+		default:
+			d.checking--
+			o.state = dagObjectPending
+			if o.timer == nil && wt.Timeout > 0 {
+				o.timer = time.AfterFunc(wt.Timeout, func() {
+					d.timeout(taskContext, id)
+				})
+			}
+		}
+		d.schedule(taskContext)
+		d.unlock()
+		return
+	}
+}
+
+// timeout is called when an object has not reconciled within the
+// timeout of its WaitTask.
+func (d *DAGApplyTask) timeout(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	d.mu.Lock()
+	o := d.objects[id]
+	switch {
+	case d.cancelled || o.state == dagObjectDone:
+		d.unlock()
+		return
+	case o.state == dagObjectChecking:
+		o.timedOut = true
+		d.unlock()
+		return
+	}
+	o.state = dagObjectChecking
+	o.timedOut = true
+	d.checking++
+	d.unlock()
+	d.checked(taskContext, id, false)
+}
+
+// done marks the object as done and sends any Finished events that are
+// no longer blocked by it. Must be called with the lock held.
+func (d *DAGApplyTask) done(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	o := d.objects[id]
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	o.state = dagObjectDone
+	d.remaining--
+	d.waitRemaining[o.level]--
+	// Update RESTMapper to pick up new custom resource types
+	im := taskContext.InventoryManager()
+	if d.waiting() && object.IsCRD(o.obj) && !im.IsFailedApply(id) && !im.IsSkippedApply(id) {
+		klog.V(5).Infof("resetting RESTMapper")
+		meta.MaybeResetRESTMapper(d.WaitTasks[o.level].Mapper)
+	}
+	d.sendFinishedEvents(taskContext)
+}
+
+// sendFinishedEvents sends the Finished events for the apply and wait
+// groups that are complete, in order. Must be called with the lock held.
+func (d *DAGApplyTask) sendFinishedEvents(taskContext *taskrunner.TaskContext) {
+	for d.applyFinished < len(d.ApplyTasks) && d.applyRemaining[d.applyFinished] == 0 {
+		at := d.ApplyTasks[d.applyFinished]
+		d.sendActionGroupEvent(taskContext, at.Name(), at.Action(), event.Finished)
+		d.applyFinished++
+	}
+	if !d.waiting() {
+		return
+	}
+	// Wait groups finish after the apply group of the same level.
+	for d.waitFinished < d.applyFinished && d.waitRemaining[d.waitFinished] == 0 {
+		wt := d.WaitTasks[d.waitFinished]
+		d.sendActionGroupEvent(taskContext, wt.Name(), wt.Action(), event.Finished)
+		d.waitFinished++
+	}
+}
+
+// complete signals completion to the task runner, if all objects are
+// done, or if the task was cancelled and no applies are in progress.
+// Must be called with the lock held.
+func (d *DAGApplyTask) complete(taskContext *taskrunner.TaskContext) {
+	if d.completed || d.running > 0 || d.checking > 0 || (d.remaining > 0 && !d.cancelled) {
+		return
+	}
+	d.completed = true
+	for _, o := range d.objects {
+		if o.timer != nil {
+			o.timer.Stop()
+		}
+	}
+	// Skip the objects that were not applied, if cancelled.
+	for _, id := range d.order {
+		if o := d.objects[id]; o.state == dagObjectBlocked {
+			at, obj := d.ApplyTasks[o.level], o.obj
+			d.outbox = append(d.outbox, func() {
+				at.skipObject(taskContext, obj, taskrunner.CancelledReason)
+			})
+		}
+	}
+	// Send the remaining Finished events, if cancelled.
+	for i := d.applyFinished; i < len(d.ApplyTasks); i++ {
+		d.applyRemaining[i] = 0
+	}
+	for i := d.waitFinished; i < len(d.WaitTasks); i++ {
+		d.waitRemaining[i] = 0
+	}
+	d.sendFinishedEvents(taskContext)
+	d.cancelFunc()
+
+	klog.V(2).Infof("dag apply task completing (name: %q)", d.Name())
+	// Send the result asynchronously, after the queued events, so the
+	// task runner can keep sending status updates until it receives it.
+	d.outbox = append(d.outbox, func() {
+		go func() {
+			taskContext.TaskChannel() <- taskrunner.TaskResult{}
+		}()
+	})
+}
+
+// Cancel stops applying new objects and waiting for applied objects.
+// Applies in progress are cancelled, and the task completes once they
+// return.
+func (d *DAGApplyTask) Cancel(taskContext *taskrunner.TaskContext) {
+	d.mu.Lock()
+	defer d.unlock()
+
+	d.cancelled = true
+	if d.cancelFunc != nil {
+		d.cancelFunc()
+	}
+	d.complete(taskContext)
+}
+
+// StatusUpdate checks whether a pending object is done reconciling, and
+// applies the objects that depend on it, if they are ready.
+func (d *DAGApplyTask) StatusUpdate(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	d.mu.Lock()
+	o, found := d.objects[id]
+	switch {
+	case !found || d.cancelled:
+		d.unlock()
+		return
+	case o.state == dagObjectChecking:
+		// checked again once the current check is done
+		o.updated = true
+		d.unlock()
+		return
+	case o.state != dagObjectPending:
+		d.unlock()
+		return
+	}
+	o.state = dagObjectChecking
+	o.updated = true
+	d.checking++
+	d.unlock()
+	d.checked(taskContext, id, false)
+}
+
+// sendActionGroupEvent queues an ActionGroup event, to be sent once the
+// lock is released. Must be called with the lock held.
+func (d *DAGApplyTask) sendActionGroupEvent(taskContext *taskrunner.TaskContext, name string,
+	action event.ResourceAction, t event.ActionGroupEventType) {
+	d.outbox = append(d.outbox, func() {
+		taskContext.SendEvent(event.Event{
+			Type: event.ActionGroupType,
+			ActionGroupEvent: event.ActionGroupEvent{
+				GroupName: name,
+				Action:    action,
+				Type:      t,
+			},
+		})
+	})
+}
@@ -0,0 +1,230 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestDAGApplyTask(t *testing.T) {
+	objs := toUnstructureds([]resourceInfo{
+		{apiVersion: "v1", kind: "ConfigMap", name: "a", namespace: "default", uid: types.UID("uid-a")},
+		{apiVersion: "v1", kind: "ConfigMap", name: "b", namespace: "default", uid: types.UID("uid-b")},
+		{apiVersion: "v1", kind: "ConfigMap", name: "c", namespace: "default", uid: types.UID("uid-c")},
+	})
+	objA, objB, objC := objs[0], objs[1], objs[2]
+	idA := object.UnstructuredToObjMetadata(objA)
+	idB := object.UnstructuredToObjMetadata(objB)
+	idC := object.UnstructuredToObjMetadata(objC)
+
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+		dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+		return &fakeApplyOptions{}
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	mapper := testutil.NewFakeRESTMapper()
+	// b depends on a, c has no dependencies.
+	dagTask := &DAGApplyTask{
+		TaskName: "dag-apply-0",
+		ApplyTasks: []*ApplyTask{
+			{
+				TaskName:   "apply-0",
+				Objects:    object.UnstructuredSet{objA, objC},
+				InfoHelper: &fakeInfoHelper{},
+			},
+			{
+				TaskName:   "apply-1",
+				Objects:    object.UnstructuredSet{objB},
+				InfoHelper: &fakeInfoHelper{},
+			},
+		},
+		WaitTasks: []*taskrunner.WaitTask{
+			taskrunner.NewWaitTask("wait-0", object.ObjMetadataSet{idA, idC}, taskrunner.AllCurrent, 0, mapper),
+			taskrunner.NewWaitTask("wait-1", object.ObjMetadataSet{idB}, taskrunner.AllCurrent, 0, mapper),
+		},
+		Dependencies: map[object.ObjMetadata]object.ObjMetadataSet{
+			idB: {idA},
+		},
+		Concurrency: 2,
+	}
+
+	eventChannel := make(chan event.Event, 100)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+	im := taskContext.InventoryManager()
+
+	var events []event.Event
+	// waitForEvents reads events until all the expected wait events have
+	// been received.
+	waitForEvents := func(op event.WaitEventOperation, ids ...object.ObjMetadata) {
+		expected := object.ObjMetadataSet(ids)
+		for len(expected) > 0 {
+			select {
+			case e := <-eventChannel:
+				events = append(events, e)
+				if e.Type == event.WaitType && e.WaitEvent.Operation == op {
+					expected = expected.Remove(e.WaitEvent.Identifier)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for %s events for (%s)", op, expected)
+			}
+		}
+	}
+	reconcile := func(obj *unstructured.Unstructured) {
+		id := object.UnstructuredToObjMetadata(obj)
+		resourceCache.Put(id, cache.ResourceStatus{
+			Resource: obj,
+			Status:   status.CurrentStatus,
+		})
+		dagTask.StatusUpdate(taskContext, id)
+	}
+
+	dagTask.Start(taskContext)
+
+	// a and c are applied right away, b waits for a.
+	waitForEvents(event.ReconcilePending, idA, idC)
+	_, found := im.ObjectStatus(idB)
+	assert.False(t, found, "expected %s not to be applied before its dependency", idB)
+
+	// c does not unblock b.
+	reconcile(objC)
+	waitForEvents(event.Reconciled, idC)
+	_, found = im.ObjectStatus(idB)
+	assert.False(t, found, "expected %s not to be applied before its dependency", idB)
+
+	// a unblocks b, without waiting for the rest of the apply group.
+	reconcile(objA)
+	waitForEvents(event.Reconciled, idA)
+	waitForEvents(event.ReconcilePending, idB)
+
+	reconcile(objB)
+	waitForEvents(event.Reconciled, idB)
+
+	select {
+	case result := <-taskContext.TaskChannel():
+		assert.NoError(t, result.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the task to complete")
+	}
+	close(eventChannel)
+	for e := range eventChannel {
+		events = append(events, e)
+	}
+
+	assert.True(t, object.ObjMetadataSet{idA, idB, idC}.Equal(im.SuccessfulApplies()))
+	assert.True(t, object.ObjMetadataSet{idA, idB, idC}.Equal(im.SuccessfulReconciles()))
+
+	// The action groups are started and finished in order.
+	var started, finished []string
+	for _, e := range events {
+		if e.Type != event.ActionGroupType {
+			continue
+		}
+		if e.ActionGroupEvent.Type == event.Started {
+			started = append(started, e.ActionGroupEvent.GroupName)
+		} else {
+			finished = append(finished, e.ActionGroupEvent.GroupName)
+		}
+	}
+	expectedGroups := []string{"apply-0", "wait-0", "apply-1", "wait-1"}
+	assert.Equal(t, expectedGroups, started)
+	assert.Equal(t, expectedGroups, finished)
+}
+
+func TestDAGApplyTaskCancel(t *testing.T) {
+	objs := toUnstructureds([]resourceInfo{
+		{apiVersion: "v1", kind: "ConfigMap", name: "a", namespace: "default", uid: types.UID("uid-a")},
+		{apiVersion: "v1", kind: "ConfigMap", name: "b", namespace: "default", uid: types.UID("uid-b")},
+	})
+	objA, objB := objs[0], objs[1]
+	idA := object.UnstructuredToObjMetadata(objA)
+	idB := object.UnstructuredToObjMetadata(objB)
+
+	oldAO := applyOptionsFactoryFunc
+	applyOptionsFactoryFunc = func(string, chan<- event.Event, common.ServerSideOptions, common.DryRunStrategy,
+		dynamic.Interface, discovery.OpenAPISchemaInterface) applyOptions {
+		return &fakeApplyOptions{}
+	}
+	defer func() { applyOptionsFactoryFunc = oldAO }()
+
+	mapper := testutil.NewFakeRESTMapper()
+	// b depends on a, so it is never applied.
+	dagTask := &DAGApplyTask{
+		TaskName: "dag-apply-0",
+		ApplyTasks: []*ApplyTask{
+			{
+				TaskName:   "apply-0",
+				Objects:    object.UnstructuredSet{objA},
+				InfoHelper: &fakeInfoHelper{},
+			},
+			{
+				TaskName:   "apply-1",
+				Objects:    object.UnstructuredSet{objB},
+				InfoHelper: &fakeInfoHelper{},
+			},
+		},
+		WaitTasks: []*taskrunner.WaitTask{
+			taskrunner.NewWaitTask("wait-0", object.ObjMetadataSet{idA}, taskrunner.AllCurrent, 0, mapper),
+			taskrunner.NewWaitTask("wait-1", object.ObjMetadataSet{idB}, taskrunner.AllCurrent, 0, mapper),
+		},
+		Dependencies: map[object.ObjMetadata]object.ObjMetadataSet{
+			idB: {idA},
+		},
+	}
+
+	eventChannel := make(chan event.Event, 100)
+	taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+	im := taskContext.InventoryManager()
+
+	var events []event.Event
+	dagTask.Start(taskContext)
+	for pending := false; !pending; {
+		select {
+		case e := <-eventChannel:
+			events = append(events, e)
+			pending = e.Type == event.WaitType && e.WaitEvent.Operation == event.ReconcilePending
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s to be pending", idA)
+		}
+	}
+
+	dagTask.Cancel(taskContext)
+	select {
+	case result := <-taskContext.TaskChannel():
+		assert.NoError(t, result.Err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the task to complete")
+	}
+	assert.Error(t, dagTask.ctx.Err(), "expected the apply context to be cancelled")
+	close(eventChannel)
+	for e := range eventChannel {
+		events = append(events, e)
+	}
+
+	assert.True(t, object.ObjMetadataSet{idA}.Equal(im.SuccessfulApplies()))
+	assert.True(t, object.ObjMetadataSet{idB}.Equal(im.SkippedApplies()))
+	var finished []string
+	for _, e := range events {
+		if e.Type == event.ActionGroupType && e.ActionGroupEvent.Type == event.Finished {
+			finished = append(finished, e.ActionGroupEvent.GroupName)
+		}
+	}
+	assert.Equal(t, []string{"apply-0", "apply-1", "wait-0", "wait-1"}, finished)
+}
@@ -4,9 +4,12 @@
 package task
 
 import (
+	"context"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -18,12 +21,18 @@ import (
 // the Task interface. This task should happen after all
 // resources have been deleted.
 type DeleteInvTask struct {
-	TaskName  string
-	InvClient inventory.Client
-	InvInfo   inventory.Info
-	DryRun    common.DryRunStrategy
+	TaskName      string
+	InvClient     inventory.Client
+	InvInfo       inventory.Info
+	PrevInventory object.ObjMetadataSet
+	DryRun        common.DryRunStrategy
+	// Retry defines how inventory writes that fail with transient errors
+	// are retried. The zero value does not retry.
+	Retry retry.Policy
 }
 
+var _ taskrunner.FinalTask = &DeleteInvTask{}
+
 func (i *DeleteInvTask) Name() string {
 	return i.TaskName
 }
@@ -40,7 +49,9 @@ func (i *DeleteInvTask) Identifiers() object.ObjMetadataSet {
 func (i *DeleteInvTask) Start(taskContext *taskrunner.TaskContext) {
 	go func() {
 		klog.V(2).Infof("delete inventory task starting (name: %q)", i.Name())
-		err := i.InvClient.DeleteInventoryObj(i.InvInfo, i.DryRun)
+		err := i.Retry.Do(context.TODO(), func() error {
+			return i.InvClient.DeleteInventoryObj(i.InvInfo, i.DryRun)
+		}, retryEventFunc(taskContext, i.Name(), inventoryIdentifier(i.InvInfo), event.InventoryAction, i.Retry))
 		// Not found is not error, since this means it was already deleted.
 		if apierrors.IsNotFound(err) {
 			err = nil
@@ -50,6 +61,32 @@ func (i *DeleteInvTask) Start(taskContext *taskrunner.TaskContext) {
 	}()
 }
 
+// StartOnAbort deletes the inventory object, like Start, if all the
+// objects in the inventory were deleted or abandoned before the abort.
+// Otherwise, the inventory is replaced with the remaining objects,
+// including deleted objects that were still waiting to be removed from
+// the cluster (ex: blocked by finalizer).
+func (i *DeleteInvTask) StartOnAbort(taskContext *taskrunner.TaskContext) {
+	im := taskContext.InventoryManager()
+	deletedObjs := im.SuccessfulDeletes().Diff(im.PendingReconciles())
+	remainingObjs := i.PrevInventory.
+		Diff(deletedObjs).
+		Diff(taskContext.AbandonedObjects())
+	if len(remainingObjs) == 0 {
+		i.Start(taskContext)
+		return
+	}
+	go func() {
+		klog.V(2).Infof("delete inventory task starting after abort (name: %q)", i.Name())
+		klog.V(4).Infof("keep in inventory %d objects not deleted before abort", len(remainingObjs))
+		err := i.Retry.Do(context.TODO(), func() error {
+			return replaceInventory(i.InvClient, i.InvInfo, remainingObjs, im, i.DryRun)
+		}, retryEventFunc(taskContext, i.Name(), inventoryIdentifier(i.InvInfo), event.InventoryAction, i.Retry))
+		klog.V(2).Infof("delete inventory task completing (name: %q)", i.Name())
+		taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
+	}()
+}
+
 // Cancel is not supported by the DeleteInvTask.
 func (i *DeleteInvTask) Cancel(_ *taskrunner.TaskContext) {}
 
@@ -14,6 +14,7 @@ import (
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
 )
 
 func TestDeleteInvTask(t *testing.T) {
@@ -66,3 +67,81 @@ func TestDeleteInvTask(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteInvTask_StartOnAbort(t *testing.T) {
+	id1 := object.UnstructuredToObjMetadata(obj1)
+	id2 := object.UnstructuredToObjMetadata(obj2)
+	id3 := object.UnstructuredToObjMetadata(obj3)
+
+	testCases := map[string]struct {
+		prevInventory  object.ObjMetadataSet
+		deletedObjs    object.ObjMetadataSet
+		reconciledObjs object.ObjMetadataSet
+		abandonedObjs  object.ObjMetadataSet
+		expectedObjs   object.ObjMetadataSet
+	}{
+		"all objects deleted; inventory deleted": {
+			prevInventory:  object.ObjMetadataSet{id1, id2},
+			deletedObjs:    object.ObjMetadataSet{id1, id2},
+			reconciledObjs: object.ObjMetadataSet{id1, id2},
+			expectedObjs:   object.ObjMetadataSet{},
+		},
+		"objects deleted or abandoned; inventory deleted": {
+			prevInventory:  object.ObjMetadataSet{id1, id2},
+			deletedObjs:    object.ObjMetadataSet{id1},
+			reconciledObjs: object.ObjMetadataSet{id1},
+			abandonedObjs:  object.ObjMetadataSet{id2},
+			expectedObjs:   object.ObjMetadataSet{},
+		},
+		"one object not reached; inventory kept": {
+			prevInventory:  object.ObjMetadataSet{id1, id2, id3},
+			deletedObjs:    object.ObjMetadataSet{id1, id2},
+			reconciledObjs: object.ObjMetadataSet{id1, id2},
+			expectedObjs:   object.ObjMetadataSet{id3},
+		},
+		"one deleted object not yet removed; inventory kept": {
+			prevInventory:  object.ObjMetadataSet{id1, id2},
+			deletedObjs:    object.ObjMetadataSet{id1, id2},
+			reconciledObjs: object.ObjMetadataSet{id1},
+			expectedObjs:   object.ObjMetadataSet{id2},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := inventory.NewFakeClient(tc.prevInventory)
+			eventChannel := make(chan event.Event)
+			resourceCache := cache.NewResourceCacheMap()
+			context := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			im := context.InventoryManager()
+			for _, deletedObj := range tc.deletedObjs {
+				im.AddSuccessfulDelete(deletedObj, "unused-uid")
+			}
+			for _, reconciledObj := range tc.reconciledObjs {
+				if err := im.SetSuccessfulReconcile(reconciledObj); err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+			}
+			for _, abandonedObj := range tc.abandonedObjs {
+				context.AddAbandonedObject(abandonedObj)
+			}
+
+			task := DeleteInvTask{
+				TaskName:      taskName,
+				InvClient:     client,
+				InvInfo:       localInv,
+				PrevInventory: tc.prevInventory,
+				DryRun:        common.DryRunNone,
+			}
+			task.StartOnAbort(context)
+			result := <-context.TaskChannel()
+			if result.Err != nil {
+				t.Errorf("unexpected error running DeleteInvTask: %s", result.Err)
+			}
+			actual, _ := client.GetClusterObjs(localInv)
+			testutil.AssertEqual(t, tc.expectedObjs, actual,
+				"Actual cluster objects (%d) do not match expected cluster objects (%d)",
+				len(actual), len(tc.expectedObjs))
+		})
+	}
+}
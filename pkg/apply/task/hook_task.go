@@ -0,0 +1,364 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
+)
+
+// hookDeletePollInterval is how often to check whether the hook object
+// from a previous run has been deleted, before re-creating it.
+var hookDeletePollInterval = time.Second
+
+// hookDeleteTimeout is how long to wait for the hook object from a
+// previous run to be deleted, if the task has no Timeout.
+var hookDeleteTimeout = 5 * time.Minute
+
+// HookTask runs the hook objects of a lifecycle phase. Each hook is
+// created, replacing the object from a previous run if it exists, and
+// the task waits for the hooks to complete, using the object from the
+// status poller: a Pod hook completes when its phase is Succeeded or
+// Failed, and a Job hook when it has the Complete or Failed condition.
+// Hooks of other kinds succeed when they are Current, and fail when
+// they are Failed. Afterwards hooks are deleted if their delete policy
+// requires it.
+// If a pre-apply or pre-delete hook fails, the task fails, so that no
+// objects are applied or deleted.
+type HookTask struct {
+	TaskName string
+
+	DynamicClient dynamic.Interface
+	Mapper        meta.RESTMapper
+	Phase         hook.Phase
+	Objects       object.UnstructuredSet
+	// Timeout defines how long to wait for the hooks to complete. If zero,
+	// the task waits until all hooks have succeeded or failed.
+	Timeout time.Duration
+
+	// cancelFunc is a function that will cancel the timeout timer
+	// on the task.
+	cancelFunc context.CancelFunc
+	// mu protects the fields below
+	mu sync.Mutex
+	// pending maps the hooks that have been created, but have not
+	// completed, to their UID.
+	pending map[object.ObjMetadata]types.UID
+	// succeeded and failed are the hooks that have completed.
+	succeeded object.ObjMetadataSet
+	failed    object.ObjMetadataSet
+	// created is true after all the hooks have been created.
+	created bool
+}
+
+func (h *HookTask) Name() string {
+	return h.TaskName
+}
+
+func (h *HookTask) Action() event.ResourceAction {
+	return event.HookAction
+}
+
+func (h *HookTask) Identifiers() object.ObjMetadataSet {
+	return object.UnstructuredSetToObjMetadataSet(h.Objects)
+}
+
+// Start creates a new goroutine that creates the hooks and waits for
+// them to complete, time out or for the task to be cancelled. It will
+// push a TaskResult on the taskChannel when done.
+func (h *HookTask) Start(taskContext *taskrunner.TaskContext) {
+	klog.V(2).Infof("hook task starting (name: %q, phase: %q, objects: %d)",
+		h.Name(), h.Phase, len(h.Objects))
+
+	// TODO: inherit context from task runner, passed through the TaskContext
+	ctx := context.Background()
+	if h.Timeout > 0 {
+		ctx, h.cancelFunc = context.WithTimeout(ctx, h.Timeout)
+	} else {
+		ctx, h.cancelFunc = context.WithCancel(ctx)
+	}
+	h.pending = make(map[object.ObjMetadata]types.UID)
+
+	go func() {
+		for _, obj := range h.Objects {
+			if ctx.Err() != nil {
+				break
+			}
+			id := object.UnstructuredToObjMetadata(obj)
+			uid, err := h.create(ctx, id, obj)
+
+			h.mu.Lock()
+			if err != nil {
+				h.completeHook(taskContext, id, event.HookFailed,
+					fmt.Errorf("failed to create hook: %w", err))
+			} else {
+				h.sendEvent(taskContext, id, event.HookStarted, nil)
+				h.pending[id] = uid
+				h.checkStatus(taskContext, id)
+			}
+			h.mu.Unlock()
+		}
+
+		h.mu.Lock()
+		h.created = true
+		h.completeIfDone()
+		h.mu.Unlock()
+
+		// Block until complete/cancel/timeout
+		<-ctx.Done()
+		klog.V(2).Infof("hook task completing (name: %q): %v", h.Name(), ctx.Err())
+
+		h.mu.Lock()
+		if ctx.Err() == context.DeadlineExceeded {
+			for _, id := range h.Identifiers() {
+				if _, found := h.pending[id]; found {
+					h.completeHook(taskContext, id, event.HookFailed,
+						fmt.Errorf("timed out waiting for hook to complete"))
+				}
+			}
+		}
+		h.cleanup(taskContext)
+		var err error
+		if len(h.failed) > 0 && (h.Phase == hook.PreApply || h.Phase == hook.PreDelete) {
+			err = fmt.Errorf("%d %s hook(s) failed", len(h.failed), h.Phase)
+		}
+		h.mu.Unlock()
+
+		// Done here. signal completion to the task runner
+		taskContext.TaskChannel() <- taskrunner.TaskResult{
+			Err: err,
+		}
+	}()
+}
+
+// create deletes the hook object from a previous run, if it exists, and
+// creates the hook object. Returns the UID of the created object.
+func (h *HookTask) create(ctx context.Context, id object.ObjMetadata, obj *unstructured.Unstructured) (types.UID, error) {
+	client, err := h.namespacedClient(id)
+	if err != nil {
+		return "", err
+	}
+	propagation := metav1.DeletePropagationBackground
+	err = client.Delete(ctx, id.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	switch {
+	case apierrors.IsNotFound(err):
+		// no previous hook object
+	case err != nil:
+		return "", err
+	default:
+		klog.V(4).Infof("waiting for previous hook to be deleted (object: %q)", id)
+		deleteCtx := ctx
+		if _, found := ctx.Deadline(); !found {
+			var cancel context.CancelFunc
+			deleteCtx, cancel = context.WithTimeout(ctx, hookDeleteTimeout)
+			defer cancel()
+		}
+		err = wait.PollImmediateUntilWithContext(deleteCtx, hookDeletePollInterval, func(ctx context.Context) (bool, error) {
+			_, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			if deleteCtx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("timed out waiting for previous hook object to be deleted")
+			}
+			return "", err
+		}
+	}
+	obj = obj.DeepCopy()
+	object.StripKyamlAnnotations(obj)
+	klog.V(4).Infof("creating hook (object: %q)", id)
+	created, err := client.Create(ctx, obj, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return created.GetUID(), nil
+}
+
+// checkStatus completes a pending hook if the hook object in the
+// ResourceCache has succeeded or failed. Statuses of objects with a
+// different UID, from before the hook was created, are ignored.
+// Must be called with the lock held.
+func (h *HookTask) checkStatus(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	uid, found := h.pending[id]
+	if !found {
+		return
+	}
+	cached := taskContext.ResourceCache().Get(id)
+	if cached.Resource == nil || cached.Resource.GetUID() != uid {
+		return
+	}
+	switch result, message := hookResult(cached); result {
+	case status.CurrentStatus:
+		h.completeHook(taskContext, id, event.HookSucceeded, nil)
+	case status.FailedStatus:
+		h.completeHook(taskContext, id, event.HookFailed,
+			fmt.Errorf("hook failed: %s", message))
+	default:
+		return
+	}
+	h.completeIfDone()
+}
+
+// hookResult returns CurrentStatus if the hook object has succeeded,
+// FailedStatus with a message if it has failed, and InProgressStatus
+// otherwise. Pods and Jobs have run to completion only when they report
+// it, which is later than when kstatus considers them Current.
+func hookResult(cached cache.ResourceStatus) (status.Status, string) {
+	obj := cached.Resource
+	switch obj.GroupVersionKind().GroupKind() {
+	case schema.GroupKind{Kind: "Pod"}:
+		phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+		switch phase {
+		case "Succeeded":
+			return status.CurrentStatus, ""
+		case "Failed":
+			message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+			if message == "" {
+				message = "Pod phase is Failed"
+			}
+			return status.FailedStatus, message
+		}
+		return status.InProgressStatus, ""
+	case schema.GroupKind{Group: "batch", Kind: "Job"}:
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["status"] != "True" {
+				continue
+			}
+			switch condition["type"] {
+			case "Complete":
+				return status.CurrentStatus, ""
+			case "Failed":
+				message, _ := condition["message"].(string)
+				if message == "" {
+					message = "Job has the Failed condition"
+				}
+				return status.FailedStatus, message
+			}
+		}
+		return status.InProgressStatus, ""
+	}
+	switch cached.Status {
+	case status.CurrentStatus, status.FailedStatus:
+		return cached.Status, cached.StatusMessage
+	}
+	return status.InProgressStatus, ""
+}
+
+// completeHook records the result of a hook and sends an event.
+// Must be called with the lock held.
+func (h *HookTask) completeHook(taskContext *taskrunner.TaskContext, id object.ObjMetadata,
+	op event.HookEventOperation, err error) {
+	delete(h.pending, id)
+	if op == event.HookSucceeded {
+		h.succeeded = append(h.succeeded, id)
+	} else {
+		h.failed = append(h.failed, id)
+	}
+	h.sendEvent(taskContext, id, op, err)
+}
+
+// completeIfDone cancels the task context, if all the hooks have been
+// created and completed. Must be called with the lock held.
+func (h *HookTask) completeIfDone() {
+	if h.created && len(h.pending) == 0 {
+		klog.V(3).Infof("all hooks completed (name: %q)", h.Name())
+		h.cancelFunc()
+	}
+}
+
+// cleanup deletes the completed hooks, according to their delete
+// policy. Must be called with the lock held.
+func (h *HookTask) cleanup(taskContext *taskrunner.TaskContext) {
+	for _, obj := range h.Objects {
+		id := object.UnstructuredToObjMetadata(obj)
+		policy, err := hook.ReadDeletePolicy(obj)
+		if err != nil {
+			// Invalid hooks are filtered before the task is created.
+			klog.Errorf("Failed to read hook delete policy: %v", err)
+			continue
+		}
+		switch {
+		case policy == hook.DeleteAlways && (h.succeeded.Contains(id) || h.failed.Contains(id)):
+		case policy == hook.DeleteOnSuccess && h.succeeded.Contains(id):
+		default:
+			continue
+		}
+		err = h.delete(id)
+		h.sendEvent(taskContext, id, event.HookDeleted, err)
+	}
+}
+
+// delete deletes a hook object that has run.
+func (h *HookTask) delete(id object.ObjMetadata) error {
+	client, err := h.namespacedClient(id)
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("deleting hook (object: %q)", id)
+	propagation := metav1.DeletePropagationBackground
+	err = client.Delete(context.TODO(), id.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (h *HookTask) namespacedClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
+	mapping, err := h.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return h.DynamicClient.Resource(mapping.Resource).Namespace(id.Namespace), nil
+}
+
+func (h *HookTask) sendEvent(taskContext *taskrunner.TaskContext, id object.ObjMetadata,
+	op event.HookEventOperation, err error) {
+	if err != nil && klog.V(4).Enabled() {
+		klog.Errorf("hook %s (object: %q): %v", op, id, err)
+	}
+	taskContext.SendEvent(event.Event{
+		Type: event.HookType,
+		HookEvent: event.HookEvent{
+			GroupName:  h.Name(),
+			Identifier: id,
+			Operation:  op,
+			Error:      err,
+		},
+	})
+}
+
+// Cancel stops waiting for the hooks to complete.
+func (h *HookTask) Cancel(_ *taskrunner.TaskContext) {
+	h.cancelFunc()
+}
+
+// StatusUpdate completes the hook, if it has succeeded or failed.
+func (h *HookTask) StatusUpdate(taskContext *taskrunner.TaskContext, id object.ObjMetadata) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checkStatus(taskContext, id)
+}
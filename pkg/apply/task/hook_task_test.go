@@ -0,0 +1,303 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/hook"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func hookJob(name string, uid types.UID, deletePolicy hook.DeletePolicy) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+				"uid":       string(uid),
+				"annotations": map[string]interface{}{
+					hook.Annotation: string(hook.PreApply),
+				},
+			},
+		},
+	}
+	if deletePolicy != "" {
+		annotations := u.GetAnnotations()
+		annotations[hook.DeletePolicyAnnotation] = string(deletePolicy)
+		u.SetAnnotations(annotations)
+	}
+	return u
+}
+
+func hookPod(name string, uid types.UID) *unstructured.Unstructured {
+	u := hookJob(name, uid, "")
+	u.SetAPIVersion("v1")
+	u.SetKind("Pod")
+	return u
+}
+
+// withResult returns a copy of the hook object, as reported by the status
+// poller after the hook has run. The result is the phase of a Pod, or
+// the condition type of a Job.
+func withResult(obj *unstructured.Unstructured, result string) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	if result == "" {
+		return obj
+	}
+	if obj.GetKind() == "Pod" {
+		obj.Object["status"] = map[string]interface{}{
+			"phase":   result,
+			"message": "Pod exited with code 1",
+		}
+		return obj
+	}
+	obj.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{
+				"type":    result,
+				"status":  "True",
+				"message": "Job has reached the specified backoff limit",
+			},
+		},
+	}
+	return obj
+}
+
+func TestHookTask(t *testing.T) {
+	hook1 := hookJob("hook-1", "uid-1", "")
+	hook2 := hookJob("hook-2", "uid-2", hook.DeleteOnSuccess)
+	hook3 := hookPod("hook-3", "uid-3")
+	id1 := object.UnstructuredToObjMetadata(hook1)
+	id2 := object.UnstructuredToObjMetadata(hook2)
+	id3 := object.UnstructuredToObjMetadata(hook3)
+
+	// hook1 exists from a previous run, with a different UID.
+	previous1 := hookJob("hook-1", "uid-old", "")
+
+	tests := map[string]struct {
+		phase    hook.Phase
+		liveObjs []runtime.Object
+		hooks    object.UnstructuredSet
+		timeout  time.Duration
+		statuses map[object.ObjMetadata]status.Status
+		results  map[object.ObjMetadata]string
+		// keepDeleted makes deletes succeed without removing the object
+		keepDeleted    bool
+		expectedEvents []testutil.ExpEvent
+		expectedLive   object.ObjMetadataSet
+		expectedGone   object.ObjMetadataSet
+		isError        bool
+	}{
+		"hooks succeed; delete on success": {
+			phase: hook.PreApply,
+			hooks: object.UnstructuredSet{hook1, hook2},
+			results: map[object.ObjMetadata]string{
+				id1: "Complete",
+				id2: "Complete",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id1, event.HookStarted, nil),
+				expHookEvent(id1, event.HookSucceeded, nil),
+				expHookEvent(id2, event.HookStarted, nil),
+				expHookEvent(id2, event.HookSucceeded, nil),
+				expHookEvent(id2, event.HookDeleted, nil),
+			},
+			expectedLive: object.ObjMetadataSet{id1},
+			expectedGone: object.ObjMetadataSet{id2},
+		},
+		"previous hook object is replaced": {
+			phase:    hook.PreApply,
+			liveObjs: []runtime.Object{previous1},
+			hooks:    object.UnstructuredSet{hook1},
+			results: map[object.ObjMetadata]string{
+				id1: "Complete",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id1, event.HookStarted, nil),
+				expHookEvent(id1, event.HookSucceeded, nil),
+			},
+			expectedLive: object.ObjMetadataSet{id1},
+		},
+		"pre-apply hook fails; task fails": {
+			phase: hook.PreApply,
+			hooks: object.UnstructuredSet{hook2},
+			results: map[object.ObjMetadata]string{
+				id2: "Failed",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id2, event.HookStarted, nil),
+				expHookEvent(id2, event.HookFailed, testutil.EqualErrorString("hook failed: Job has reached the specified backoff limit")),
+			},
+			expectedLive: object.ObjMetadataSet{id2},
+			isError:      true,
+		},
+		"post-apply hook fails; task succeeds": {
+			phase: hook.PostApply,
+			hooks: object.UnstructuredSet{hook2},
+			results: map[object.ObjMetadata]string{
+				id2: "Failed",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id2, event.HookStarted, nil),
+				expHookEvent(id2, event.HookFailed, testutil.EqualErrorString("hook failed: Job has reached the specified backoff limit")),
+			},
+			expectedLive: object.ObjMetadataSet{id2},
+		},
+		"current job without complete condition times out": {
+			phase:   hook.PreApply,
+			hooks:   object.UnstructuredSet{hook1},
+			timeout: 10 * time.Millisecond,
+			statuses: map[object.ObjMetadata]status.Status{
+				id1: status.CurrentStatus,
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id1, event.HookStarted, nil),
+				expHookEvent(id1, event.HookFailed, testutil.EqualErrorString("timed out waiting for hook to complete")),
+			},
+			expectedLive: object.ObjMetadataSet{id1},
+			isError:      true,
+		},
+		"pod hook succeeds": {
+			phase: hook.PreDelete,
+			hooks: object.UnstructuredSet{hook3},
+			statuses: map[object.ObjMetadata]status.Status{
+				id3: status.InProgressStatus,
+			},
+			results: map[object.ObjMetadata]string{
+				id3: "Succeeded",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id3, event.HookStarted, nil),
+				expHookEvent(id3, event.HookSucceeded, nil),
+			},
+			expectedLive: object.ObjMetadataSet{id3},
+		},
+		"pod hook fails": {
+			phase: hook.PreDelete,
+			hooks: object.UnstructuredSet{hook3},
+			results: map[object.ObjMetadata]string{
+				id3: "Failed",
+			},
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id3, event.HookStarted, nil),
+				expHookEvent(id3, event.HookFailed, testutil.EqualErrorString("hook failed: Pod exited with code 1")),
+			},
+			expectedLive: object.ObjMetadataSet{id3},
+			isError:      true,
+		},
+		"previous hook object is never deleted": {
+			phase:       hook.PreApply,
+			liveObjs:    []runtime.Object{previous1},
+			hooks:       object.UnstructuredSet{hook1},
+			keepDeleted: true,
+			expectedEvents: []testutil.ExpEvent{
+				expHookEvent(id1, event.HookFailed, testutil.EqualErrorString(
+					"failed to create hook: timed out waiting for previous hook object to be deleted")),
+			},
+			expectedLive: object.ObjMetadataSet{id1},
+			isError:      true,
+		},
+	}
+
+	oldDeleteTimeout, oldPollInterval := hookDeleteTimeout, hookDeletePollInterval
+	hookDeleteTimeout, hookDeletePollInterval = 50*time.Millisecond, 10*time.Millisecond
+	defer func() {
+		hookDeleteTimeout, hookDeletePollInterval = oldDeleteTimeout, oldPollInterval
+	}()
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mapper := testutil.NewFakeRESTMapper(
+				schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+			)
+			dynamicClient := fake.NewSimpleDynamicClient(scheme.Scheme, tc.liveObjs...)
+			if tc.keepDeleted {
+				dynamicClient.PrependReactor("delete", "*", func(clienttesting.Action) (bool, runtime.Object, error) {
+					return true, nil, nil
+				})
+			}
+
+			// Statuses are cached before the task starts, so each hook
+			// completes as soon as it is created.
+			resourceCache := cache.NewResourceCacheMap()
+			for _, obj := range tc.hooks {
+				id := object.UnstructuredToObjMetadata(obj)
+				resourceCache.Put(id, cache.ResourceStatus{
+					Resource:      withResult(obj, tc.results[id]),
+					Status:        tc.statuses[id],
+					StatusMessage: "Job has reached the specified backoff limit",
+				})
+			}
+
+			eventChannel := make(chan event.Event, 3*len(tc.hooks))
+			taskContext := taskrunner.NewTaskContext(eventChannel, resourceCache)
+
+			task := &HookTask{
+				TaskName:      taskName,
+				DynamicClient: dynamicClient,
+				Mapper:        mapper,
+				Phase:         tc.phase,
+				Objects:       tc.hooks,
+				Timeout:       tc.timeout,
+			}
+			task.Start(taskContext)
+			result := <-taskContext.TaskChannel()
+			if tc.isError {
+				assert.Error(t, result.Err)
+			} else {
+				assert.NoError(t, result.Err)
+			}
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			testutil.AssertEqual(t, tc.expectedEvents, testutil.EventsToExpEvents(events))
+
+			for _, id := range tc.expectedLive {
+				obj, err := getObject(dynamicClient, mapper, id)
+				if assert.NoError(t, err, "expected hook %q to exist", id) && !tc.keepDeleted {
+					// the object from the previous run must be replaced
+					assert.NotEqual(t, types.UID("uid-old"), obj.GetUID())
+				}
+			}
+			for _, id := range tc.expectedGone {
+				_, err := getObject(dynamicClient, mapper, id)
+				assert.True(t, apierrors.IsNotFound(err), "expected hook %q to be deleted", id)
+			}
+		})
+	}
+}
+
+func expHookEvent(id object.ObjMetadata, op event.HookEventOperation, err error) testutil.ExpEvent {
+	return testutil.ExpEvent{
+		EventType: event.HookType,
+		HookEvent: &testutil.ExpHookEvent{
+			GroupName:  taskName,
+			Identifier: id,
+			Operation:  op,
+			Error:      err,
+		},
+	}
+}
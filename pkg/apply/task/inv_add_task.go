@@ -4,10 +4,13 @@
 package task
 
 import (
+	"context"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -27,6 +30,9 @@ type InvAddTask struct {
 	InvInfo   inventory.Info
 	Objects   object.UnstructuredSet
 	DryRun    common.DryRunStrategy
+	// Retry defines how inventory writes that fail with transient errors
+	// are retried. The zero value does not retry.
+	Retry retry.Policy
 }
 
 func (i *InvAddTask) Name() string {
@@ -60,7 +66,10 @@ func (i *InvAddTask) Start(taskContext *taskrunner.TaskContext) {
 		}
 		klog.V(4).Infof("merging %d local objects into inventory", len(i.Objects))
 		currentObjs := object.UnstructuredSetToObjMetadataSet(i.Objects)
-		_, err := i.InvClient.Merge(i.InvInfo, currentObjs, i.DryRun)
+		err := i.Retry.Do(context.TODO(), func() error {
+			_, err := i.InvClient.Merge(i.InvInfo, currentObjs, i.DryRun)
+			return err
+		}, retryEventFunc(taskContext, i.Name(), inventoryIdentifier(i.InvInfo), event.InventoryAction, i.Retry))
 		i.sendTaskResult(taskContext, err)
 	}()
 }
@@ -4,8 +4,11 @@
 package task
 
 import (
+	"context"
+
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory"
@@ -20,8 +23,16 @@ type InvSetTask struct {
 	InvInfo       inventory.Info
 	PrevInventory object.ObjMetadataSet
 	DryRun        common.DryRunStrategy
+	// Retry defines how inventory writes that fail with transient errors
+	// are retried. The zero value does not retry.
+	Retry retry.Policy
+
+	// aborted is true if the task was started after the run was aborted.
+	aborted bool
 }
 
+var _ taskrunner.FinalTask = &InvSetTask{}
+
 func (i *InvSetTask) Name() string {
 	return i.TaskName
 }
@@ -51,6 +62,8 @@ func (i *InvSetTask) Identifiers() object.ObjMetadataSet {
 // - Deleted resources (failed)
 // - Abandoned resources (failed)
 //
+// - Applied and deleted resources not reached before an abort
+//
 // Removed objects:
 // - Deleted resources (successful)
 // - Abandoned resources (successful)
@@ -103,6 +116,20 @@ func (i *InvSetTask) Start(taskContext *taskrunner.TaskContext) {
 		klog.V(4).Infof("keep in inventory %d skipped prunes", len(pruneSkips))
 		invObjs = invObjs.Union(pruneSkips)
 
+		// If the run was aborted, and an object was previously stored in the
+		// inventory, but was neither applied nor deleted, because the run did
+		// not reach it, then keep it in the inventory so it can be
+		// applied/pruned next time.
+		if i.aborted {
+			actuatedObjs := object.ObjMetadataSet{}
+			for _, objStatus := range im.ObjectStatuses() {
+				actuatedObjs = append(actuatedObjs, inventory.ObjMetadataFromObjectReference(objStatus.ObjectReference))
+			}
+			unactuatedObjs := i.PrevInventory.Diff(actuatedObjs)
+			klog.V(4).Infof("keep in inventory %d objects not reached before abort", len(unactuatedObjs))
+			invObjs = invObjs.Union(unactuatedObjs)
+		}
+
 		// If an object is abandoned, then remove it from the inventory.
 		abandonedObjects := taskContext.AbandonedObjects()
 		klog.V(4).Infof("remove from inventory %d abandoned objects", len(abandonedObjects))
@@ -115,15 +142,37 @@ func (i *InvSetTask) Start(taskContext *taskrunner.TaskContext) {
 		invObjs = invObjs.Union(invalidObjects)
 
 		klog.V(4).Infof("set inventory %d total objects", len(invObjs))
-		err := i.InvClient.Replace(i.InvInfo, invObjs, i.DryRun)
+		err := i.Retry.Do(context.TODO(), func() error {
+			return replaceInventory(i.InvClient, i.InvInfo, invObjs, im, i.DryRun)
+		}, retryEventFunc(taskContext, i.Name(), inventoryIdentifier(i.InvInfo), event.InventoryAction, i.Retry))
 
 		klog.V(2).Infof("inventory set task completing (name: %q)", i.Name())
 		taskContext.TaskChannel() <- taskrunner.TaskResult{Err: err}
 	}()
 }
 
+// StartOnAbort sets the inventory, like Start, so that the inventory
+// records the changes made before the abort. Objects that were previously
+// stored in the inventory, and were not reached before the abort, are
+// kept.
+func (i *InvSetTask) StartOnAbort(taskContext *taskrunner.TaskContext) {
+	i.aborted = true
+	i.Start(taskContext)
+}
+
 // Cancel is not supported by the InvSetTask.
 func (i *InvSetTask) Cancel(_ *taskrunner.TaskContext) {}
 
 // StatusUpdate is not supported by the InvSetTask.
 func (i *InvSetTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
+
+// replaceInventory replaces the objects stored in the inventory with the
+// passed objects. If the inventory client can record the result of the
+// run, the status of the objects is recorded with them.
+func replaceInventory(invClient inventory.Client, invInfo inventory.Info, objs object.ObjMetadataSet,
+	im *inventory.Manager, dryRun common.DryRunStrategy) error {
+	if resultClient, ok := invClient.(inventory.ResultClient); ok {
+		return resultClient.ReplaceWithResults(invInfo, objs, im.ObjectStatuses(), dryRun)
+	}
+	return invClient.Replace(invInfo, objs, dryRun)
+}
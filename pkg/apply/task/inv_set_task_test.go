@@ -37,6 +37,8 @@ func TestInvSetTask(t *testing.T) {
 		skippedDeletes object.ObjMetadataSet
 		abandonedObjs  object.ObjMetadataSet
 		invalidObjs    object.ObjMetadataSet
+		deletedObjs    object.ObjMetadataSet
+		aborted        bool
 		expectedObjs   object.ObjMetadataSet
 	}{
 		"no apply objs, no prune failures; no inventory": {
@@ -159,6 +161,24 @@ func TestInvSetTask(t *testing.T) {
 			invalidObjs:   object.ObjMetadataSet{idInvalid},
 			expectedObjs:  object.ObjMetadataSet{id3},
 		},
+		"one apply obj, two not reached, three in prev inventory; one inventory": {
+			prevInventory: object.ObjMetadataSet{id1, id2, id3},
+			appliedObjs:   object.ObjMetadataSet{id1},
+			expectedObjs:  object.ObjMetadataSet{id1},
+		},
+		"aborted, one apply obj, two not reached, three in prev inventory; three inventory": {
+			prevInventory: object.ObjMetadataSet{id1, id2, id3},
+			appliedObjs:   object.ObjMetadataSet{id1},
+			aborted:       true,
+			expectedObjs:  object.ObjMetadataSet{id1, id2, id3},
+		},
+		"aborted, one deleted, one skipped delete, two in prev inventory; one inventory": {
+			prevInventory:  object.ObjMetadataSet{id2, id3},
+			deletedObjs:    object.ObjMetadataSet{id2},
+			skippedDeletes: object.ObjMetadataSet{id3},
+			aborted:        true,
+			expectedObjs:   object.ObjMetadataSet{id3},
+		},
 	}
 
 	for name, tc := range tests {
@@ -196,10 +216,17 @@ func TestInvSetTask(t *testing.T) {
 			for _, invalidObj := range tc.invalidObjs {
 				context.AddInvalidObject(invalidObj)
 			}
+			for _, deletedObj := range tc.deletedObjs {
+				im.AddSuccessfulDelete(deletedObj, "unused-uid")
+			}
 			if taskName != task.Name() {
 				t.Errorf("expected task name (%s), got (%s)", taskName, task.Name())
 			}
-			task.Start(context)
+			if tc.aborted {
+				task.StartOnAbort(context)
+			} else {
+				task.Start(context)
+			}
 			result := <-context.TaskChannel()
 			if result.Err != nil {
 				t.Errorf("unexpected error running InvAddTask: %s", result.Err)
@@ -208,6 +235,9 @@ func TestInvSetTask(t *testing.T) {
 			testutil.AssertEqual(t, tc.expectedObjs, actual,
 				"Actual cluster objects (%d) do not match expected cluster objects (%d)",
 				len(actual), len(tc.expectedObjs))
+			// The status of the actuated objects is recorded as the result of the run.
+			testutil.AssertEqual(t, im.ObjectStatuses(), client.Results,
+				"Actual inventory results do not match the object status")
 		})
 	}
 }
@@ -4,11 +4,14 @@
 package task
 
 import (
+	"context"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/filter"
 	"sigs.k8s.io/cli-utils/pkg/apply/prune"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -28,6 +31,12 @@ type PruneTask struct {
 	// True if we are destroying, which deletes the inventory object
 	// as well (possibly) the inventory namespace.
 	Destroy bool
+	// Retry defines how deletes that fail with transient errors are
+	// retried. The zero value does not retry.
+	Retry retry.Policy
+
+	// cancelFunc cancels the deletes of the task.
+	cancelFunc context.CancelFunc
 }
 
 func (p *PruneTask) Name() string {
@@ -50,8 +59,13 @@ func (p *PruneTask) Identifiers() object.ObjMetadataSet {
 // the Run function on the PruneOptions to update
 // the cluster. It will push a TaskResult on the taskChannel
 // to signal to the taskrunner that the task has completed (or failed).
+// If the task is cancelled, the objects that have not been deleted yet
+// are skipped.
 func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
+	var ctx context.Context
+	ctx, p.cancelFunc = context.WithCancel(context.Background())
 	go func() {
+		defer p.cancelFunc()
 		klog.V(2).Infof("prune task starting (name: %q, objects: %d)",
 			p.Name(), len(p.Objects))
 		// Create filter to prevent deletion of currently applied
@@ -60,7 +74,8 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 			CurrentUIDs: taskContext.InventoryManager().AppliedResourceUIDs(),
 		}
 		p.Filters = append(p.Filters, uidFilter)
-		err := p.Pruner.Prune(
+		err := p.Pruner.PruneWithContext(
+			ctx,
 			p.Objects,
 			p.Filters,
 			taskContext,
@@ -69,6 +84,7 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 				DryRunStrategy:    p.DryRunStrategy,
 				PropagationPolicy: p.PropagationPolicy,
 				Destroy:           p.Destroy,
+				Retry:             p.Retry,
 			},
 		)
 		klog.V(2).Infof("prune task completing (name: %q)", p.Name())
@@ -78,8 +94,13 @@ func (p *PruneTask) Start(taskContext *taskrunner.TaskContext) {
 	}()
 }
 
-// Cancel is not supported by the PruneTask.
-func (p *PruneTask) Cancel(_ *taskrunner.TaskContext) {}
+// Cancel stops deleting objects. Deletes in progress are allowed to
+// finish, and the remaining objects are skipped.
+func (p *PruneTask) Cancel(_ *taskrunner.TaskContext) {
+	if p.cancelFunc != nil {
+		p.cancelFunc()
+	}
+}
 
 // StatusUpdate is not supported by the PruneTask.
 func (p *PruneTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
@@ -0,0 +1,49 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// retryEventFunc returns a function for retry.Policy.Do, that sends a
+// RetryEvent for each retry of an operation on the object.
+func retryEventFunc(taskContext *taskrunner.TaskContext, groupName string, id object.ObjMetadata,
+	action event.ResourceAction, policy retry.Policy) func(int, error, time.Duration) {
+	return func(attempt int, err error, delay time.Duration) {
+		klog.V(4).Infof("%s failed, retrying in %s (object: %q, attempt: %d/%d): %v",
+			action, delay, id, attempt, policy.Attempts, err)
+		taskContext.SendEvent(event.Event{
+			Type: event.RetryType,
+			RetryEvent: event.RetryEvent{
+				GroupName:   groupName,
+				Identifier:  id,
+				Action:      action,
+				Attempt:     attempt,
+				MaxAttempts: policy.Attempts,
+				Delay:       delay,
+				Error:       err,
+			},
+		})
+	}
+}
+
+// inventoryIdentifier returns the identifier used in the events of the
+// inventory object. Only the name and namespace are known.
+func inventoryIdentifier(inv inventory.Info) object.ObjMetadata {
+	if inv == nil {
+		return object.NilObjMetadata
+	}
+	return object.ObjMetadata{
+		Namespace: inv.Namespace(),
+		Name:      inv.Name(),
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// RollbackTask reverts the changes made by the preceding apply and prune
+// tasks if any object failed to apply or timed out reconciling, or if the
+// run was aborted. Objects that existed before the run are restored from
+// their snapshot, objects created by the run are deleted, pruned objects
+// are re-created, and the inventory is reset to the previous set of
+// objects. Objects are deleted and restored in reverse apply order, so
+// that dependents are reverted before their dependencies, and re-created
+// in apply order.
+type RollbackTask struct {
+	TaskName string
+
+	DynamicClient dynamic.Interface
+	Mapper        meta.RESTMapper
+	InvClient     inventory.Client
+	InvInfo       inventory.Info
+	// ApplyIds is the set of objects the run attempted to apply, in apply
+	// order.
+	ApplyIds object.ObjMetadataSet
+	// Snapshots is the live state, before the run, of the apply objects
+	// that already existed in the cluster.
+	Snapshots object.UnstructuredSet
+	// PruneObjs is the live state, before the run, of the objects
+	// scheduled to be pruned, in apply order.
+	PruneObjs object.UnstructuredSet
+	// PrevInventory is the set of objects in the inventory before the run.
+	PrevInventory object.ObjMetadataSet
+	DryRun        common.DryRunStrategy
+
+	aborted bool
+}
+
+var _ taskrunner.FinalTask = &RollbackTask{}
+
+func (r *RollbackTask) Name() string {
+	return r.TaskName
+}
+
+func (r *RollbackTask) Action() event.ResourceAction {
+	return event.RollbackAction
+}
+
+func (r *RollbackTask) Identifiers() object.ObjMetadataSet {
+	return r.ApplyIds.Union(object.UnstructuredSetToObjMetadataSet(r.PruneObjs))
+}
+
+// Start checks the TaskContext for apply failures and reconcile timeouts.
+// If there are none, and the run was not aborted, the task completes
+// without doing anything. Otherwise every object actuated by the run is
+// reverted and a RollbackEvent is sent for each of them.
+func (r *RollbackTask) Start(taskContext *taskrunner.TaskContext) {
+	go func() {
+		klog.V(2).Infof("rollback task starting (name: %q)", r.Name())
+		im := taskContext.InventoryManager()
+		if !r.aborted && !RollbackRequired(im) {
+			klog.V(4).Infof("rollback not required (name: %q)", r.Name())
+			r.sendTaskResult(taskContext, nil)
+			return
+		}
+
+		snapshots := make(map[object.ObjMetadata]*unstructured.Unstructured, len(r.Snapshots))
+		for _, obj := range r.Snapshots {
+			snapshots[object.UnstructuredToObjMetadata(obj)] = obj
+		}
+
+		// Objects that were created by this run, but could not be removed,
+		// must stay in the inventory so they can be pruned later.
+		retained := object.ObjMetadataSet{}
+
+		for i := len(r.ApplyIds) - 1; i >= 0; i-- {
+			id := r.ApplyIds[i]
+			if !im.IsSuccessfulApply(id) {
+				continue
+			}
+			if snapshot, found := snapshots[id]; found {
+				err := r.restore(id, snapshot)
+				r.sendEvent(taskContext, id, event.RollbackRestored, err)
+				continue
+			}
+			err := r.remove(id)
+			if err != nil {
+				retained = append(retained, id)
+			}
+			r.sendEvent(taskContext, id, event.RollbackRemoved, err)
+		}
+
+		for _, obj := range r.PruneObjs {
+			id := object.UnstructuredToObjMetadata(obj)
+			if !im.IsSuccessfulDelete(id) {
+				continue
+			}
+			err := r.recreate(id, obj)
+			r.sendEvent(taskContext, id, event.RollbackRecreated, err)
+		}
+
+		invObjs := r.PrevInventory.Union(retained)
+		klog.V(4).Infof("rollback inventory to %d objects", len(invObjs))
+		err := r.InvClient.Replace(r.InvInfo, invObjs, r.DryRun)
+		r.sendTaskResult(taskContext, err)
+	}()
+}
+
+// StartOnAbort reverts every object actuated by the run before the abort.
+func (r *RollbackTask) StartOnAbort(taskContext *taskrunner.TaskContext) {
+	r.aborted = true
+	r.Start(taskContext)
+}
+
+// Cancel is not supported by the RollbackTask.
+func (r *RollbackTask) Cancel(_ *taskrunner.TaskContext) {}
+
+// StatusUpdate is not supported by the RollbackTask.
+func (r *RollbackTask) StatusUpdate(_ *taskrunner.TaskContext, _ object.ObjMetadata) {}
+
+// RollbackRequired returns true if any object failed to apply or timed out
+// while waiting for it to reconcile.
+func RollbackRequired(im *inventory.Manager) bool {
+	return len(im.FailedApplies()) > 0 || len(im.TimeoutReconciles()) > 0
+}
+
+// restore overwrites the live object with its snapshot.
+func (r *RollbackTask) restore(id object.ObjMetadata, snapshot *unstructured.Unstructured) error {
+	if r.DryRun.ClientOrServerDryRun() {
+		return nil
+	}
+	client, err := r.namespacedClient(id)
+	if err != nil {
+		return err
+	}
+	live, err := client.Get(context.TODO(), id.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	obj := snapshot.DeepCopy()
+	obj.SetResourceVersion(live.GetResourceVersion())
+	obj.SetManagedFields(nil)
+	klog.V(4).Infof("restoring object (object: %q)", id)
+	_, err = client.Update(context.TODO(), obj, metav1.UpdateOptions{})
+	return err
+}
+
+// remove deletes an object that did not exist before the run.
+func (r *RollbackTask) remove(id object.ObjMetadata) error {
+	if r.DryRun.ClientOrServerDryRun() {
+		return nil
+	}
+	client, err := r.namespacedClient(id)
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("removing object (object: %q)", id)
+	err = client.Delete(context.TODO(), id.Name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// recreate creates a previously pruned object from its snapshot.
+func (r *RollbackTask) recreate(id object.ObjMetadata, snapshot *unstructured.Unstructured) error {
+	if r.DryRun.ClientOrServerDryRun() {
+		return nil
+	}
+	client, err := r.namespacedClient(id)
+	if err != nil {
+		return err
+	}
+	obj := snapshot.DeepCopy()
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetDeletionGracePeriodSeconds(nil)
+	obj.SetManagedFields(nil)
+	unstructured.RemoveNestedField(obj.Object, "status")
+	klog.V(4).Infof("re-creating object (object: %q)", id)
+	_, err = client.Create(context.TODO(), obj, metav1.CreateOptions{})
+	return err
+}
+
+func (r *RollbackTask) namespacedClient(id object.ObjMetadata) (dynamic.ResourceInterface, error) {
+	mapping, err := r.Mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return r.DynamicClient.Resource(mapping.Resource).Namespace(id.Namespace), nil
+}
+
+func (r *RollbackTask) sendEvent(taskContext *taskrunner.TaskContext, id object.ObjMetadata,
+	op event.RollbackEventOperation, err error) {
+	if err != nil {
+		if klog.V(4).Enabled() {
+			klog.Errorf("rollback failed (object: %q): %v", id, err)
+		}
+		op = event.RollbackUnspecified
+	}
+	taskContext.SendEvent(event.Event{
+		Type: event.RollbackType,
+		RollbackEvent: event.RollbackEvent{
+			GroupName:  r.Name(),
+			Identifier: id,
+			Operation:  op,
+			Error:      err,
+		},
+	})
+}
+
+func (r *RollbackTask) sendTaskResult(taskContext *taskrunner.TaskContext, err error) {
+	klog.V(2).Infof("rollback task completing (name: %q)", r.Name())
+	taskContext.TaskChannel() <- taskrunner.TaskResult{
+		Err: err,
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/apply/taskrunner"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+func TestRollbackTask(t *testing.T) {
+	id1 := object.UnstructuredToObjMetadata(obj1)
+	id2 := object.UnstructuredToObjMetadata(obj2)
+	id3 := object.UnstructuredToObjMetadata(obj3)
+
+	// obj1 existed before the run, with a different label.
+	snapshot1 := obj1.DeepCopy()
+	snapshot1.SetLabels(map[string]string{"version": "old"})
+	live1 := obj1.DeepCopy()
+	live1.SetLabels(map[string]string{"version": "new"})
+
+	tests := map[string]struct {
+		liveObjs          []runtime.Object
+		applyIds          object.ObjMetadataSet
+		snapshots         object.UnstructuredSet
+		pruneObjs         object.UnstructuredSet
+		prevInventory     object.ObjMetadataSet
+		successfulApplies object.ObjMetadataSet
+		failedApplies     object.ObjMetadataSet
+		timeoutReconciles object.ObjMetadataSet
+		successfulDeletes object.ObjMetadataSet
+		aborted           bool
+		expectedEvents    []testutil.ExpEvent
+		expectedInventory object.ObjMetadataSet
+		expectedLive      object.ObjMetadataSet
+		expectedGone      object.ObjMetadataSet
+		// expectedVersion is the expected "version" label of obj1
+		expectedVersion string
+	}{
+		"no failures; no rollback": {
+			liveObjs:          []runtime.Object{live1, obj2},
+			applyIds:          object.ObjMetadataSet{id1, id2},
+			snapshots:         object.UnstructuredSet{snapshot1},
+			prevInventory:     object.ObjMetadataSet{id1},
+			successfulApplies: object.ObjMetadataSet{id1, id2},
+			expectedEvents:    []testutil.ExpEvent{},
+			expectedInventory: object.ObjMetadataSet{id1, id2},
+			expectedLive:      object.ObjMetadataSet{id1, id2},
+			expectedVersion:   "new",
+		},
+		"aborted; revert applied objects in reverse order": {
+			liveObjs:          []runtime.Object{live1, obj2},
+			applyIds:          object.ObjMetadataSet{id1, id2, id3},
+			snapshots:         object.UnstructuredSet{snapshot1},
+			prevInventory:     object.ObjMetadataSet{id1},
+			successfulApplies: object.ObjMetadataSet{id1, id2},
+			aborted:           true,
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id2,
+						Operation:  event.RollbackRemoved,
+					},
+				},
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id1,
+						Operation:  event.RollbackRestored,
+					},
+				},
+			},
+			expectedInventory: object.ObjMetadataSet{id1},
+			expectedLive:      object.ObjMetadataSet{id1},
+			expectedGone:      object.ObjMetadataSet{id2},
+			expectedVersion:   "old",
+		},
+		"apply failure; restore existing, remove created": {
+			liveObjs:          []runtime.Object{live1, obj2},
+			applyIds:          object.ObjMetadataSet{id1, id2, id3},
+			snapshots:         object.UnstructuredSet{snapshot1},
+			prevInventory:     object.ObjMetadataSet{id1},
+			successfulApplies: object.ObjMetadataSet{id1, id2},
+			failedApplies:     object.ObjMetadataSet{id3},
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id2,
+						Operation:  event.RollbackRemoved,
+					},
+				},
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id1,
+						Operation:  event.RollbackRestored,
+					},
+				},
+			},
+			expectedInventory: object.ObjMetadataSet{id1},
+			expectedLive:      object.ObjMetadataSet{id1},
+			expectedGone:      object.ObjMetadataSet{id2},
+			expectedVersion:   "old",
+		},
+		"reconcile timeout; recreate pruned": {
+			liveObjs:          []runtime.Object{live1},
+			applyIds:          object.ObjMetadataSet{id1},
+			snapshots:         object.UnstructuredSet{snapshot1},
+			pruneObjs:         object.UnstructuredSet{obj3},
+			prevInventory:     object.ObjMetadataSet{id1, id3},
+			successfulApplies: object.ObjMetadataSet{id1},
+			timeoutReconciles: object.ObjMetadataSet{id1},
+			successfulDeletes: object.ObjMetadataSet{id3},
+			expectedEvents: []testutil.ExpEvent{
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id1,
+						Operation:  event.RollbackRestored,
+					},
+				},
+				{
+					EventType: event.RollbackType,
+					RollbackEvent: &testutil.ExpRollbackEvent{
+						GroupName:  taskName,
+						Identifier: id3,
+						Operation:  event.RollbackRecreated,
+					},
+				},
+			},
+			expectedInventory: object.ObjMetadataSet{id1, id3},
+			expectedLive:      object.ObjMetadataSet{id1, id3},
+			expectedVersion:   "old",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			mapper := testutil.NewFakeRESTMapper(
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+				schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"},
+				schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+			)
+			dynamicClient := fake.NewSimpleDynamicClient(scheme.Scheme, tc.liveObjs...)
+			invClient := inventory.NewFakeClient(tc.prevInventory.Union(tc.successfulApplies))
+
+			eventChannel := make(chan event.Event, len(tc.applyIds)+len(tc.pruneObjs))
+			taskContext := taskrunner.NewTaskContext(eventChannel, cache.NewResourceCacheMap())
+			im := taskContext.InventoryManager()
+			for _, id := range tc.successfulApplies {
+				im.AddSuccessfulApply(id, "unused-uid", int64(0))
+			}
+			for _, id := range tc.failedApplies {
+				im.AddFailedApply(id)
+			}
+			for _, id := range tc.timeoutReconciles {
+				err := im.SetTimeoutReconcile(id)
+				assert.NoError(t, err)
+			}
+			for _, id := range tc.successfulDeletes {
+				im.AddSuccessfulDelete(id, "unused-uid")
+			}
+
+			task := &RollbackTask{
+				TaskName:      taskName,
+				DynamicClient: dynamicClient,
+				Mapper:        mapper,
+				InvClient:     invClient,
+				InvInfo:       localInv,
+				ApplyIds:      tc.applyIds,
+				Snapshots:     tc.snapshots,
+				PruneObjs:     tc.pruneObjs,
+				PrevInventory: tc.prevInventory,
+			}
+			if tc.aborted {
+				task.StartOnAbort(taskContext)
+			} else {
+				task.Start(taskContext)
+			}
+			result := <-taskContext.TaskChannel()
+			assert.NoError(t, result.Err)
+			close(eventChannel)
+
+			var events []event.Event
+			for e := range eventChannel {
+				events = append(events, e)
+			}
+			testutil.AssertEqual(t, tc.expectedEvents, testutil.EventsToExpEvents(events))
+
+			invObjs, _ := invClient.GetClusterObjs(localInv)
+			testutil.AssertEqual(t, tc.expectedInventory, invObjs)
+
+			for _, id := range tc.expectedLive {
+				_, err := getObject(dynamicClient, mapper, id)
+				assert.NoError(t, err, "expected object %q to exist", id)
+			}
+			for _, id := range tc.expectedGone {
+				_, err := getObject(dynamicClient, mapper, id)
+				assert.True(t, apierrors.IsNotFound(err), "expected object %q to be removed", id)
+			}
+			obj, err := getObject(dynamicClient, mapper, id1)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedVersion, obj.GetLabels()["version"])
+		})
+	}
+}
+
+func getObject(client *fake.FakeDynamicClient, mapper meta.RESTMapper, id object.ObjMetadata) (*unstructured.Unstructured, error) {
+	mapping, err := mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return nil, err
+	}
+	return client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Get(context.TODO(), id.Name, metav1.GetOptions{})
+}
@@ -4,6 +4,8 @@
 package taskrunner
 
 import (
+	"sync"
+
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
@@ -25,7 +27,10 @@ func NewTaskContext(eventChannel chan event.Event, resourceCache cache.ResourceC
 
 // TaskContext defines a context that is passed between all
 // the tasks that is in a taskqueue.
+// TaskContext is thread-safe, so tasks may update it from multiple
+// goroutines.
 type TaskContext struct {
+	mu               sync.RWMutex
 	taskChannel      chan TaskResult
 	eventChannel     chan event.Event
 	resourceCache    cache.ResourceCache
@@ -58,32 +63,44 @@ func (tc *TaskContext) SendEvent(e event.Event) {
 
 // IsAbandonedObject returns true if the object is abandoned
 func (tc *TaskContext) IsAbandonedObject(id object.ObjMetadata) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	_, found := tc.abandonedObjects[id]
 	return found
 }
 
 // AddAbandonedObject registers that the object is abandoned
 func (tc *TaskContext) AddAbandonedObject(id object.ObjMetadata) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	tc.abandonedObjects[id] = struct{}{}
 }
 
 // AbandonedObjects returns all the abandoned objects
 func (tc *TaskContext) AbandonedObjects() object.ObjMetadataSet {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return object.ObjMetadataSetFromMap(tc.abandonedObjects)
 }
 
 // IsInvalidObject returns true if the object is abandoned
 func (tc *TaskContext) IsInvalidObject(id object.ObjMetadata) bool {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	_, found := tc.invalidObjects[id]
 	return found
 }
 
 // AddInvalidObject registers that the object is abandoned
 func (tc *TaskContext) AddInvalidObject(id object.ObjMetadata) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
 	tc.invalidObjects[id] = struct{}{}
 }
 
 // InvalidObjects returns all the abandoned objects
 func (tc *TaskContext) InvalidObjects() object.ObjMetadataSet {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	return object.ObjMetadataSetFromMap(tc.invalidObjects)
 }
@@ -0,0 +1,272 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// FinalizerRemoval configures a WaitTask, waiting for objects to be
+// deleted, to remove finalizers from the objects that are still
+// terminating after a grace period. Only the named finalizers are removed,
+// so that the finalizers of other controllers still block the deletion.
+type FinalizerRemoval struct {
+	// Client is used to remove the finalizers.
+	Client dynamic.Interface
+	// Finalizers are the names of the finalizers that may be removed.
+	Finalizers []string
+	// GracePeriod is how long after the deletion of an object was requested
+	// its finalizers are removed.
+	GracePeriod time.Duration
+}
+
+// removable returns the finalizers of the object that may be removed.
+func (fr *FinalizerRemoval) removable(obj *unstructured.Unstructured) []string {
+	var names []string
+	for _, f := range blockingFinalizers(obj) {
+		if stringsContain(fr.Finalizers, f) {
+			names = append(names, f)
+		}
+	}
+	return names
+}
+
+// delay returns how long to wait before removing the finalizers of the
+// terminating object.
+func (fr *FinalizerRemoval) delay(obj *unstructured.Unstructured) time.Duration {
+	deleted := obj.GetDeletionTimestamp()
+	if deleted == nil {
+		return fr.GracePeriod
+	}
+	d := time.Until(deleted.Add(fr.GracePeriod))
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// remove removes the removable finalizers from the object, and returns
+// the removed finalizers. The update fails if the finalizers were changed
+// since the object was read, so that finalizers added in the meantime are
+// not lost.
+func (fr *FinalizerRemoval) remove(ctx context.Context, mapper meta.RESTMapper,
+	obj *unstructured.Unstructured) ([]string, error) {
+	removed := fr.removable(obj)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	client := fr.Client.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	klog.V(4).Infof("removing finalizers %v from %v", removed, object.UnstructuredToObjMetadata(obj))
+
+	finalizers := obj.GetFinalizers()
+	if remaining := withoutStrings(finalizers, removed); len(remaining) != len(finalizers) {
+		patch, err := json.Marshal([]map[string]interface{}{
+			{"op": "test", "path": "/metadata/finalizers", "value": finalizers},
+			{"op": "replace", "path": "/metadata/finalizers", "value": remaining},
+		})
+		if err != nil {
+			return nil, err
+		}
+		obj, err = client.Patch(ctx, obj.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+	}
+
+	specFinalizers := namespaceFinalizers(obj)
+	if remaining := withoutStrings(specFinalizers, removed); len(remaining) != len(specFinalizers) {
+		// The finalizers of a namespace spec can only be changed with the
+		// finalize subresource. The resourceVersion of the object prevents
+		// overwriting changes.
+		obj = obj.DeepCopy()
+		if err := unstructured.SetNestedStringSlice(obj.Object, remaining, "spec", "finalizers"); err != nil {
+			return nil, err
+		}
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{}, "finalize")
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+	}
+	return removed, nil
+}
+
+// blockingFinalizers returns the finalizers of the object, if its deletion
+// was requested, but is blocked by these finalizers. For namespaces, this
+// includes the finalizers of the spec, which block the deletion until the
+// content of the namespace is deleted.
+func blockingFinalizers(obj *unstructured.Unstructured) []string {
+	if obj == nil || obj.GetDeletionTimestamp() == nil {
+		return nil
+	}
+	return append(obj.GetFinalizers(), namespaceFinalizers(obj)...)
+}
+
+// namespaceFinalizers returns the finalizers of the spec, if the object is
+// a namespace.
+func namespaceFinalizers(obj *unstructured.Unstructured) []string {
+	if obj.GroupVersionKind().GroupKind() != namespaceGK {
+		return nil
+	}
+	finalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers")
+	return finalizers
+}
+
+// blockedReason returns the reason of the events for an object whose
+// deletion is blocked by the finalizers.
+func blockedReason(finalizers []string) string {
+	if len(finalizers) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("deletion blocked by finalizers: %s", strings.Join(finalizers, ", "))
+}
+
+// removedReason returns the reason of the events for an object whose
+// finalizers were removed.
+func removedReason(finalizers []string) string {
+	return fmt.Sprintf("removed finalizers: %s", strings.Join(finalizers, ", "))
+}
+
+// withoutStrings returns the list without the removed strings. The result
+// is never nil, so that it can be used to replace a list.
+func withoutStrings(list, removed []string) []string {
+	result := []string{}
+	for _, s := range list {
+		if !stringsContain(removed, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func ignoreNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		// deleted in the meantime
+		return nil
+	}
+	return err
+}
+
+func stringsContain(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBlocked records whether the deletion of the pending object is
+// blocked by finalizers, schedules the removal of its finalizers, and
+// returns the reason of the pending event. Only applies when waiting for
+// objects to be deleted. The pending set must be write locked.
+func (w *WaitTask) checkBlocked(taskContext *TaskContext, id object.ObjMetadata) string {
+	if w.Condition != AllNotFound {
+		return ""
+	}
+	obj := taskContext.ResourceCache().Get(id).Resource
+	reason := blockedReason(blockingFinalizers(obj))
+	if reason == "" {
+		delete(w.blocked, id)
+		return ""
+	}
+	if w.blocked == nil {
+		w.blocked = make(map[object.ObjMetadata]string)
+	}
+	w.blocked[id] = reason
+	w.scheduleFinalizerRemoval(taskContext, id, obj)
+	return reason
+}
+
+// scheduleFinalizerRemoval starts a timer to remove the finalizers of the
+// terminating object after the grace period, unless already scheduled.
+// A timer is required, because the status of a terminating object may not
+// change again. The pending set must be write locked.
+func (w *WaitTask) scheduleFinalizerRemoval(taskContext *TaskContext, id object.ObjMetadata,
+	obj *unstructured.Unstructured) {
+	if w.FinalizerRemoval == nil || w.ctx == nil || w.removalsStopped {
+		return
+	}
+	if _, found := w.removals[id]; found {
+		return
+	}
+	if len(w.FinalizerRemoval.removable(obj)) == 0 {
+		return
+	}
+	if w.removals == nil {
+		w.removals = make(map[object.ObjMetadata]*time.Timer)
+	}
+	delay := w.FinalizerRemoval.delay(obj)
+	klog.V(3).Infof("scheduling finalizer removal in %v: %v", delay, id)
+	w.removalsWG.Add(1)
+	w.removals[id] = time.AfterFunc(delay, func() {
+		defer w.removalsWG.Done()
+		w.removeFinalizers(taskContext, id)
+	})
+}
+
+// removeFinalizers removes the finalizers of the object, if it is still
+// pending, and sends a pending event with the removed finalizers. If the
+// removal fails, it is scheduled again on the next status update.
+// The lock is not held while the object is updated, so the object is
+// checked to still be pending before and after.
+func (w *WaitTask) removeFinalizers(taskContext *TaskContext, id object.ObjMetadata) {
+	w.mu.Lock()
+	if !w.pending.Contains(id) || w.ctx.Err() != nil {
+		w.mu.Unlock()
+		return
+	}
+	obj := taskContext.ResourceCache().Get(id).Resource
+	w.mu.Unlock()
+	if obj == nil {
+		return
+	}
+
+	removed, err := w.FinalizerRemoval.remove(w.ctx, w.Mapper, obj)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err != nil {
+		klog.Errorf("Failed to remove finalizers (object: %v): %v", id, err)
+		delete(w.removals, id)
+		return
+	}
+	if len(removed) > 0 && w.pending.Contains(id) {
+		w.sendReasonEvent(taskContext, id, event.ReconcilePending, removedReason(removed))
+	}
+}
+
+// stopFinalizerRemovals stops the scheduled finalizer removals, and waits
+// for the running removals to finish, so that they don't send events after
+// the task completed.
+func (w *WaitTask) stopFinalizerRemovals() {
+	w.mu.Lock()
+	w.removalsStopped = true
+	for _, timer := range w.removals {
+		if timer.Stop() {
+			// never started
+			w.removalsWG.Done()
+		}
+	}
+	w.mu.Unlock()
+	w.removalsWG.Wait()
+}
@@ -0,0 +1,308 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package taskrunner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/cli-utils/pkg/apply/cache"
+	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	"sigs.k8s.io/cli-utils/pkg/kstatus/status"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+var testNamespaceYAML = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: test-namespace
+  uid: ns-uid
+  finalizers:
+  - example.com/cleanup
+  - example.com/keep
+spec:
+  finalizers:
+  - kubernetes
+`
+
+func TestWaitTask_BlockedByFinalizers(t *testing.T) {
+	testDeploymentID := testutil.ToIdentifier(t, testDeployment1YAML)
+	testDeployment := testutil.Unstructured(t, testDeployment1YAML)
+	deleted := metav1.NewTime(time.Now())
+	testDeployment.SetDeletionTimestamp(&deleted)
+	testDeployment.SetFinalizers([]string{"example.com/a"})
+
+	taskName := "wait-0"
+	task := NewWaitTask(taskName, object.ObjMetadataSet{testDeploymentID}, AllNotFound,
+		2*time.Second, testutil.NewFakeRESTMapper())
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	taskContext.InventoryManager().AddSuccessfulDelete(testDeploymentID, testDeployment.GetUID())
+	resourceCache.Put(testDeploymentID, cache.ResourceStatus{
+		Resource: testDeployment,
+		Status:   status.InProgressStatus,
+	})
+
+	// run task async, to let the test collect events
+	go func() {
+		task.Start(taskContext)
+		// another finalizer was added
+		updated := testDeployment.DeepCopy()
+		updated.SetFinalizers([]string{"example.com/a", "example.com/b"})
+		resourceCache.Put(testDeploymentID, cache.ResourceStatus{
+			Resource: updated,
+			Status:   status.InProgressStatus,
+		})
+		task.StatusUpdate(taskContext, testDeploymentID)
+		// no change, so no event
+		task.StatusUpdate(taskContext, testDeploymentID)
+	}()
+
+	receivedEvents := collectEvents(t, taskContext, nil)
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeploymentID,
+				Operation:  event.ReconcilePending,
+				Reason:     "deletion blocked by finalizers: example.com/a",
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeploymentID,
+				Operation:  event.ReconcilePending,
+				Reason:     "deletion blocked by finalizers: example.com/a, example.com/b",
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testDeploymentID,
+				Operation:  event.ReconcileTimeout,
+				Reason:     "deletion blocked by finalizers: example.com/a, example.com/b",
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents)
+}
+
+func TestWaitTask_RemoveFinalizers(t *testing.T) {
+	testNamespaceID := testutil.ToIdentifier(t, testNamespaceYAML)
+	testNamespace := testutil.Unstructured(t, testNamespaceYAML)
+	deleted := metav1.NewTime(time.Now().Add(-time.Minute))
+	testNamespace.SetDeletionTimestamp(&deleted)
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, testNamespace.DeepCopy())
+
+	taskName := "wait-0"
+	task := NewWaitTask(taskName, object.ObjMetadataSet{testNamespaceID}, AllNotFound,
+		5*time.Second, testutil.NewFakeRESTMapper(
+			schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"},
+		))
+	task.FinalizerRemoval = &FinalizerRemoval{
+		Client:      client,
+		Finalizers:  []string{"example.com/cleanup", "kubernetes"},
+		GracePeriod: 30 * time.Second,
+	}
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	taskContext.InventoryManager().AddSuccessfulDelete(testNamespaceID, testNamespace.GetUID())
+	resourceCache.Put(testNamespaceID, cache.ResourceStatus{
+		Resource: testNamespace,
+		Status:   status.InProgressStatus,
+	})
+
+	go task.Start(taskContext)
+
+	receivedEvents := collectEvents(t, taskContext, func(e event.Event) {
+		if e.WaitEvent.Reason == "removed finalizers: example.com/cleanup, kubernetes" {
+			// the remaining finalizer was removed by its controller
+			go func() {
+				resourceCache.Put(testNamespaceID, cache.ResourceStatus{
+					Status: status.NotFoundStatus,
+				})
+				task.StatusUpdate(taskContext, testNamespaceID)
+			}()
+		}
+	})
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testNamespaceID,
+				Operation:  event.ReconcilePending,
+				Reason:     "deletion blocked by finalizers: example.com/cleanup, example.com/keep, kubernetes",
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testNamespaceID,
+				Operation:  event.ReconcilePending,
+				Reason:     "removed finalizers: example.com/cleanup, kubernetes",
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testNamespaceID,
+				Operation:  event.Reconciled,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents)
+
+	obj, err := client.Resource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}).
+		Get(context.Background(), testNamespace.GetName(), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com/keep"}, obj.GetFinalizers())
+	specFinalizers, _, err := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers")
+	require.NoError(t, err)
+	assert.Empty(t, specFinalizers)
+}
+
+func TestWaitTask_RemoveFinalizersReconciledMeanwhile(t *testing.T) {
+	testNamespaceID := testutil.ToIdentifier(t, testNamespaceYAML)
+	testNamespace := testutil.Unstructured(t, testNamespaceYAML)
+	deleted := metav1.NewTime(time.Now().Add(-time.Minute))
+	testNamespace.SetDeletionTimestamp(&deleted)
+
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme, testNamespace.DeepCopy())
+
+	taskName := "wait-0"
+	task := NewWaitTask(taskName, object.ObjMetadataSet{testNamespaceID}, AllNotFound,
+		5*time.Second, testutil.NewFakeRESTMapper(
+			schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Namespace"},
+		))
+	task.FinalizerRemoval = &FinalizerRemoval{
+		Client:      client,
+		Finalizers:  []string{"example.com/cleanup", "kubernetes"},
+		GracePeriod: 30 * time.Second,
+	}
+
+	eventChannel := make(chan event.Event)
+	resourceCache := cache.NewResourceCacheMap()
+	taskContext := NewTaskContext(eventChannel, resourceCache)
+	defer close(eventChannel)
+
+	taskContext.InventoryManager().AddSuccessfulDelete(testNamespaceID, testNamespace.GetUID())
+	resourceCache.Put(testNamespaceID, cache.ResourceStatus{
+		Resource: testNamespace,
+		Status:   status.InProgressStatus,
+	})
+
+	// The object is deleted while its finalizers are being removed.
+	client.PrependReactor("patch", "namespaces", func(clienttesting.Action) (bool, runtime.Object, error) {
+		resourceCache.Put(testNamespaceID, cache.ResourceStatus{
+			Status: status.NotFoundStatus,
+		})
+		task.StatusUpdate(taskContext, testNamespaceID)
+		return false, nil, nil
+	})
+
+	go task.Start(taskContext)
+
+	receivedEvents := collectEvents(t, taskContext, nil)
+
+	expectedEvents := []event.Event{
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testNamespaceID,
+				Operation:  event.ReconcilePending,
+				Reason:     "deletion blocked by finalizers: example.com/cleanup, example.com/keep, kubernetes",
+			},
+		},
+		{
+			Type: event.WaitType,
+			WaitEvent: event.WaitEvent{
+				GroupName:  taskName,
+				Identifier: testNamespaceID,
+				Operation:  event.Reconciled,
+			},
+		},
+	}
+	testutil.AssertEqual(t, expectedEvents, receivedEvents)
+}
+
+func TestFinalizerRemoval_Delay(t *testing.T) {
+	fr := &FinalizerRemoval{GracePeriod: time.Minute}
+
+	testCases := map[string]struct {
+		deleted  time.Time
+		expected time.Duration
+	}{
+		"grace period expired": {
+			deleted:  time.Now().Add(-2 * time.Minute),
+			expected: 0,
+		},
+		"grace period remaining": {
+			deleted:  time.Now().Add(-30 * time.Second),
+			expected: 30 * time.Second,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			obj := testutil.Unstructured(t, testDeployment1YAML)
+			deleted := metav1.NewTime(tc.deleted)
+			obj.SetDeletionTimestamp(&deleted)
+			assert.InDelta(t, tc.expected, fr.delay(obj), float64(2*time.Second))
+		})
+	}
+}
+
+// collectEvents returns the events sent by the task until it completes.
+// The callback, if not nil, is called for each event.
+func collectEvents(t *testing.T, taskContext *TaskContext, callback func(event.Event)) []event.Event {
+	timer := time.NewTimer(10 * time.Second)
+	defer timer.Stop()
+	var receivedEvents []event.Event
+	for {
+		select {
+		case e := <-taskContext.EventChannel():
+			receivedEvents = append(receivedEvents, e)
+			if callback != nil {
+				callback(e)
+			}
+		case res := <-taskContext.TaskChannel():
+			assert.NoError(t, res.Err)
+			return receivedEvents
+		case <-timer.C:
+			t.Fatalf("timed out waiting for TaskResult")
+			return nil
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"k8s.io/klog/v2"
 	"sigs.k8s.io/cli-utils/pkg/apply/cache"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
 	"sigs.k8s.io/cli-utils/pkg/apply/poller"
@@ -37,6 +38,11 @@ type TaskStatusRunner struct {
 type Options struct {
 	PollInterval     time.Duration
 	EmitStatusEvents bool
+	// TaskFinished, if not nil, is called after each task has completed
+	// or failed, before the next task is started. If it returns an error,
+	// the run is aborted: the remaining final tasks are run, and the
+	// error is returned.
+	TaskFinished func(Task, *TaskContext) error
 }
 
 // Run executes the tasks in the taskqueue, with the statusPoller running in the
@@ -82,10 +88,9 @@ func (tsr *TaskStatusRunner) Run(
 	}
 
 	// abort is used to signal that something has failed, and
-	// the task processing should end as soon as is possible. Only
-	// wait tasks can be interrupted, so for all other tasks we need
-	// to wait for the currently running one to finish before we can
-	// exit.
+	// the task processing should end as soon as is possible. The
+	// running task is cancelled, but we need to wait for it to
+	// finish, and to run the final tasks, before we can exit.
 	abort := false
 	var abortReason error
 
@@ -158,21 +163,36 @@ func (tsr *TaskStatusRunner) Run(
 		// finish, we exit.
 		// If everything is ok, we fetch and start the next task.
 		case msg := <-taskContext.TaskChannel():
-			taskContext.SendEvent(event.Event{
-				Type: event.ActionGroupType,
-				ActionGroupEvent: event.ActionGroupEvent{
-					GroupName: currentTask.Name(),
-					Action:    currentTask.Action(),
-					Type:      event.Finished,
-				},
-			})
+			// GroupedTasks send their own ActionGroup events.
+			if _, grouped := currentTask.(GroupedTask); !grouped {
+				taskContext.SendEvent(event.Event{
+					Type: event.ActionGroupType,
+					ActionGroupEvent: event.ActionGroupEvent{
+						GroupName: currentTask.Name(),
+						Action:    currentTask.Action(),
+						Type:      event.Finished,
+					},
+				})
+			}
+			if opts.TaskFinished != nil {
+				if err := opts.TaskFinished(currentTask, taskContext); err != nil && !abort {
+					abort = true
+					abortReason = err
+				}
+			}
 			if msg.Err != nil {
 				return complete(
 					fmt.Errorf("task failed (action: %q, name: %q): %w",
 						currentTask.Action(), currentTask.Name(), msg.Err))
 			}
 			if abort {
-				return complete(abortReason)
+				// Run the final tasks, so the changes made before the
+				// abort are recorded.
+				currentTask, done = nextFinalTask(taskQueue, taskContext)
+				if done {
+					return complete(abortReason)
+				}
+				continue
 			}
 			currentTask, done = nextTask(taskQueue, taskContext)
 			// If there are no more tasks, we are done. So just
@@ -182,7 +202,7 @@ func (tsr *TaskStatusRunner) Run(
 			}
 		// The doneCh will be closed if the passed in context is cancelled.
 		// If so, we just set the abort flag and wait for the currently running
-		// task to complete before we exit.
+		// task and the final tasks to complete before we exit.
 		case <-doneCh:
 			doneCh = nil // Set doneCh to nil so we don't enter a busy loop.
 			abort = true
@@ -207,6 +227,41 @@ func nextTask(taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
 		return nil, true
 	}
 
+	sendStartedEvent(tsk, taskContext)
+	tsk.Start(taskContext)
+
+	return tsk, false
+}
+
+// nextFinalTask skips the tasks in the taskQueue up to the next FinalTask,
+// and starts it after an abort. If there is no FinalTask left in the
+// taskQueue, the second return value will be true.
+func nextFinalTask(taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
+	for {
+		var tsk Task
+		select {
+		case t := <-taskQueue:
+			tsk = t
+		default:
+			return nil, true
+		}
+		ft, ok := tsk.(FinalTask)
+		if !ok {
+			klog.V(4).Infof("task skipped after abort (name: %q)", tsk.Name())
+			continue
+		}
+		sendStartedEvent(ft, taskContext)
+		ft.StartOnAbort(taskContext)
+		return ft, false
+	}
+}
+
+// sendStartedEvent sends the ActionGroup Started event of the task, unless
+// it is a GroupedTask, which sends its own ActionGroup events.
+func sendStartedEvent(tsk Task, taskContext *TaskContext) {
+	if _, grouped := tsk.(GroupedTask); grouped {
+		return
+	}
 	taskContext.SendEvent(event.Event{
 		Type: event.ActionGroupType,
 		ActionGroupEvent: event.ActionGroupEvent{
@@ -215,10 +270,6 @@ func nextTask(taskQueue chan Task, taskContext *TaskContext) (Task, bool) {
 			Type:      event.Started,
 		},
 	})
-
-	tsk.Start(taskContext)
-
-	return tsk, false
 }
 
 // TaskResult is the type returned from tasks once they have completed
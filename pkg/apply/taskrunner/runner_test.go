@@ -413,6 +413,32 @@ func TestBaseRunnerCancellation(t *testing.T) {
 				event.ActionGroupType,
 			},
 		},
+		"final task runs after cancellation": {
+			tasks: []Task{
+				&fakeApplyTask{
+					resultEvent: event.Event{
+						Type: event.ApplyType,
+					},
+					duration: 4 * time.Second,
+				},
+				&fakeApplyTask{
+					resultEvent: event.Event{
+						Type: event.PruneType,
+					},
+					duration: 2 * time.Second,
+				},
+				&fakeFinalTask{},
+			},
+			contextTimeout: 2 * time.Second,
+			expectedError:  context.DeadlineExceeded,
+			expectedEventTypes: []event.Type{
+				event.ActionGroupType,
+				event.ApplyType,
+				event.ActionGroupType,
+				event.ActionGroupType, // final task started
+				event.ActionGroupType, // final task finished
+			},
+		},
 		"error from status poller while wait task is running": {
 			tasks: []Task{
 				NewWaitTask("wait", object.ObjMetadataSet{depID}, AllCurrent,
@@ -540,6 +566,37 @@ func (f *fakeApplyTask) Cancel(_ *TaskContext) {}
 
 func (f *fakeApplyTask) StatusUpdate(_ *TaskContext, _ object.ObjMetadata) {}
 
+// fakeFinalTask completes immediately, whether the runner aborted or not.
+type fakeFinalTask struct{}
+
+var _ FinalTask = &fakeFinalTask{}
+
+func (f *fakeFinalTask) Name() string {
+	return "final"
+}
+
+func (f *fakeFinalTask) Action() event.ResourceAction {
+	return event.InventoryAction
+}
+
+func (f *fakeFinalTask) Identifiers() object.ObjMetadataSet {
+	return object.ObjMetadataSet{}
+}
+
+func (f *fakeFinalTask) Start(taskContext *TaskContext) {
+	go func() {
+		taskContext.TaskChannel() <- TaskResult{}
+	}()
+}
+
+func (f *fakeFinalTask) StartOnAbort(taskContext *TaskContext) {
+	f.Start(taskContext)
+}
+
+func (f *fakeFinalTask) Cancel(_ *TaskContext) {}
+
+func (f *fakeFinalTask) StatusUpdate(_ *TaskContext, _ object.ObjMetadata) {}
+
 type fakePoller struct {
 	start  chan struct{}
 	events []pollevent.Event
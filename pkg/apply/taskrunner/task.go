@@ -19,7 +19,8 @@ import (
 )
 
 var (
-	crdGK = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	crdGK       = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	namespaceGK = schema.GroupKind{Group: "", Kind: "Namespace"}
 )
 
 // Task is the interface that must be implemented by
@@ -33,6 +34,30 @@ type Task interface {
 	Cancel(*TaskContext)
 }
 
+// GroupedTask is a Task that reports its progress as multiple action
+// groups. The TaskStatusRunner does not send the ActionGroup events for
+// a GroupedTask, so the task must send the Started and Finished events
+// for each of its action groups itself.
+type GroupedTask interface {
+	Task
+	ActionGroups() []event.ActionGroup
+}
+
+// FinalTask is a Task that still runs when the TaskStatusRunner aborts,
+// after the running task has completed, so that it can record the changes
+// made before the abort, like the tasks that update the inventory. The
+// other tasks left in the queue are not started.
+type FinalTask interface {
+	Task
+	// StartOnAbort is called instead of Start if the TaskStatusRunner
+	// aborted.
+	StartOnAbort(*TaskContext)
+}
+
+// CancelledReason is the reason of the Skipped events sent for the objects
+// that a cancelled task did not get to.
+const CancelledReason = "cancelled"
+
 // NewWaitTask creates a new wait task where we will wait until
 // the resources specifies by ids all meet the specified condition.
 func NewWaitTask(name string, ids object.ObjMetadataSet, cond Condition, timeout time.Duration, mapper meta.RESTMapper) *WaitTask {
@@ -64,6 +89,13 @@ type WaitTask struct {
 	Timeout time.Duration
 	// Mapper is the RESTMapper to update after CRDs have been reconciled
 	Mapper meta.RESTMapper
+	// FinalizerRemoval optionally removes finalizers from the objects that
+	// are still terminating after a grace period, when waiting for the
+	// objects to be deleted.
+	FinalizerRemoval *FinalizerRemoval
+	// ctx is the context of the started task, used to cancel the removal
+	// of finalizers when the task completes.
+	ctx context.Context
 	// cancelFunc is a function that will cancel the timeout timer
 	// on the task.
 	cancelFunc context.CancelFunc
@@ -72,7 +104,19 @@ type WaitTask struct {
 	// failed is the set of resources that we are waiting for, but is considered
 	// failed, i.e. unlikely to successfully reconcile.
 	failed object.ObjMetadataSet
-	// mu protects the pending ObjMetadataSet
+	// blocked is the reason of the last pending event of the objects whose
+	// deletion is blocked by finalizers.
+	blocked map[object.ObjMetadata]string
+	// removals are the timers that remove the finalizers of the objects.
+	removals map[object.ObjMetadata]*time.Timer
+	// removalsStopped is true once the task completed, so that no more
+	// finalizer removals are scheduled.
+	removalsStopped bool
+	// removalsWG tracks the scheduled finalizer removals, so that the task
+	// only completes after the running removals are done.
+	removalsWG sync.WaitGroup
+	// mu protects the pending ObjMetadataSet, and the blocked objects and
+	// their finalizer removals.
 	mu sync.RWMutex
 }
 
@@ -103,6 +147,7 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 	} else {
 		ctx, w.cancelFunc = context.WithCancel(ctx)
 	}
+	w.ctx = ctx
 
 	w.startInner(taskContext)
 
@@ -123,6 +168,9 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 			w.sendTimeoutEvents(taskContext)
 		}
 
+		// Stop removing finalizers, before the task completes
+		w.stopFinalizerRemovals()
+
 		// Update RESTMapper to pick up new custom resource types
 		w.updateRESTMapper(taskContext)
 
@@ -132,12 +180,17 @@ func (w *WaitTask) Start(taskContext *TaskContext) {
 }
 
 func (w *WaitTask) sendEvent(taskContext *TaskContext, id object.ObjMetadata, op event.WaitEventOperation) {
+	w.sendReasonEvent(taskContext, id, op, "")
+}
+
+func (w *WaitTask) sendReasonEvent(taskContext *TaskContext, id object.ObjMetadata, op event.WaitEventOperation, reason string) {
 	taskContext.SendEvent(event.Event{
 		Type: event.WaitType,
 		WaitEvent: event.WaitEvent{
 			GroupName:  w.Name(),
 			Identifier: id,
 			Operation:  op,
+			Reason:     reason,
 		},
 	})
 }
@@ -151,32 +204,8 @@ func (w *WaitTask) startInner(taskContext *TaskContext) {
 
 	pending := object.ObjMetadataSet{}
 	for _, id := range w.Ids {
-		switch {
-		case w.changedUID(taskContext, id):
-			// replaced
-			w.handleChangedUID(taskContext, id)
-		case w.skipped(taskContext, id):
-			err := taskContext.InventoryManager().SetSkippedReconcile(id)
-			if err != nil {
-				// Object never applied or deleted!
-				klog.Errorf("Failed to mark object as skipped reconcile: %v", err)
-			}
-			w.sendEvent(taskContext, id, event.ReconcileSkipped)
-		case w.reconciledByID(taskContext, id):
-			err := taskContext.InventoryManager().SetSuccessfulReconcile(id)
-			if err != nil {
-				// Object never applied or deleted!
-				klog.Errorf("Failed to mark object as successful reconcile: %v", err)
-			}
-			w.sendEvent(taskContext, id, event.Reconciled)
-		default:
-			err := taskContext.InventoryManager().SetPendingReconcile(id)
-			if err != nil {
-				// Object never applied or deleted!
-				klog.Errorf("Failed to mark object as pending reconcile: %v", err)
-			}
+		if !w.checkObject(taskContext, id) {
 			pending = append(pending, id)
-			w.sendEvent(taskContext, id, event.ReconcilePending)
 		}
 	}
 	w.pending = pending
@@ -188,6 +217,40 @@ func (w *WaitTask) startInner(taskContext *TaskContext) {
 	}
 }
 
+// checkObject updates the status of the object and sends a pending,
+// skipped, reconciled or failed event. Returns false if the object is
+// pending.
+func (w *WaitTask) checkObject(taskContext *TaskContext, id object.ObjMetadata) bool {
+	switch {
+	case w.changedUID(taskContext, id):
+		// replaced
+		w.handleChangedUID(taskContext, id)
+	case w.skipped(taskContext, id):
+		err := taskContext.InventoryManager().SetSkippedReconcile(id)
+		if err != nil {
+			// Object never applied or deleted!
+			klog.Errorf("Failed to mark object as skipped reconcile: %v", err)
+		}
+		w.sendEvent(taskContext, id, event.ReconcileSkipped)
+	case w.reconciledByID(taskContext, id):
+		err := taskContext.InventoryManager().SetSuccessfulReconcile(id)
+		if err != nil {
+			// Object never applied or deleted!
+			klog.Errorf("Failed to mark object as successful reconcile: %v", err)
+		}
+		w.sendEvent(taskContext, id, event.Reconciled)
+	default:
+		err := taskContext.InventoryManager().SetPendingReconcile(id)
+		if err != nil {
+			// Object never applied or deleted!
+			klog.Errorf("Failed to mark object as pending reconcile: %v", err)
+		}
+		w.sendReasonEvent(taskContext, id, event.ReconcilePending, w.checkBlocked(taskContext, id))
+		return false
+	}
+	return true
+}
+
 // sendTimeoutEvents sends a timeout event for every remaining pending object
 // The pending set is read locked during execution of sendTimeoutEvents.
 func (w *WaitTask) sendTimeoutEvents(taskContext *TaskContext) {
@@ -195,15 +258,21 @@ func (w *WaitTask) sendTimeoutEvents(taskContext *TaskContext) {
 	defer w.mu.RUnlock()
 
 	for _, id := range w.pending {
-		err := taskContext.InventoryManager().SetTimeoutReconcile(id)
-		if err != nil {
-			// Object never applied or deleted!
-			klog.Errorf("Failed to mark object as pending reconcile: %v", err)
-		}
-		w.sendEvent(taskContext, id, event.ReconcileTimeout)
+		w.timeoutObject(taskContext, id)
 	}
 }
 
+// timeoutObject updates the status of a pending object and sends a
+// timeout event.
+func (w *WaitTask) timeoutObject(taskContext *TaskContext, id object.ObjMetadata) {
+	err := taskContext.InventoryManager().SetTimeoutReconcile(id)
+	if err != nil {
+		// Object never applied or deleted!
+		klog.Errorf("Failed to mark object as pending reconcile: %v", err)
+	}
+	w.sendReasonEvent(taskContext, id, event.ReconcileTimeout, w.blocked[id])
+}
+
 // reconciledByID checks whether the condition set in the task is currently met
 // for the specified object given the status of resource in the cache.
 func (w *WaitTask) reconciledByID(taskContext *TaskContext, id object.ObjMetadata) bool {
@@ -321,34 +390,20 @@ func (w *WaitTask) StatusUpdate(taskContext *TaskContext, id object.ObjMetadata)
 
 	switch {
 	case w.pending.Contains(id):
-		switch {
-		case w.changedUID(taskContext, id):
-			// replaced
-			w.handleChangedUID(taskContext, id)
-			w.pending = w.pending.Remove(id)
-		case w.reconciledByID(taskContext, id):
-			// reconciled - remove from pending & send event
-			err := taskContext.InventoryManager().SetSuccessfulReconcile(id)
-			if err != nil {
-				// Object never applied or deleted!
-				klog.Errorf("Failed to mark object as successful reconcile: %v", err)
-			}
-			w.pending = w.pending.Remove(id)
-			w.sendEvent(taskContext, id, event.Reconciled)
-		case w.failedByID(taskContext, id):
-			// failed - remove from pending & send event
-			err := taskContext.InventoryManager().SetFailedReconcile(id)
-			if err != nil {
-				// Object never applied or deleted!
-				klog.Errorf("Failed to mark object as failed reconcile: %v", err)
+		done, failed := w.updatePending(taskContext, id)
+		if !done {
+			// still pending - report if blocked by other finalizers now
+			last := w.blocked[id]
+			if reason := w.checkBlocked(taskContext, id); reason != last {
+				w.sendReasonEvent(taskContext, id, event.ReconcilePending, reason)
 			}
-			w.pending = w.pending.Remove(id)
-			w.failed = append(w.failed, id)
-			w.sendEvent(taskContext, id, event.ReconcileFailed)
-		default:
 			// can't be all reconciled now, so don't bother checking
 			return
 		}
+		w.pending = w.pending.Remove(id)
+		if failed {
+			w.failed = append(w.failed, id)
+		}
 	case !w.Ids.Contains(id):
 		// not in wait group - ignore
 		return
@@ -409,6 +464,72 @@ func (w *WaitTask) StatusUpdate(taskContext *TaskContext, id object.ObjMetadata)
 	}
 }
 
+// updatePending updates the status of a pending object and sends a
+// reconciled or failed event, if the object is no longer pending.
+// Returns whether the object is no longer pending, and whether it failed.
+func (w *WaitTask) updatePending(taskContext *TaskContext, id object.ObjMetadata) (bool, bool) {
+	switch {
+	case w.changedUID(taskContext, id):
+		// replaced
+		w.handleChangedUID(taskContext, id)
+		return true, false
+	case w.reconciledByID(taskContext, id):
+		// reconciled - send event
+		err := taskContext.InventoryManager().SetSuccessfulReconcile(id)
+		if err != nil {
+			// Object never applied or deleted!
+			klog.Errorf("Failed to mark object as successful reconcile: %v", err)
+		}
+		w.sendEvent(taskContext, id, event.Reconciled)
+		return true, false
+	case w.failedByID(taskContext, id):
+		// failed - send event
+		err := taskContext.InventoryManager().SetFailedReconcile(id)
+		if err != nil {
+			// Object never applied or deleted!
+			klog.Errorf("Failed to mark object as failed reconcile: %v", err)
+		}
+		w.sendEvent(taskContext, id, event.ReconcileFailed)
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// CheckObject checks the status of a single object, without starting the
+// task, and sends a pending, skipped, reconciled or failed event.
+// Returns false if the object is still pending. Together with
+// UpdateObject and TimeoutObject, this allows other tasks to wait for
+// each of the objects individually, using the condition, timeout and
+// event group name of the WaitTask. Like the other methods of the
+// WaitTask, they may be called concurrently.
+func (w *WaitTask) CheckObject(taskContext *TaskContext, id object.ObjMetadata) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.checkObject(taskContext, id)
+}
+
+// UpdateObject checks the status of a single pending object, after a
+// status update, and sends a reconciled or failed event if the object is
+// no longer pending. Returns false if the object is still pending.
+func (w *WaitTask) UpdateObject(taskContext *TaskContext, id object.ObjMetadata) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	done, _ := w.updatePending(taskContext, id)
+	return done
+}
+
+// TimeoutObject marks a single pending object as timed out and sends a
+// timeout event.
+func (w *WaitTask) TimeoutObject(taskContext *TaskContext, id object.ObjMetadata) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.timeoutObject(taskContext, id)
+}
+
 // updateRESTMapper resets the RESTMapper if CRDs were applied, so that new
 // resource types can be applied by subsequent tasks.
 // TODO: find a way to add/remove mappers without resetting the entire mapper
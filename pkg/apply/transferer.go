@@ -0,0 +1,257 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// NewTransferer returns a new Transferer.
+func NewTransferer(factory cmdutil.Factory, invClient inventory.Client) (*Transferer, error) {
+	client, err := factory.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := factory.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &Transferer{
+		client:    client,
+		mapper:    mapper,
+		invClient: invClient,
+	}, nil
+}
+
+// Transferer moves the ownership of objects from one inventory to another,
+// without deleting or otherwise modifying the objects.
+type Transferer struct {
+	client    dynamic.Interface
+	mapper    meta.RESTMapper
+	invClient inventory.Client
+}
+
+// TransfererOptions selects the objects to transfer. An object must match
+// all the provided selectors to be transferred. At least one selector must
+// be provided.
+type TransfererOptions struct {
+	// Selector matches the labels of the live objects.
+	Selector labels.Selector
+
+	// GroupKinds matches the objects of any of these kinds. A GroupKind
+	// without a group matches the kind in any group.
+	GroupKinds []schema.GroupKind
+
+	// Names matches the objects with any of these names.
+	Names []string
+
+	// DryRunStrategy defines whether changes should actually be performed,
+	// or if it is just talk and no action.
+	DryRunStrategy common.DryRunStrategy
+}
+
+// Transfer moves the selected objects from the source inventory to the
+// target inventory, and returns the transferred objects.
+//
+// The objects are added to the target inventory first, then the
+// owning-inventory annotation of the live objects is set to the target
+// inventory, and finally the objects are removed from the source inventory.
+// If any of these steps fails, the annotations that were already set and
+// the target inventory are reverted, and no object is transferred.
+//
+// If an object is owned by neither inventory, nothing is transferred.
+// Objects that no longer exist are removed from the source inventory only.
+func (t *Transferer) Transfer(ctx context.Context, from, to inventory.Info, options TransfererOptions) (object.ObjMetadataSet, error) {
+	if options.Selector == nil && len(options.GroupKinds) == 0 && len(options.Names) == 0 {
+		return nil, fmt.Errorf("no objects selected to transfer")
+	}
+	if to.ID() == "" {
+		return nil, fmt.Errorf("target inventory %s/%s has no inventory id", to.Namespace(), to.Name())
+	}
+	if from.ID() == to.ID() {
+		return nil, fmt.Errorf("source and target inventories have the same inventory id %q", from.ID())
+	}
+	ids, err := t.invClient.GetClusterObjs(from)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("transfer run for %d objects", len(ids))
+
+	objs, missing, err := t.selectObjects(ctx, from, to, ids, options)
+	if err != nil {
+		return nil, err
+	}
+	transferred := object.UnstructuredSetToObjMetadataSet(objs)
+	if len(transferred) == 0 && len(missing) == 0 {
+		return nil, nil
+	}
+
+	if len(transferred) == 0 {
+		klog.V(4).Infof("removing %d missing objects from the source inventory", len(missing))
+		return nil, t.invClient.Replace(from, ids.Diff(missing), options.DryRunStrategy)
+	}
+
+	prevTargetIds, err := t.invClient.GetClusterObjs(to)
+	if err != nil {
+		return nil, err
+	}
+	klog.V(4).Infof("adding %d transferred objects to the target inventory", len(transferred))
+	if _, err := t.invClient.Merge(to, transferred, options.DryRunStrategy); err != nil {
+		return nil, err
+	}
+
+	var patched object.UnstructuredSet
+	for _, obj := range objs {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+		if inventory.IDMatch(to, obj) == inventory.Match {
+			klog.V(4).Infof("object already owned by the target inventory (object: %q)",
+				object.UnstructuredToObjMetadata(obj))
+			continue
+		}
+		if err = t.patchInventoryAnnotation(ctx, obj, to.ID(), options.DryRunStrategy); err != nil {
+			break
+		}
+		patched = append(patched, obj)
+	}
+
+	if err == nil {
+		klog.V(4).Infof("removing %d transferred and %d missing objects from the source inventory",
+			len(transferred), len(missing))
+		err = t.invClient.Replace(from, ids.Diff(transferred).Diff(missing), options.DryRunStrategy)
+	}
+	if err != nil {
+		// The transfer failed, so revert to the previous owners. Use a
+		// new context, in case the transfer was cancelled.
+		if revertErr := t.revert(context.Background(), to, patched, prevTargetIds, options.DryRunStrategy); revertErr != nil {
+			return nil, utilerrors.NewAggregate([]error{err, revertErr})
+		}
+		return nil, err
+	}
+	return transferred, nil
+}
+
+// selectObjects returns the live objects of the source inventory that match
+// the options, and the ids of the selected objects that no longer exist.
+// Returns an error if a selected object is owned by an inventory other than
+// the source and target inventories.
+func (t *Transferer) selectObjects(ctx context.Context, from, to inventory.Info, ids object.ObjMetadataSet,
+	options TransfererOptions) (object.UnstructuredSet, object.ObjMetadataSet, error) {
+	var objs object.UnstructuredSet
+	var missing object.ObjMetadataSet
+	var errs []error
+	for _, id := range ids {
+		if !matchesGroupKinds(id, options.GroupKinds) || !matchesNames(id, options.Names) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		obj, err := getLiveObject(ctx, t.client, t.mapper, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if obj == nil {
+			// The object is gone, so there is nothing to transfer, unless
+			// it must match labels that can't be checked anymore.
+			if options.Selector == nil {
+				missing = append(missing, id)
+			}
+			continue
+		}
+		if options.Selector != nil && !options.Selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		if inventory.IDMatch(from, obj) == inventory.NoMatch && inventory.IDMatch(to, obj) == inventory.NoMatch {
+			errs = append(errs, fmt.Errorf("object %s is owned by another inventory %q",
+				id, obj.GetAnnotations()[inventory.OwningInventoryKey]))
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	if len(errs) > 0 {
+		return nil, nil, utilerrors.NewAggregate(errs)
+	}
+	return objs, missing, nil
+}
+
+// revert restores the owning-inventory annotation of the patched objects,
+// and the objects of the target inventory, as they were before the transfer.
+func (t *Transferer) revert(ctx context.Context, to inventory.Info, patched object.UnstructuredSet,
+	prevTargetIds object.ObjMetadataSet, dryRun common.DryRunStrategy) error {
+	var errs []error
+	for _, obj := range patched {
+		var owner interface{}
+		if value, found := obj.GetAnnotations()[inventory.OwningInventoryKey]; found {
+			owner = value
+		}
+		if err := t.patchInventoryAnnotation(ctx, obj, owner, dryRun); err != nil {
+			errs = append(errs, fmt.Errorf("failed to revert transfer: %w", err))
+		}
+	}
+	klog.V(4).Infof("restoring %d objects in the target inventory", len(prevTargetIds))
+	if err := t.invClient.Replace(to, prevTargetIds, dryRun); err != nil {
+		errs = append(errs, fmt.Errorf("failed to revert transfer: %w", err))
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// patchInventoryAnnotation sets the owning-inventory annotation of the live
+// object to the owner, or removes it if the owner is nil.
+func (t *Transferer) patchInventoryAnnotation(ctx context.Context, obj *unstructured.Unstructured,
+	owner interface{}, dryRun common.DryRunStrategy) error {
+	id := object.UnstructuredToObjMetadata(obj)
+	if dryRun.ClientDryRun() {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				inventory.OwningInventoryKey: owner,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	mapping, err := t.mapper.RESTMapping(id.GroupKind)
+	if err != nil {
+		return err
+	}
+	opts := metav1.PatchOptions{}
+	if dryRun.ServerDryRun() {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	klog.V(4).Infof("setting annotation (object: %q, annotation: %q, value: %v)",
+		id, inventory.OwningInventoryKey, owner)
+	_, err = t.client.Resource(mapping.Resource).Namespace(id.Namespace).
+		Patch(ctx, id.Name, types.MergePatchType, patch, opts)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("object %s was deleted during the transfer", id)
+		}
+		return fmt.Errorf("failed to set annotation %s on %s: %w", inventory.OwningInventoryKey, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/inventory"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/testutil"
+)
+
+// multiInvClient is a fake inventory client storing the objects of several
+// inventories, keyed by inventory id.
+type multiInvClient struct {
+	*inventory.FakeClient
+	objs map[string]object.ObjMetadataSet
+	// replaceErr is returned when replacing the objects of the inventory
+	// with this id.
+	replaceErr map[string]error
+}
+
+func (c *multiInvClient) GetClusterObjs(inv inventory.Info) (object.ObjMetadataSet, error) {
+	return c.objs[inv.ID()], nil
+}
+
+func (c *multiInvClient) Merge(inv inventory.Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) (object.ObjMetadataSet, error) {
+	pruneIds := c.objs[inv.ID()].Diff(objs)
+	if !dryRun.ClientOrServerDryRun() {
+		c.objs[inv.ID()] = c.objs[inv.ID()].Union(objs)
+	}
+	return pruneIds, nil
+}
+
+func (c *multiInvClient) Replace(inv inventory.Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) error {
+	if err := c.replaceErr[inv.ID()]; err != nil {
+		return err
+	}
+	if !dryRun.ClientOrServerDryRun() {
+		c.objs[inv.ID()] = objs
+	}
+	return nil
+}
+
+func TestTransfererTransfer(t *testing.T) {
+	depID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	pod1ID := testutil.ToIdentifier(t, resources["obj1"])
+	pod2ID := testutil.ToIdentifier(t, resources["obj2"])
+	srcIDs := object.ObjMetadataSet{depID, secretID, pod1ID, pod2ID}
+
+	testCases := map[string]struct {
+		to                  inventoryInfo
+		options             TransfererOptions
+		patchErr            map[string]error
+		replaceErr          map[string]error
+		expectedTransferred object.ObjMetadataSet
+		expectedSrc         object.ObjMetadataSet
+		expectedDst         object.ObjMetadataSet
+		expectedOwners      map[object.ObjMetadata]string
+		expectedErr         string
+	}{
+		"nothing selected": {
+			options:     TransfererOptions{},
+			expectedSrc: srcIDs,
+			expectedErr: "no objects selected to transfer",
+		},
+		"same inventory": {
+			to: inventoryInfo{name: "abc-123", namespace: "default", id: "test"},
+			options: TransfererOptions{
+				Names: []string{"secret"},
+			},
+			expectedSrc: srcIDs,
+			expectedErr: `source and target inventories have the same inventory id "test"`,
+		},
+		"by kind": {
+			options: TransfererOptions{
+				GroupKinds: []schema.GroupKind{{Kind: "deployment"}},
+			},
+			expectedTransferred: object.ObjMetadataSet{depID},
+			expectedSrc:         object.ObjMetadataSet{secretID, pod1ID, pod2ID},
+			expectedDst:         object.ObjMetadataSet{depID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "target",
+				secretID: "test",
+			},
+		},
+		"by name, including missing object": {
+			options: TransfererOptions{
+				Names: []string{"secret", "obj2"},
+			},
+			expectedTransferred: object.ObjMetadataSet{secretID},
+			expectedSrc:         object.ObjMetadataSet{depID, pod1ID},
+			expectedDst:         object.ObjMetadataSet{secretID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "test",
+				secretID: "target",
+			},
+		},
+		"by selector": {
+			options: TransfererOptions{
+				Selector: labels.SelectorFromSet(labels.Set{"app": "web"}),
+			},
+			expectedTransferred: object.ObjMetadataSet{depID},
+			expectedSrc:         object.ObjMetadataSet{secretID, pod1ID, pod2ID},
+			expectedDst:         object.ObjMetadataSet{depID},
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "target",
+				secretID: "test",
+			},
+		},
+		"owned by other inventory": {
+			options: TransfererOptions{
+				GroupKinds: []schema.GroupKind{{Kind: "Pod"}},
+				Names:      []string{"obj1"},
+			},
+			expectedSrc: srcIDs,
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:  "test",
+				pod1ID: "other",
+			},
+			expectedErr: `object test-namespace_obj1__Pod is owned by another inventory "other"`,
+		},
+		"annotation patch fails": {
+			options: TransfererOptions{
+				Names: []string{"foo", "secret"},
+			},
+			patchErr: map[string]error{
+				"secret": fmt.Errorf("patch failed"),
+			},
+			expectedSrc: srcIDs,
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "test",
+				secretID: "test",
+			},
+			expectedErr: "failed to set annotation config.k8s.io/owning-inventory on default_secret__Secret: patch failed",
+		},
+		"source inventory write fails": {
+			options: TransfererOptions{
+				Names: []string{"foo", "secret"},
+			},
+			replaceErr: map[string]error{
+				"test": fmt.Errorf("replace failed"),
+			},
+			expectedSrc: srcIDs,
+			expectedOwners: map[object.ObjMetadata]string{
+				depID:    "test",
+				secretID: "test",
+			},
+			expectedErr: "replace failed",
+		},
+		"dry-run": {
+			options: TransfererOptions{
+				GroupKinds:     []schema.GroupKind{{Group: "apps", Kind: "Deployment"}},
+				DryRunStrategy: common.DryRunClient,
+			},
+			expectedTransferred: object.ObjMetadataSet{depID},
+			expectedSrc:         srcIDs,
+			expectedOwners: map[object.ObjMetadata]string{
+				depID: "test",
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			dep := testutil.Unstructured(t, resources["deployment"], testutil.AddOwningInv(t, "test"))
+			dep.SetLabels(map[string]string{"app": "web"})
+			secret := testutil.Unstructured(t, resources["secret"], testutil.AddOwningInv(t, "test"))
+			pod1 := testutil.Unstructured(t, resources["obj1"], testutil.AddOwningInv(t, "other"))
+
+			client := fake.NewSimpleDynamicClient(scheme.Scheme, []runtime.Object{dep, secret, pod1}...)
+			mapper := testutil.NewFakeRESTMapper(
+				schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"},
+				schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+			)
+			invClient := &multiInvClient{
+				FakeClient: inventory.NewFakeClient(nil),
+				objs: map[string]object.ObjMetadataSet{
+					"test": srcIDs,
+				},
+				replaceErr: tc.replaceErr,
+			}
+			client.PrependReactor("patch", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				err := tc.patchErr[action.(clienttesting.PatchAction).GetName()]
+				return err != nil, nil, err
+			})
+			tr := &Transferer{
+				client:    client,
+				mapper:    mapper,
+				invClient: invClient,
+			}
+			from := inventoryInfo{name: "abc-123", namespace: "default", id: "test"}.toWrapped()
+			to := inventoryInfo{name: "def-456", namespace: "default", id: "target"}
+			if tc.to.id != "" {
+				to = tc.to
+			}
+
+			transferred, err := tr.Transfer(context.TODO(), from, to.toWrapped(), tc.options)
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedTransferred, transferred)
+			testutil.AssertEqual(t, tc.expectedSrc, invClient.objs["test"])
+			testutil.AssertEqual(t, tc.expectedDst, invClient.objs["target"])
+
+			for id, owner := range tc.expectedOwners {
+				mapping, err := mapper.RESTMapping(id.GroupKind)
+				require.NoError(t, err)
+				obj, err := client.Resource(mapping.Resource).Namespace(id.Namespace).
+					Get(context.TODO(), id.Name, metav1.GetOptions{})
+				require.NoError(t, err)
+				assert.Equal(t, owner, obj.GetAnnotations()[inventory.OwningInventoryKey], "owner of %s", id)
+			}
+		})
+	}
+}
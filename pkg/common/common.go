@@ -25,6 +25,17 @@ const (
 	// used as a suffix of the inventory object name. Example:
 	//   inventory-1e5824fb
 	InventoryHash = "cli-utils.sigs.k8s.io/inventory-hash"
+	// InventoryShardLabel is the label stored on the additional
+	// inventory objects (shards) of a sharded inventory. The value of
+	// the label is the index of the shard. The shards have the same
+	// InventoryLabel as the inventory object they belong to.
+	InventoryShardLabel = "cli-utils.sigs.k8s.io/inventory-shard"
+	// InventoryRevisionLabel is the label stored on the ConfigMaps
+	// which record the revision history of an inventory. The value
+	// of the label is the InventoryLabel of the inventory object.
+	// The revisions do not have the InventoryLabel, so that they are
+	// not mistaken for inventory objects.
+	InventoryRevisionLabel = "cli-utils.sigs.k8s.io/inventory-revision"
 	// Resource lifecycle annotation key for "on-remove" operations.
 	OnRemoveAnnotation = "cli-utils.sigs.k8s.io/on-remove"
 	// Resource lifecycle annotation value to prevent deletion.
@@ -41,6 +52,20 @@ const (
 	// PreventDeletion is the value used with LifecycleDeletionAnnotation
 	// to prevent deleting a resource.
 	PreventDeletion = "detach"
+
+	// OnImmutableChangeAnnotation is the resource lifecycle annotation key
+	// for applies that fail because an immutable field was changed.
+	OnImmutableChangeAnnotation = "cli-utils.sigs.k8s.io/on-immutable-change"
+
+	// OnImmutableChangeReplace is the value used with
+	// OnImmutableChangeAnnotation to delete and recreate the resource.
+	OnImmutableChangeReplace = "replace"
+
+	// ForceConflictsAnnotation is the annotation key that lists the paths
+	// of the fields, like ".spec.replicas", separated by commas, that may
+	// be taken from other field managers when applying server-side. The
+	// fields below the listed paths may be taken as well.
+	ForceConflictsAnnotation = "cli-utils.sigs.k8s.io/force-conflicts"
 )
 
 // RandomStr returns an eight-digit (with leading zeros) string of a
@@ -86,6 +111,8 @@ const (
 	//
 	// If a client sends a server-side dry-run call to an APIServer that doesn't
 	// support server-side dry-run, then the APIServer will persist changes inadvertently.
+	// The Applier and Destroyer verify the support of every resource before
+	// running with this strategy.
 	DryRunServer
 )
 
@@ -17,6 +17,7 @@ import (
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/inventory/configmap"
+	"sigs.k8s.io/cli-utils/pkg/inventory/resourcegroup"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/filters"
 	"sigs.k8s.io/kustomize/kyaml/openapi"
@@ -40,6 +41,9 @@ type InitOptions struct {
 	Namespace string
 	// Inventory object label value; must be a valid k8s label value.
 	InventoryID string
+	// Kind of the inventory object; ConfigMap or ResourceGroup. The
+	// Template is left unchanged if empty.
+	InventoryKind string
 }
 
 func NewInitOptions(f cmdutil.Factory, ioStreams genericclioptions.IOStreams) *InitOptions {
@@ -81,11 +85,28 @@ func (i *InitOptions) Complete(args []string) error {
 	if !validateInventoryID(i.InventoryID) {
 		return fmt.Errorf("invalid group name: %s", i.InventoryID)
 	}
+	if err := i.completeTemplate(); err != nil {
+		return err
+	}
 	// Output the calculated namespace used for inventory object.
 	fmt.Fprintf(i.ioStreams.Out, "namespace: %s is used for inventory object\n", i.Namespace)
 	return nil
 }
 
+// completeTemplate sets the Template for the InventoryKind.
+func (i *InitOptions) completeTemplate() error {
+	switch i.InventoryKind {
+	case "":
+	case "ConfigMap":
+		i.Template = configmap.ConfigMapTemplate
+	case "ResourceGroup":
+		i.Template = resourcegroup.ResourceGroupTemplate
+	default:
+		return fmt.Errorf("invalid inventory kind: %s (must be ConfigMap or ResourceGroup)", i.InventoryKind)
+	}
+	return nil
+}
+
 type namespaceLoader interface {
 	Namespace() (string, bool, error)
 }
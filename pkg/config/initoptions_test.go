@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
 )
@@ -185,6 +186,15 @@ func TestComplete(t *testing.T) {
 	}
 }
 
+func TestCompleteTemplate(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace("foo")
+	defer tf.Cleanup()
+	ioStreams, _, _, _ := genericclioptions.NewTestIOStreams() //nolint:dogsled
+	io := NewInitOptions(tf, ioStreams)
+	io.InventoryKind = "Secret"
+	assert.EqualError(t, io.completeTemplate(), "invalid inventory kind: Secret (must be ConfigMap or ResourceGroup)")
+}
+
 func TestFindNamespace(t *testing.T) {
 	testCases := map[string]struct {
 		namespace         string
@@ -333,12 +343,27 @@ func TestValidateInventoryID(t *testing.T) {
 
 func TestFillInValues(t *testing.T) {
 	tests := map[string]struct {
-		namespace   string
-		inventoryID string
+		namespace     string
+		inventoryID   string
+		inventoryKind string
+		expectedKind  string
 	}{
 		"Basic namespace/inventoryID": {
-			namespace:   "foo",
-			inventoryID: "bar",
+			namespace:    "foo",
+			inventoryID:  "bar",
+			expectedKind: "ConfigMap",
+		},
+		"ConfigMap inventory kind": {
+			namespace:     "foo",
+			inventoryID:   "bar",
+			inventoryKind: "ConfigMap",
+			expectedKind:  "ConfigMap",
+		},
+		"ResourceGroup inventory kind": {
+			namespace:     "foo",
+			inventoryID:   "bar",
+			inventoryKind: "ResourceGroup",
+			expectedKind:  "ResourceGroup",
 		},
 	}
 
@@ -350,6 +375,8 @@ func TestFillInValues(t *testing.T) {
 			io := NewInitOptions(tf, ioStreams)
 			io.Namespace = tc.namespace
 			io.InventoryID = tc.inventoryID
+			io.InventoryKind = tc.inventoryKind
+			require.NoError(t, io.completeTemplate())
 			actual := io.fillInValues()
 			expectedLabel := fmt.Sprintf("cli-utils.sigs.k8s.io/inventory-id: %s", tc.inventoryID)
 			if !strings.Contains(actual, expectedLabel) {
@@ -366,8 +393,9 @@ func TestFillInValues(t *testing.T) {
 			if !matched {
 				t.Errorf("expected inventory name (e.g. inventory-12345678), got (%s)", actual)
 			}
-			if !strings.Contains(actual, "kind: ConfigMap") {
-				t.Errorf("\nExpected `kind: ConfigMap` not found in inventory object: %s\n", actual)
+			expectedKind := fmt.Sprintf("kind: %s", tc.expectedKind)
+			if !strings.Contains(actual, expectedKind) {
+				t.Errorf("\nExpected `%s` not found in inventory object: %s\n", expectedKind, actual)
 			}
 		})
 	}
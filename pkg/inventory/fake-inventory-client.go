@@ -6,18 +6,24 @@ package inventory
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
 
 // FakeClient is a testing implementation of the Client interface.
 type FakeClient struct {
-	Objs object.ObjMetadataSet
-	Err  error
+	Objs      object.ObjMetadataSet
+	ObjStatus []actuation.ObjectStatus
+	Results   []actuation.ObjectStatus
+	Revisions []Revision
+	Err       error
 }
 
 var (
 	_ Client        = &FakeClient{}
+	_ ResultClient  = &FakeClient{}
+	_ HistoryClient = &FakeClient{}
 	_ ClientFactory = FakeClientFactory{}
 )
 
@@ -66,11 +72,13 @@ func (fic *FakeClient) Replace(_ Info, objs object.ObjMetadataSet, _ common.DryR
 	return nil
 }
 
-// DeleteInventoryObj returns an error if one is forced; does nothing otherwise.
+// DeleteInventoryObj returns an error if one is forced; clears the stored
+// objects otherwise.
 func (fic *FakeClient) DeleteInventoryObj(Info, common.DryRunStrategy) error {
 	if fic.Err != nil {
 		return fic.Err
 	}
+	fic.Objs = object.ObjMetadataSet{}
 	return nil
 }
 
@@ -98,3 +106,70 @@ func (fic *FakeClient) GetClusterInventoryInfo(Info) (*unstructured.Unstructured
 func (fic *FakeClient) GetClusterInventoryObjs(_ Info) (object.UnstructuredSet, error) {
 	return object.UnstructuredSet{}, nil
 }
+
+// GetClusterObjStatus returns the currently stored object status.
+func (fic *FakeClient) GetClusterObjStatus(Info) ([]actuation.ObjectStatus, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	return fic.ObjStatus, nil
+}
+
+// UpdateObjStatus stores the passed object status, or returns an error if
+// one is set up.
+func (fic *FakeClient) UpdateObjStatus(_ Info, objStatus []actuation.ObjectStatus, _ common.DryRunStrategy) error {
+	if fic.Err != nil {
+		return fic.Err
+	}
+	fic.ObjStatus = objStatus
+	return nil
+}
+
+// GetClusterObjResults returns the currently stored results.
+func (fic *FakeClient) GetClusterObjResults(Info) ([]actuation.ObjectStatus, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	return fic.Results, nil
+}
+
+// ReplaceWithResults replaces the stored cluster inventory objs with the
+// passed objs, and stores the passed results, or returns an error if one
+// is set up.
+func (fic *FakeClient) ReplaceWithResults(_ Info, objs object.ObjMetadataSet, results []actuation.ObjectStatus, _ common.DryRunStrategy) error {
+	if fic.Err != nil {
+		return fic.Err
+	}
+	fic.Objs = objs
+	fic.Results = results
+	return nil
+}
+
+// GetRevisions returns the currently stored revisions.
+func (fic *FakeClient) GetRevisions(Info) ([]Revision, error) {
+	if fic.Err != nil {
+		return nil, fic.Err
+	}
+	return fic.Revisions, nil
+}
+
+// AddRevision stores the passed revision, numbered after the last stored
+// revision, and drops the oldest revisions beyond the limit, or returns an
+// error if one is set up.
+func (fic *FakeClient) AddRevision(_ Info, rev Revision, limit int, dryRun common.DryRunStrategy) (Revision, error) {
+	if fic.Err != nil {
+		return rev, fic.Err
+	}
+	if dryRun.ClientOrServerDryRun() {
+		return rev, nil
+	}
+	rev.Number = 1
+	if len(fic.Revisions) > 0 {
+		rev.Number = fic.Revisions[len(fic.Revisions)-1].Number + 1
+	}
+	fic.Revisions = append(fic.Revisions, rev)
+	if limit > 0 && len(fic.Revisions) > limit {
+		fic.Revisions = fic.Revisions[len(fic.Revisions)-limit:]
+	}
+	return rev, nil
+}
@@ -0,0 +1,265 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains an inventory client which stores the
+// inventory in a local file instead of in the cluster, for
+// clusters where the inventory object cannot be created, or
+// workflows which review the inventory with the package. The
+// file contains the inventory object manifest, the same object
+// the ClusterClient stores in the cluster.
+
+package inventory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
+)
+
+// FileClient is an implementation of the Client interface which stores the
+// inventory in a local file. The inventory object is never created in the
+// cluster.
+type FileClient struct {
+	// Path is the path of the inventory file.
+	Path string
+}
+
+var (
+	_ Client        = &FileClient{}
+	_ ResultClient  = &FileClient{}
+	_ ClientFactory = FileClientFactory{}
+)
+
+// FileClientFactory is a factory that creates instances of FileClient,
+// which store the inventory in the file at Path.
+type FileClientFactory struct {
+	Path string
+}
+
+func (f FileClientFactory) NewClient(cmdutil.Factory) (Client, error) {
+	return NewFileClient(f.Path), nil
+}
+
+// NewFileClient returns a FileClient which stores the inventory in the
+// file at the passed path.
+func NewFileClient(path string) *FileClient {
+	return &FileClient{Path: path}
+}
+
+// GetClusterObjs returns the objects stored in the inventory file, or an
+// empty set if the file does not exist yet.
+func (fc *FileClient) GetClusterObjs(localInv Info) (object.ObjMetadataSet, error) {
+	inv, err := fc.read(localInv)
+	if err != nil || inv == nil {
+		return object.ObjMetadataSet{}, err
+	}
+	return wrapInventoryObjByKind(inv).Load()
+}
+
+// Merge stores the union of the passed objects with the objects stored in
+// the inventory file, and returns the stored objects which are not in the
+// passed objects. This is the set of objects to prune. Creates the
+// inventory file if it does not exist.
+func (fc *FileClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) (object.ObjMetadataSet, error) {
+	inv, err := fc.read(localInv)
+	if err != nil {
+		return nil, err
+	}
+	storedObjs := object.ObjMetadataSet{}
+	if inv == nil {
+		inv, err = fc.newInventoryObj(localInv)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		storedObjs, err = wrapInventoryObjByKind(inv).Load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	pruneIds := storedObjs.Diff(objs)
+	unionObjs := storedObjs.Union(objs)
+	klog.V(4).Infof("num objects to prune: %d", len(pruneIds))
+	klog.V(4).Infof("num merged objects to store in inventory file: %d", len(unionObjs))
+	if dryRun.ClientOrServerDryRun() {
+		return pruneIds, nil
+	}
+	if err := fc.store(inv, unionObjs, nil); err != nil {
+		return nil, err
+	}
+	return pruneIds, nil
+}
+
+// Replace stores the passed objects in the inventory file.
+func (fc *FileClient) Replace(localInv Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) error {
+	return fc.ReplaceWithResults(localInv, objs, nil, dryRun)
+}
+
+// ReplaceWithResults stores the passed objects in the inventory file, with
+// the passed results if the inventory object can record them. Returns an
+// error if the inventory file does not exist.
+func (fc *FileClient) ReplaceWithResults(localInv Info, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infoln("dry-run replace inventory file: not written")
+		return nil
+	}
+	inv, err := fc.read(localInv)
+	if err != nil {
+		return err
+	}
+	if inv == nil {
+		return fmt.Errorf("inventory file not found: %s", fc.Path)
+	}
+	klog.V(4).Infof("replace inventory file %d objects", len(objs))
+	return fc.store(inv, objs, results)
+}
+
+// GetClusterObjResults returns the result of the last run of the objects
+// stored in the inventory file, or an empty list if the file does not exist
+// or does not record the results.
+func (fc *FileClient) GetClusterObjResults(localInv Info) ([]actuation.ObjectStatus, error) {
+	inv, err := fc.read(localInv)
+	if err != nil || inv == nil {
+		return []actuation.ObjectStatus{}, err
+	}
+	resultStorage, ok := wrapInventoryObjByKind(inv).(ResultStorage)
+	if !ok {
+		return []actuation.ObjectStatus{}, nil
+	}
+	return resultStorage.LoadResults()
+}
+
+// DeleteInventoryObj deletes the inventory file, if it exists.
+func (fc *FileClient) DeleteInventoryObj(localInv Info, dryRun common.DryRunStrategy) error {
+	if _, err := fc.read(localInv); err != nil {
+		return err
+	}
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infof("dry-run delete inventory file: not deleted")
+		return nil
+	}
+	klog.V(4).Infof("deleting inventory file: %s", fc.Path)
+	if err := os.Remove(fc.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete inventory file: %w", err)
+	}
+	return nil
+}
+
+// ApplyInventoryNamespace does nothing, because the inventory is not
+// stored in the namespace.
+func (fc *FileClient) ApplyInventoryNamespace(obj *unstructured.Unstructured, _ common.DryRunStrategy) error {
+	klog.V(4).Infof("skip applying inventory namespace (%s): inventory is stored in a file", obj.GetName())
+	return nil
+}
+
+// GetClusterInventoryInfo returns the inventory object stored in the
+// inventory file, or nil if the file does not exist.
+func (fc *FileClient) GetClusterInventoryInfo(localInv Info) (*unstructured.Unstructured, error) {
+	return fc.read(localInv)
+}
+
+// GetClusterInventoryObjs returns the inventory object stored in the
+// inventory file, or an empty set if the file does not exist.
+func (fc *FileClient) GetClusterInventoryObjs(localInv Info) (object.UnstructuredSet, error) {
+	inv, err := fc.read(localInv)
+	if err != nil || inv == nil {
+		return object.UnstructuredSet{}, err
+	}
+	return object.UnstructuredSet{inv}, nil
+}
+
+// read returns the inventory object stored in the inventory file, or nil
+// if the file does not exist. Returns an error if the stored inventory
+// object has a different inventory id than the passed inventory.
+func (fc *FileClient) read(localInv Info) (*unstructured.Unstructured, error) {
+	if localInv == nil {
+		return nil, fmt.Errorf("inventoryInfo must be specified")
+	}
+	data, err := ioutil.ReadFile(fc.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read inventory file: %w", err)
+	}
+	inv := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(data, &inv.Object); err != nil {
+		return nil, fmt.Errorf("invalid inventory file %s: %w", fc.Path, err)
+	}
+	if !IsInventoryObject(inv) {
+		return nil, fmt.Errorf("invalid inventory file %s: missing the %s label", fc.Path, common.InventoryLabel)
+	}
+	if id := inv.GetLabels()[common.InventoryLabel]; localInv.ID() != "" && id != localInv.ID() {
+		return nil, fmt.Errorf("inventory file %s belongs to inventory %q, not %q", fc.Path, id, localInv.ID())
+	}
+	return inv, nil
+}
+
+// newInventoryObj returns the inventory object of the passed inventory,
+// to be stored in a new inventory file.
+func (fc *FileClient) newInventoryObj(localInv Info) (*unstructured.Unstructured, error) {
+	inv := invInfoToUnstructured(localInv)
+	if inv == nil {
+		return nil, fmt.Errorf("attempting create a nil inventory object")
+	}
+	if !IsInventoryObject(inv) {
+		return nil, fmt.Errorf("inventory object has no inventory id: %s/%s", inv.GetNamespace(), inv.GetName())
+	}
+	inv = inv.DeepCopy()
+	object.StripKyamlAnnotations(inv)
+	return inv, nil
+}
+
+// store stores the passed objects and results in the passed inventory
+// object, and writes it to the inventory file. The file is replaced
+// atomically, so that it is never left partly written.
+func (fc *FileClient) store(inv *unstructured.Unstructured, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus) error {
+	wrappedInv := wrapInventoryObjByKind(inv)
+	if err := wrappedInv.Store(objs); err != nil {
+		return err
+	}
+	if resultStorage, ok := wrappedInv.(ResultStorage); ok && results != nil {
+		if err := resultStorage.StoreResults(results); err != nil {
+			return err
+		}
+	}
+	inv, err := wrappedInv.GetObject()
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(inv.Object)
+	if err != nil {
+		return fmt.Errorf("failed to encode inventory file: %w", err)
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(fc.Path), filepath.Base(fc.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	if err := os.Chmod(tmpFile.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	klog.V(4).Infof("writing inventory file: %s (%d objects)", fc.Path, len(objs))
+	if err := os.Rename(tmpFile.Name(), fc.Path); err != nil {
+		return fmt.Errorf("failed to write inventory file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/yaml"
+)
+
+func TestFileClient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.yaml")
+	invClient := NewFileClient(path)
+	invObj := inventoryObj.DeepCopy()
+	invObj.SetAnnotations(map[string]string{"config.kubernetes.io/path": "inventory-template.yaml"})
+	inv := WrapInventoryInfoObj(invObj)
+
+	// No objects are stored until the file exists.
+	objs, err := invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assert.Empty(t, objs)
+	invObjs, err := invClient.GetClusterInventoryObjs(inv)
+	require.NoError(t, err)
+	assert.Empty(t, invObjs)
+	err = invClient.Replace(inv, testObjs("a"), common.DryRunNone)
+	assert.EqualError(t, err, "inventory file not found: "+path)
+
+	// Dry-run does not create the file.
+	pruneIds, err := invClient.Merge(inv, testObjs("a", "b"), common.DryRunClient)
+	require.NoError(t, err)
+	assert.Empty(t, pruneIds)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	pruneIds, err = invClient.Merge(inv, testObjs("a", "b"), common.DryRunNone)
+	require.NoError(t, err)
+	assert.Empty(t, pruneIds)
+
+	// The file is replaced without leaving temporary files behind.
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// The file contains the inventory object manifest.
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	stored := &unstructured.Unstructured{}
+	require.NoError(t, yaml.Unmarshal(data, &stored.Object))
+	assert.Equal(t, "ConfigMap", stored.GetKind())
+	assert.Equal(t, inventoryObjName, stored.GetName())
+	assert.Equal(t, testNamespace, stored.GetNamespace())
+	assert.Equal(t, inv.ID(), stored.GetLabels()[common.InventoryLabel])
+	assert.Empty(t, stored.GetAnnotations())
+	loaded, err := WrapInventoryObj(stored).Load()
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a", "b"), loaded)
+
+	// Merge returns the stored objects to prune.
+	pruneIds, err = invClient.Merge(inv, testObjs("b", "c"), common.DryRunNone)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a"), pruneIds)
+	objs, err = invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a", "b", "c"), objs)
+
+	results := []actuation.ObjectStatus{
+		{
+			ObjectReference: ObjectReferenceFromObjMetadata(testObjs("b")[0]),
+			Strategy:        actuation.ActuationStrategyApply,
+			Actuation:       actuation.ActuationSucceeded,
+			Reconcile:       actuation.ReconcileSucceeded,
+		},
+	}
+	err = invClient.ReplaceWithResults(inv, testObjs("b", "c"), results, common.DryRunNone)
+	require.NoError(t, err)
+	objs, err = invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("b", "c"), objs)
+	storedResults, err := invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.Equal(t, results, storedResults)
+	invObjs, err = invClient.GetClusterInventoryObjs(inv)
+	require.NoError(t, err)
+	assert.Len(t, invObjs, 1)
+
+	// The file of another inventory is not used.
+	otherObj := inventoryObj.DeepCopy()
+	otherObj.SetLabels(map[string]string{common.InventoryLabel: "other-id"})
+	other := WrapInventoryInfoObj(otherObj)
+	_, err = invClient.GetClusterObjs(other)
+	assert.EqualError(t, err, `inventory file `+path+` belongs to inventory "`+inv.ID()+`", not "other-id"`)
+	err = invClient.DeleteInventoryObj(other, common.DryRunNone)
+	assert.Error(t, err)
+
+	// Dry-run does not delete the file.
+	require.NoError(t, invClient.DeleteInventoryObj(inv, common.DryRunServer))
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, invClient.DeleteInventoryObj(inv, common.DryRunNone))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	require.NoError(t, invClient.DeleteInventoryObj(inv, common.DryRunNone))
+}
@@ -0,0 +1,302 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the code to record the revision history
+// of an inventory. Each revision is an immutable ConfigMap in
+// the namespace of the inventory object, named after the
+// inventory object and the revision number, with the
+// InventoryRevisionLabel.
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// Revision is an immutable record of a successful apply of an inventory.
+type Revision struct {
+	// Number identifies the revision. Revisions are numbered from 1, in the
+	// order they are recorded.
+	Number int
+	// Objects is the set of objects stored in the inventory by the apply.
+	Objects object.ObjMetadataSet
+	// Hash is the hash of the objects. See ObjMetadataSet.Hash.
+	Hash string
+	// Timestamp is the time the revision was recorded.
+	Timestamp time.Time
+	// Manifests are the applied objects, if they were recorded. A revision
+	// can only be applied again if its manifests were recorded.
+	Manifests object.UnstructuredSet
+}
+
+// HistoryClient is implemented by inventory clients that can record the
+// revision history of an inventory.
+type HistoryClient interface {
+	// GetRevisions returns the recorded revisions of the inventory, from
+	// the oldest to the newest.
+	GetRevisions(inv Info) ([]Revision, error)
+	// AddRevision records the passed revision as the newest revision of the
+	// inventory, and deletes the oldest revisions so that at most limit
+	// revisions are kept. Returns the recorded revision with its number.
+	AddRevision(inv Info, rev Revision, limit int, dryRun common.DryRunStrategy) (Revision, error)
+}
+
+var _ HistoryClient = &ClusterClient{}
+
+// configMapGVR is the resource of the ConfigMaps which record the revisions.
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+// maxRevisionSize is the maximum encoded size, in bytes, of the ConfigMap
+// which records a revision, which is the size limit of objects.
+const maxRevisionSize = 1024 * 1024
+
+// The keys of the revision ConfigMap data.
+const (
+	revisionNumberKey    = "revision"
+	revisionObjectsKey   = "objects"
+	revisionHashKey      = "hash"
+	revisionTimestampKey = "timestamp"
+	revisionManifestsKey = "manifests"
+)
+
+// GetRevisions returns the revisions of the passed inventory recorded in
+// the cluster, from the oldest to the newest.
+func (cic *ClusterClient) GetRevisions(localInv Info) ([]Revision, error) {
+	revObjs, err := cic.getClusterRevisions(localInv)
+	if err != nil {
+		return nil, err
+	}
+	revs := make([]Revision, 0, len(revObjs))
+	for _, revObj := range revObjs {
+		rev, err := revisionFromConfigMap(revObj)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, rev)
+	}
+	return revs, nil
+}
+
+// AddRevision records the passed revision in the cluster, numbered after
+// the newest recorded revision, and deletes the oldest revisions beyond the
+// passed limit. The limit is not enforced if it is zero or less. The
+// inventory object must already exist.
+func (cic *ClusterClient) AddRevision(localInv Info, rev Revision, limit int, dryRun common.DryRunStrategy) (Revision, error) {
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infoln("dry-run add inventory revision: not recorded")
+		return rev, nil
+	}
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
+	if err != nil {
+		return rev, fmt.Errorf("failed to read inventory from cluster: %w", err)
+	}
+	if clusterInv == nil {
+		return rev, fmt.Errorf("inventory object not found in cluster: %s/%s", localInv.Namespace(), localInv.Name())
+	}
+	revObjs, err := cic.getClusterRevisions(localInv)
+	if err != nil {
+		return rev, err
+	}
+	rev.Number = 1
+	if len(revObjs) > 0 {
+		rev.Number = revisionNumber(revObjs[len(revObjs)-1]) + 1
+	}
+	revObj, err := revisionToConfigMap(clusterInv, rev)
+	if err != nil {
+		return rev, err
+	}
+	klog.V(4).Infof("create inventory revision: %s/%s (%d objects)", revObj.GetNamespace(), revObj.GetName(), len(rev.Objects))
+	if _, err := cic.dc.Resource(configMapGVR).Namespace(revObj.GetNamespace()).
+		Create(context.TODO(), revObj, metav1.CreateOptions{}); err != nil {
+		return rev, fmt.Errorf("failed to record inventory revision: %w", err)
+	}
+	if limit <= 0 {
+		return rev, nil
+	}
+	revObjs = append(revObjs, revObj)
+	for len(revObjs) > limit {
+		klog.V(4).Infof("delete old inventory revision: %s/%s", revObjs[0].GetNamespace(), revObjs[0].GetName())
+		if err := cic.deleteInventoryObjByName(revObjs[0], dryRun); err != nil {
+			return rev, fmt.Errorf("failed to delete old inventory revision: %w", err)
+		}
+		revObjs = revObjs[1:]
+	}
+	return rev, nil
+}
+
+// getClusterRevisions returns the ConfigMaps which record the revisions of
+// the passed inventory, ordered by revision number.
+func (cic *ClusterClient) getClusterRevisions(localInv Info) (object.UnstructuredSet, error) {
+	id := localInv.ID()
+	if id == "" {
+		return nil, fmt.Errorf("inventory object has no inventory id: %s/%s", localInv.Namespace(), localInv.Name())
+	}
+	labelSelector := fmt.Sprintf("%s=%s", common.InventoryRevisionLabel, id)
+	klog.V(4).Infof("inventory revisions fetch by label (namespace: %q, selector: %q)", localInv.Namespace(), labelSelector)
+	uList, err := cic.dc.Resource(configMapGVR).Namespace(localInv.Namespace()).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory revisions from cluster: %w", err)
+	}
+	revObjs := object.UnstructuredSet{}
+	for i := range uList.Items {
+		revObjs = append(revObjs, &uList.Items[i])
+	}
+	sort.SliceStable(revObjs, func(i, j int) bool {
+		return revisionNumber(revObjs[i]) < revisionNumber(revObjs[j])
+	})
+	return revObjs, nil
+}
+
+// deleteRevisions deletes the revision history of the passed inventory.
+func (cic *ClusterClient) deleteRevisions(localInv Info, dryRun common.DryRunStrategy) error {
+	if localInv.ID() == "" {
+		return nil
+	}
+	revObjs, err := cic.getClusterRevisions(localInv)
+	if err != nil {
+		return err
+	}
+	for _, revObj := range revObjs {
+		if err := cic.deleteInventoryObjByName(revObj, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revisionNumber returns the number of the passed revision, or 0 if it is
+// invalid.
+func revisionNumber(revObj *unstructured.Unstructured) int {
+	value, _, _ := unstructured.NestedString(revObj.Object, "data", revisionNumberKey)
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// CheckRevisionManifests returns an error if the passed manifests can not
+// be recorded with a revision. The manifests of Secrets are not recorded,
+// because the revisions are ConfigMaps, which do not protect their data
+// like Secrets do.
+func CheckRevisionManifests(manifests object.UnstructuredSet) error {
+	for _, obj := range manifests {
+		if obj.GroupVersionKind().GroupKind() == (schema.GroupKind{Kind: "Secret"}) {
+			return fmt.Errorf("revision manifests can not include Secrets: %s",
+				object.UnstructuredToObjMetadata(obj))
+		}
+	}
+	return nil
+}
+
+// revisionToConfigMap returns the immutable ConfigMap which records the
+// passed revision of the passed inventory object. Returns an error if the
+// ConfigMap is larger than maxRevisionSize.
+func revisionToConfigMap(inv *unstructured.Unstructured, rev Revision) (*unstructured.Unstructured, error) {
+	if err := CheckRevisionManifests(rev.Manifests); err != nil {
+		return nil, err
+	}
+	objStrs := make([]string, 0, len(rev.Objects))
+	for _, id := range rev.Objects {
+		objStrs = append(objStrs, id.String())
+	}
+	sort.Strings(objStrs)
+	data := map[string]string{
+		revisionNumberKey:    strconv.Itoa(rev.Number),
+		revisionObjectsKey:   strings.Join(objStrs, "\n"),
+		revisionHashKey:      rev.Hash,
+		revisionTimestampKey: rev.Timestamp.UTC().Format(time.RFC3339),
+	}
+	if len(rev.Manifests) > 0 {
+		manifests := make([]map[string]interface{}, 0, len(rev.Manifests))
+		for _, obj := range rev.Manifests {
+			manifests = append(manifests, obj.Object)
+		}
+		manifestsJSON, err := json.Marshal(manifests)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode revision manifests: %w", err)
+		}
+		data[revisionManifestsKey] = string(manifestsJSON)
+	}
+	revObj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	revObj.SetAPIVersion("v1")
+	revObj.SetKind("ConfigMap")
+	revObj.SetNamespace(inv.GetNamespace())
+	revObj.SetName(fmt.Sprintf("%s-rev-%d", inv.GetName(), rev.Number))
+	revObj.SetLabels(map[string]string{
+		common.InventoryRevisionLabel: inv.GetLabels()[common.InventoryLabel],
+	})
+	if err := unstructured.SetNestedStringMap(revObj.Object, data, "data"); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedField(revObj.Object, true, "immutable"); err != nil {
+		return nil, err
+	}
+	revJSON, err := revObj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode inventory revision: %w", err)
+	}
+	if len(revJSON) > maxRevisionSize {
+		return nil, fmt.Errorf("inventory revision %d is %d bytes, larger than the limit of %d bytes",
+			rev.Number, len(revJSON), maxRevisionSize)
+	}
+	return revObj, nil
+}
+
+// revisionFromConfigMap returns the revision recorded by the passed
+// ConfigMap.
+func revisionFromConfigMap(revObj *unstructured.Unstructured) (Revision, error) {
+	rev := Revision{}
+	data, _, err := unstructured.NestedStringMap(revObj.Object, "data")
+	if err != nil {
+		return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+	}
+	rev.Number, err = strconv.Atoi(data[revisionNumberKey])
+	if err != nil {
+		return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+	}
+	rev.Objects = object.ObjMetadataSet{}
+	if data[revisionObjectsKey] != "" {
+		for _, objStr := range strings.Split(data[revisionObjectsKey], "\n") {
+			id, err := object.ParseObjMetadata(objStr)
+			if err != nil {
+				return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+			}
+			rev.Objects = append(rev.Objects, id)
+		}
+	}
+	rev.Hash = data[revisionHashKey]
+	rev.Timestamp, err = time.Parse(time.RFC3339, data[revisionTimestampKey])
+	if err != nil {
+		return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+	}
+	if data[revisionManifestsKey] != "" {
+		var manifests []json.RawMessage
+		if err := json.Unmarshal([]byte(data[revisionManifestsKey]), &manifests); err != nil {
+			return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+		}
+		for _, manifest := range manifests {
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(manifest); err != nil {
+				return rev, fmt.Errorf("invalid inventory revision %s: %w", revObj.GetName(), err)
+			}
+			rev.Manifests = append(rev.Manifests, obj)
+		}
+	}
+	return rev, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func TestRevisionHistory(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+
+	timestamp := time.Date(2022, time.March, 1, 10, 0, 0, 0, time.UTC)
+	manifest := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      "a",
+				"namespace": testNamespace,
+			},
+			"spec": map[string]interface{}{
+				"priority": int64(10),
+			},
+		},
+	}
+	newRevision := func(names ...string) Revision {
+		objs := testObjs(names...)
+		return Revision{
+			Objects:   objs,
+			Hash:      objs.Hash(),
+			Timestamp: timestamp,
+		}
+	}
+
+	// A revision requires the inventory object.
+	_, err = invClient.AddRevision(inv, newRevision("a"), 2, common.DryRunNone)
+	assert.EqualError(t, err, "inventory object not found in cluster: "+testNamespace+"/"+inventoryObjName)
+
+	_, err = invClient.Merge(inv, testObjs("a"), common.DryRunNone)
+	require.NoError(t, err)
+	rev := newRevision("a")
+	rev.Manifests = object.UnstructuredSet{manifest}
+	rev, err = invClient.AddRevision(inv, rev, 2, common.DryRunNone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rev.Number)
+
+	// Dry-run does not record a revision.
+	_, err = invClient.AddRevision(inv, newRevision("a", "b"), 2, common.DryRunClient)
+	require.NoError(t, err)
+
+	revs, err := invClient.GetRevisions(inv)
+	require.NoError(t, err)
+	require.Len(t, revs, 1)
+	assert.Equal(t, rev, revs[0])
+
+	// The oldest revisions are deleted beyond the limit.
+	_, err = invClient.AddRevision(inv, newRevision("a", "b"), 2, common.DryRunNone)
+	require.NoError(t, err)
+	_, err = invClient.AddRevision(inv, newRevision(), 2, common.DryRunNone)
+	require.NoError(t, err)
+	revs, err = invClient.GetRevisions(inv)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	expected := newRevision("a", "b")
+	expected.Number = 2
+	assert.Equal(t, expected, revs[0])
+	expected = newRevision()
+	expected.Number = 3
+	assert.Equal(t, expected, revs[1])
+
+	// The revisions are immutable ConfigMaps, which are not inventory objects.
+	revObj, err := tf.FakeDynamicClient.Resource(configMapGVR).Namespace(testNamespace).
+		Get(context.TODO(), inventoryObjName+"-rev-3", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, IsInventoryObject(revObj))
+	immutable, _, _ := unstructured.NestedBool(revObj.Object, "immutable")
+	assert.True(t, immutable)
+	clusterObjs, err := invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a"), clusterObjs)
+
+	// The manifests of Secrets are not recorded.
+	secret := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"metadata": map[string]interface{}{
+				"name":      "b",
+				"namespace": testNamespace,
+			},
+			"data": map[string]interface{}{
+				"password": "c2VjcmV0",
+			},
+		},
+	}
+	rev = newRevision("a", "b")
+	rev.Manifests = object.UnstructuredSet{manifest, secret}
+	_, err = invClient.AddRevision(inv, rev, 2, common.DryRunNone)
+	assert.EqualError(t, err, "revision manifests can not include Secrets: "+testNamespace+"_b__Secret")
+
+	// Revisions larger than the size limit of objects are not recorded.
+	large := manifest.DeepCopy()
+	err = unstructured.SetNestedField(large.Object, strings.Repeat("x", maxRevisionSize), "spec", "hostname")
+	require.NoError(t, err)
+	rev = newRevision("a")
+	rev.Manifests = object.UnstructuredSet{large}
+	_, err = invClient.AddRevision(inv, rev, 2, common.DryRunNone)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "larger than the limit of 1048576 bytes")
+	revs, err = invClient.GetRevisions(inv)
+	require.NoError(t, err)
+	assert.Len(t, revs, 2)
+
+	// The revision history is deleted with the inventory object.
+	err = invClient.DeleteInventoryObj(inv, common.DryRunNone)
+	require.NoError(t, err)
+	revs, err = invClient.GetRevisions(inv)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+}
@@ -3,10 +3,14 @@
 
 package inventory
 
-import cmdutil "k8s.io/kubectl/pkg/cmd/util"
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
 
 var (
 	_ ClientFactory = ClusterClientFactory{}
+	_ ClientFactory = ResourceGroupClientFactory{}
 )
 
 // ClientFactory is a factory that constructs new Client instances.
@@ -15,9 +19,50 @@ type ClientFactory interface {
 }
 
 // ClusterClientFactory is a factory that creates instances of ClusterClient inventory client.
+// The inventory objects are stored as ConfigMaps, unless they are ResourceGroups.
 type ClusterClientFactory struct {
+	// ShardSizeLimit is the maximum encoded size, in bytes, of each
+	// inventory object. See ClusterClient.ShardSizeLimit. Zero uses
+	// DefaultShardSizeLimit, and a negative value does not shard new
+	// objects.
+	ShardSizeLimit int
+}
+
+func (ccf ClusterClientFactory) NewClient(factory cmdutil.Factory) (Client, error) {
+	client, err := NewClient(factory, wrapInventoryObjByKind, invInfoToUnstructured)
+	if err != nil {
+		return nil, err
+	}
+	if ccf.ShardSizeLimit != 0 {
+		client.ShardSizeLimit = ccf.ShardSizeLimit
+	}
+	return client, nil
+}
+
+// ResourceGroupClientFactory is a factory that creates instances of ClusterClient
+// inventory client for ResourceGroup inventory objects. The ResourceGroup CRD is
+// installed when the first inventory object is created.
+type ResourceGroupClientFactory struct {
+}
+
+func (ResourceGroupClientFactory) NewClient(factory cmdutil.Factory) (Client, error) {
+	return NewClient(factory, WrapResourceGroupObj, InvInfoToResourceGroup)
+}
+
+// wrapInventoryObjByKind wraps the passed inventory object with the
+// ResourceGroup if it is one, or with the ConfigMap otherwise.
+func wrapInventoryObjByKind(inv *unstructured.Unstructured) Storage {
+	if IsResourceGroup(inv) {
+		return WrapResourceGroupObj(inv)
+	}
+	return WrapInventoryObj(inv)
 }
 
-func (ClusterClientFactory) NewClient(factory cmdutil.Factory) (Client, error) {
-	return NewClient(factory, WrapInventoryObj, InvInfoToConfigMap)
+// invInfoToUnstructured returns the inventory object wrapped by the
+// passed ConfigMap or ResourceGroup Info.
+func invInfoToUnstructured(inv Info) *unstructured.Unstructured {
+	if rg := InvInfoToResourceGroup(inv); rg != nil {
+		return rg
+	}
+	return InvInfoToConfigMap(inv)
 }
@@ -6,18 +6,24 @@ package inventory
 import (
 	"context"
 	"fmt"
+	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/klog/v2"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/kubectl/pkg/util"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/yaml"
 )
 
 // Client expresses an interface for interacting with
@@ -45,6 +51,53 @@ type Client interface {
 	GetClusterInventoryObjs(inv Info) (object.UnstructuredSet, error)
 }
 
+// StatusClient is implemented by inventory clients that can persist the
+// status of the objects in the inventory object.
+type StatusClient interface {
+	// GetClusterObjStatus returns the actuation and reconcile status of the
+	// objects, as last persisted in the cluster inventory object. Returns an
+	// empty list if the inventory object does not exist or does not store
+	// the status of its objects.
+	GetClusterObjStatus(inv Info) ([]actuation.ObjectStatus, error)
+	// UpdateObjStatus persists the actuation and reconcile status of the
+	// objects in the cluster inventory object. Returns an error if the
+	// inventory object cannot store the status of its objects.
+	UpdateObjStatus(inv Info, objStatus []actuation.ObjectStatus, dryRun common.DryRunStrategy) error
+}
+
+// ResultClient is implemented by inventory clients that can record the
+// result of the last run, the actuation and reconcile status of each
+// object, in the inventory object. Unlike the status persisted by the
+// StatusClient, the result is kept after the run completes, so that it can
+// be shown without reading every object from the cluster.
+type ResultClient interface {
+	// GetClusterObjResults returns the actuation and reconcile status of the
+	// objects at the end of their last run. Returns an empty list if the
+	// inventory object does not exist or does not record results.
+	GetClusterObjResults(inv Info) ([]actuation.ObjectStatus, error)
+	// ReplaceWithResults replaces the set of objects stored in the inventory
+	// object, like Replace, and records the passed results of the stored
+	// objects. Stored objects without a passed result keep their previous
+	// result.
+	ReplaceWithResults(inv Info, objs object.ObjMetadataSet, results []actuation.ObjectStatus, dryRun common.DryRunStrategy) error
+}
+
+var (
+	// crdGVR is the resource of CustomResourceDefinitions, used to install
+	// inventory types on demand.
+	crdGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+	// crdPollInterval is the interval between checks whether an installed
+	// inventory CRD is established.
+	crdPollInterval = time.Second
+	// crdEstablishTimeout is how long to wait for an installed inventory
+	// CRD to be established.
+	crdEstablishTimeout = time.Minute
+)
+
 // ClusterClient is a concrete implementation of the
 // Client interface.
 type ClusterClient struct {
@@ -53,9 +106,28 @@ type ClusterClient struct {
 	mapper                meta.RESTMapper
 	InventoryFactoryFunc  StorageFactoryFunc
 	invToUnstructuredFunc ToUnstructuredFunc
+	// ShardSizeLimit is the maximum encoded size, in bytes, of each
+	// inventory object, including the results of its objects. If the
+	// objects of an inventory do not fit, they are stored across several
+	// inventory objects (shards) with the same inventory id label.
+	// NewClient sets it to DefaultShardSizeLimit. Zero or less does not
+	// shard new objects, but the shards of existing inventories are still
+	// read and updated.
+	ShardSizeLimit int
 }
 
 var _ Client = &ClusterClient{}
+var _ StatusClient = &ClusterClient{}
+var _ ResultClient = &ClusterClient{}
+
+// conflictRetryPolicy bounds how often Merge and Replace read and write the
+// inventory again when it is changed concurrently.
+var conflictRetryPolicy = retry.Policy{
+	Attempts:   5,
+	Backoff:    100 * time.Millisecond,
+	MaxBackoff: 2 * time.Second,
+	Retriable:  isInventoryConflict,
+}
 
 // NewClient returns a concrete implementation of the
 // Client interface or an error.
@@ -80,6 +152,7 @@ func NewClient(factory cmdutil.Factory,
 		mapper:                mapper,
 		InventoryFactoryFunc:  invFunc,
 		invToUnstructuredFunc: invToUnstructuredFunc,
+		ShardSizeLimit:        DefaultShardSizeLimit,
 	}
 	return &clusterClient, nil
 }
@@ -89,9 +162,23 @@ func NewClient(factory cmdutil.Factory,
 // inventory object. Returns the set differrence of the cluster inventory
 // objects and the currently applied objects. This is the set of objects
 // to prune. Creates the initial cluster inventory object storing the passed
-// objects if an inventory object does not exist. Returns an error if one
-// occurred.
+// objects if an inventory object does not exist. If the inventory object
+// is changed or created concurrently, the union is computed again from the
+// new cluster inventory, up to a bounded number of attempts, after which a
+// ConflictError is returned. Returns an error if one occurred.
 func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) (object.ObjMetadataSet, error) {
+	var pruneIds object.ObjMetadataSet
+	err := cic.retryOnConflict(localInv, func() error {
+		var err error
+		pruneIds, err = cic.merge(localInv, objs, dryRun)
+		return err
+	})
+	return pruneIds, err
+}
+
+// merge reads the cluster inventory object once, and stores the union of
+// the passed objects with its objects. See Merge.
+func (cic *ClusterClient) merge(localInv Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) (object.ObjMetadataSet, error) {
 	pruneIds := object.ObjMetadataSet{}
 	invObj := cic.invToUnstructuredFunc(localInv)
 	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
@@ -99,9 +186,15 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 		return pruneIds, err
 	}
 	if clusterInv == nil {
-		// Wrap inventory object and store the inventory in it.
+		// Wrap inventory object and store the inventory in it. The objects
+		// that do not fit are stored in additional shards.
+		sizes, capacity, err := cic.shardSizes(invObj, nil, objs, nil)
+		if err != nil {
+			return nil, err
+		}
+		shards := assignShards(nil, objs, sizes, capacity)
 		inv := cic.InventoryFactoryFunc(invObj)
-		if err := inv.Store(objs); err != nil {
+		if err := inv.Store(shards[0]); err != nil {
 			return nil, err
 		}
 		invInfo, err := inv.GetObject()
@@ -113,6 +206,11 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 		if err != nil {
 			return nil, err
 		}
+		if len(shards) > 1 && !dryRun.ClientOrServerDryRun() {
+			if err := cic.createShards(createdObj, shards[1:], dryRun); err != nil {
+				return nil, err
+			}
+		}
 		// Status update requires the latest ResourceVersion
 		invInfo.SetResourceVersion(createdObj.GetResourceVersion())
 		if err := cic.updateStatus(invInfo, dryRun); err != nil {
@@ -128,25 +226,12 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 		unionObjs := clusterObjs.Union(objs)
 		klog.V(4).Infof("num objects to prune: %d", len(pruneIds))
 		klog.V(4).Infof("num merged objects to store in inventory: %d", len(unionObjs))
-		wrappedInv := cic.InventoryFactoryFunc(clusterInv)
-		if err = wrappedInv.Store(unionObjs); err != nil {
-			return pruneIds, err
-		}
-		clusterInv, err = wrappedInv.GetObject()
-		if err != nil {
-			return pruneIds, err
-		}
 		if dryRun.ClientOrServerDryRun() {
 			return pruneIds, nil
 		}
-		if !objs.Equal(clusterObjs) {
-			klog.V(4).Infof("update cluster inventory: %s/%s", clusterInv.GetNamespace(), clusterInv.GetName())
-			appliedObj, err := cic.applyInventoryObj(clusterInv, dryRun)
-			if err != nil {
-				return pruneIds, err
-			}
-			// Status update requires the latest ResourceVersion
-			clusterInv.SetResourceVersion(appliedObj.GetResourceVersion())
+		clusterInv, err = cic.storeShards(clusterInv, unionObjs, nil, dryRun)
+		if err != nil {
+			return pruneIds, err
 		}
 		if err := cic.updateStatus(clusterInv, dryRun); err != nil {
 			return pruneIds, err
@@ -159,33 +244,42 @@ func (cic *ClusterClient) Merge(localInv Info, objs object.ObjMetadataSet, dryRu
 // Replace stores the passed objects in the cluster inventory object, or
 // an error if one occurred.
 func (cic *ClusterClient) Replace(localInv Info, objs object.ObjMetadataSet, dryRun common.DryRunStrategy) error {
+	return cic.ReplaceWithResults(localInv, objs, nil, dryRun)
+}
+
+// ReplaceWithResults stores the passed objects in the cluster inventory
+// object, with the passed results if the inventory object can record
+// them, or an error if one occurred. If the inventory object is changed
+// concurrently, it is read again and the objects are stored again, up to a
+// bounded number of attempts, after which a ConflictError is returned.
+func (cic *ClusterClient) ReplaceWithResults(localInv Info, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
 	// Skip entire function for dry-run.
 	if dryRun.ClientOrServerDryRun() {
 		klog.V(4).Infoln("dry-run replace inventory object: not applied")
 		return nil
 	}
-	clusterObjs, err := cic.GetClusterObjs(localInv)
-	if err != nil {
-		return fmt.Errorf("failed to read inventory objects from cluster: %w", err)
-	}
+	return cic.retryOnConflict(localInv, func() error {
+		return cic.replace(localInv, objs, results, dryRun)
+	})
+}
+
+// replace reads the cluster inventory object once, and stores the passed
+// objects and results in it. See ReplaceWithResults.
+func (cic *ClusterClient) replace(localInv Info, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
 	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
 	if err != nil {
 		return fmt.Errorf("failed to read inventory from cluster: %w", err)
 	}
-	clusterInv, err = cic.replaceInventory(clusterInv, objs)
+	if clusterInv == nil {
+		return fmt.Errorf("inventory object not found in cluster: %s/%s", localInv.Namespace(), localInv.Name())
+	}
+	klog.V(4).Infof("replace cluster inventory %d objects", len(objs))
+	clusterInv, err = cic.storeShards(clusterInv, objs, results, dryRun)
 	if err != nil {
 		return err
 	}
-	if !objs.Equal(clusterObjs) {
-		klog.V(4).Infof("replace cluster inventory: %s/%s", clusterInv.GetNamespace(), clusterInv.GetName())
-		klog.V(4).Infof("replace cluster inventory %d objects", len(objs))
-		appliedObj, err := cic.applyInventoryObj(clusterInv, dryRun)
-		if err != nil {
-			return fmt.Errorf("failed to write updated inventory to cluster: %w", err)
-		}
-		// Status update requires the latest ResourceVersion
-		clusterInv.SetResourceVersion(appliedObj.GetResourceVersion())
-	}
 	if err := cic.updateStatus(clusterInv, dryRun); err != nil {
 		return err
 	}
@@ -194,10 +288,22 @@ func (cic *ClusterClient) Replace(localInv Info, objs object.ObjMetadataSet, dry
 
 // replaceInventory stores the passed objects into the passed inventory object.
 func (cic *ClusterClient) replaceInventory(inv *unstructured.Unstructured, objs object.ObjMetadataSet) (*unstructured.Unstructured, error) {
+	return cic.storeInventory(inv, objs, nil)
+}
+
+// storeInventory stores the passed objects into the passed inventory
+// object, with the passed results if the inventory object can record them.
+func (cic *ClusterClient) storeInventory(inv *unstructured.Unstructured, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus) (*unstructured.Unstructured, error) {
 	wrappedInv := cic.InventoryFactoryFunc(inv)
 	if err := wrappedInv.Store(objs); err != nil {
 		return nil, err
 	}
+	if resultStorage, ok := wrappedInv.(ResultStorage); ok && results != nil {
+		if err := resultStorage.StoreResults(results); err != nil {
+			return nil, err
+		}
+	}
 	clusterInv, err := wrappedInv.GetObject()
 	if err != nil {
 		return nil, err
@@ -205,19 +311,32 @@ func (cic *ClusterClient) replaceInventory(inv *unstructured.Unstructured, objs
 	return clusterInv, nil
 }
 
-// DeleteInventoryObj deletes the inventory object from the cluster.
+// DeleteInventoryObj deletes the inventory object from the cluster, with
+// its shards and its revision history.
 func (cic *ClusterClient) DeleteInventoryObj(localInv Info, dryRun common.DryRunStrategy) error {
 	if localInv == nil {
 		return fmt.Errorf("retrieving cluster inventory object with nil local inventory")
 	}
 	switch localInv.Strategy() {
 	case NameStrategy:
-		return cic.deleteInventoryObjByName(cic.invToUnstructuredFunc(localInv), dryRun)
+		invObj := cic.invToUnstructuredFunc(localInv)
+		if err := cic.deleteInventoryObjByName(invObj, dryRun); err != nil {
+			return err
+		}
+		if invObj == nil {
+			return nil
+		}
+		if err := cic.deleteShards(invObj, dryRun); err != nil {
+			return err
+		}
 	case LabelStrategy:
-		return cic.deleteInventoryObjsByLabel(localInv, dryRun)
+		if err := cic.deleteInventoryObjsByLabel(localInv, dryRun); err != nil {
+			return err
+		}
 	default:
 		panic(fmt.Errorf("unknown inventory strategy: %s", localInv.Strategy()))
 	}
+	return cic.deleteRevisions(localInv, dryRun)
 }
 
 func (cic *ClusterClient) deleteInventoryObjsByLabel(inv Info, dryRun common.DryRunStrategy) error {
@@ -229,12 +348,15 @@ func (cic *ClusterClient) deleteInventoryObjsByLabel(inv Info, dryRun common.Dry
 		if err := cic.deleteInventoryObjByName(invObj, dryRun); err != nil {
 			return err
 		}
+		if err := cic.deleteShards(invObj, dryRun); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// GetClusterObjs returns the objects stored in the cluster inventory object, or
-// an error if one occurred.
+// GetClusterObjs returns the objects stored in the cluster inventory object,
+// and in its shards, or an error if one occurred.
 func (cic *ClusterClient) GetClusterObjs(localInv Info) (object.ObjMetadataSet, error) {
 	var objs object.ObjMetadataSet
 	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
@@ -246,7 +368,15 @@ func (cic *ClusterClient) GetClusterObjs(localInv Info) (object.ObjMetadataSet,
 		return objs, nil
 	}
 	wrapped := cic.InventoryFactoryFunc(clusterInv)
-	return wrapped.Load()
+	objs, err = wrapped.Load()
+	if err != nil {
+		return objs, err
+	}
+	shardObjs, err := cic.getClusterShardObjs(clusterInv)
+	if err != nil {
+		return objs, err
+	}
+	return objs.Union(shardObjs), nil
 }
 
 // getClusterInventoryObj returns a pointer to the cluster inventory object, or
@@ -281,6 +411,11 @@ func (cic *ClusterClient) getClusterInventoryObjsByLabel(inv Info) (object.Unstr
 	localObj := object.UnstructuredToObjMetadata(localInv)
 	mapping, err := cic.getMapping(localInv)
 	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The inventory type is not installed, so there are no
+			// inventory objects yet.
+			return object.UnstructuredSet{}, nil
+		}
 		return nil, err
 	}
 	groupResource := mapping.Resource.GroupResource().String()
@@ -300,6 +435,10 @@ func (cic *ClusterClient) getClusterInventoryObjsByLabel(inv Info) (object.Unstr
 	}
 	var invList []*unstructured.Unstructured
 	for i := range uList.Items {
+		// The shards are part of the inventory object they belong to.
+		if isShard(&uList.Items[i]) {
+			continue
+		}
 		invList = append(invList, &uList.Items[i])
 	}
 	return invList, nil
@@ -313,6 +452,11 @@ func (cic *ClusterClient) getClusterInventoryObjsByName(inv Info) (object.Unstru
 
 	mapping, err := cic.getMapping(localInv)
 	if err != nil {
+		if meta.IsNoMatchError(err) {
+			// The inventory type is not installed, so there are no
+			// inventory objects yet.
+			return object.UnstructuredSet{}, nil
+		}
 		return nil, err
 	}
 
@@ -346,7 +490,41 @@ func (cic *ClusterClient) GetClusterInventoryObjs(inv Info) (object.Unstructured
 	return clusterInvObjects, err
 }
 
+// retryOnConflict calls the passed function, which reads and writes the
+// cluster inventory object, again while it fails because the inventory
+// object was changed or created concurrently, up to the attempts of
+// conflictRetryPolicy. Returns a ConflictError if the attempts run out.
+func (cic *ClusterClient) retryOnConflict(localInv Info, fn func() error) error {
+	attempts := 0
+	err := conflictRetryPolicy.Do(context.TODO(), func() error {
+		attempts++
+		return fn()
+	}, func(attempt int, err error, delay time.Duration) {
+		klog.V(4).Infof("inventory %s/%s changed concurrently (attempt %d): retrying in %s: %v",
+			localInv.Namespace(), localInv.Name(), attempt, delay, err)
+	})
+	if err != nil && isInventoryConflict(err) {
+		return &ConflictError{
+			Namespace: localInv.Namespace(),
+			Name:      localInv.Name(),
+			Attempts:  attempts,
+			err:       err,
+		}
+	}
+	return err
+}
+
+// isInventoryConflict returns true if the passed error means that an
+// inventory object was changed since it was read, or was created since it
+// was found missing.
+func isInventoryConflict(err error) bool {
+	return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+}
+
 // applyInventoryObj applies the passed inventory object to the APIServer.
+// The update fails with a conflict if the inventory object was changed
+// since the passed object was read, because the passed object carries the
+// resourceVersion it was read with.
 func (cic *ClusterClient) applyInventoryObj(obj *unstructured.Unstructured, dryRun common.DryRunStrategy) (*unstructured.Unstructured, error) {
 	if dryRun.ClientOrServerDryRun() {
 		klog.V(4).Infof("dry-run apply inventory object: not applied")
@@ -383,6 +561,13 @@ func (cic *ClusterClient) createInventoryObj(obj *unstructured.Unstructured, dry
 	}
 
 	mapping, err := cic.getMapping(obj)
+	if err != nil && meta.IsNoMatchError(err) && IsResourceGroup(obj) {
+		// Install the ResourceGroup type on demand.
+		if err := cic.installCRD(ResourceGroupCRD); err != nil {
+			return nil, err
+		}
+		mapping, err = cic.getMapping(obj)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -392,6 +577,51 @@ func (cic *ClusterClient) createInventoryObj(obj *unstructured.Unstructured, dry
 		Create(context.TODO(), obj, metav1.CreateOptions{})
 }
 
+// installCRD creates the passed CustomResourceDefinition if it does not
+// already exist, and waits until it is established. Resets the RESTMapper
+// and the discovery client afterwards, so the new type can be found.
+func (cic *ClusterClient) installCRD(crdManifest []byte) error {
+	crd := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal(crdManifest, &crd.Object); err != nil {
+		return fmt.Errorf("failed to decode inventory CRD: %w", err)
+	}
+	client := cic.dc.Resource(crdGVR)
+	klog.V(4).Infof("installing inventory CRD: %s", crd.GetName())
+	_, err := client.Create(context.TODO(), crd, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to install inventory CRD %s: %w", crd.GetName(), err)
+	}
+	err = wait.PollImmediate(crdPollInterval, crdEstablishTimeout, func() (bool, error) {
+		clusterCRD, err := client.Get(context.TODO(), crd.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return isCRDEstablished(clusterCRD), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for inventory CRD %s to be established: %w", crd.GetName(), err)
+	}
+	cic.discoveryClient.Invalidate()
+	meta.MaybeResetRESTMapper(cic.mapper)
+	return nil
+}
+
+// isCRDEstablished returns true if the passed CustomResourceDefinition has
+// the Established condition.
+func isCRDEstablished(crd *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
 // deleteInventoryObjByName deletes the passed inventory object from the APIServer, or
 // an error if one occurs.
 func (cic *ClusterClient) deleteInventoryObjByName(obj *unstructured.Unstructured, dryRun common.DryRunStrategy) error {
@@ -437,6 +667,95 @@ func (cic *ClusterClient) ApplyInventoryNamespace(obj *unstructured.Unstructured
 	return err
 }
 
+// GetClusterObjStatus returns the status of the objects stored in the
+// cluster inventory object, or an empty list if the inventory object does
+// not exist. The status is read with the StatusStorage of the inventory
+// object, if it has one, or from the "status.objects" field otherwise.
+func (cic *ClusterClient) GetClusterObjStatus(localInv Info) ([]actuation.ObjectStatus, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory from cluster: %w", err)
+	}
+	if clusterInv == nil {
+		return nil, nil
+	}
+	if statusStorage, ok := cic.InventoryFactoryFunc(clusterInv).(StatusStorage); ok {
+		return statusStorage.LoadStatus()
+	}
+	return ObjStatusFromUnstructured(clusterInv)
+}
+
+// UpdateObjStatus stores the status of the objects in the cluster inventory
+// object, with the StatusStorage of the inventory object, if it has one, or
+// in the "status.objects" field otherwise. The inventory object must
+// already exist. Returns an error for inventory types without a status
+// subresource, like the ConfigMap.
+func (cic *ClusterClient) UpdateObjStatus(localInv Info, objStatus []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
+	if dryRun.ClientOrServerDryRun() {
+		klog.V(4).Infoln("dry-run update inventory object status: not updated")
+		return nil
+	}
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
+	if err != nil {
+		return fmt.Errorf("failed to read inventory from cluster: %w", err)
+	}
+	if clusterInv == nil {
+		return fmt.Errorf("inventory object not found in cluster: %s/%s", localInv.Namespace(), localInv.Name())
+	}
+	mapping, err := cic.getMapping(clusterInv)
+	if err != nil {
+		return err
+	}
+	hasStatus, err := cic.hasSubResource(clusterInv.GetAPIVersion(), mapping.Resource.Resource, "status")
+	if err != nil {
+		return err
+	}
+	if !hasStatus {
+		return fmt.Errorf("inventory object %s/%s cannot store object status: %s has no status subresource",
+			localInv.Namespace(), localInv.Name(), clusterInv.GetKind())
+	}
+	if statusStorage, ok := cic.InventoryFactoryFunc(clusterInv).(StatusStorage); ok {
+		err = statusStorage.StoreStatus(objStatus)
+	} else {
+		err = SetObjStatusInUnstructured(clusterInv, objStatus)
+	}
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("update cluster inventory status: %d objects", len(objStatus))
+	return cic.updateStatus(clusterInv, dryRun)
+}
+
+// GetClusterObjResults returns the result of the last run of the objects
+// stored in the cluster inventory object and in its shards, or an empty
+// list if the inventory object does not exist or does not record results.
+func (cic *ClusterClient) GetClusterObjResults(localInv Info) ([]actuation.ObjectStatus, error) {
+	clusterInv, err := cic.GetClusterInventoryInfo(localInv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory from cluster: %w", err)
+	}
+	if clusterInv == nil {
+		return nil, nil
+	}
+	shards, err := cic.getClusterShards(clusterInv)
+	if err != nil {
+		return nil, err
+	}
+	var results []actuation.ObjectStatus
+	for _, invObj := range append(object.UnstructuredSet{clusterInv}, shards...) {
+		resultStorage, ok := cic.InventoryFactoryFunc(invObj).(ResultStorage)
+		if !ok {
+			continue
+		}
+		invResults, err := resultStorage.LoadResults()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, invResults...)
+	}
+	return results, nil
+}
+
 // getMapping returns the RESTMapping for the provided resource.
 func (cic *ClusterClient) getMapping(obj *unstructured.Unstructured) (*meta.RESTMapping, error) {
 	return cic.mapper.RESTMapping(obj.GroupVersionKind().GroupKind(), obj.GroupVersionKind().Version)
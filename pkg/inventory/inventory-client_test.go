@@ -4,15 +4,21 @@
 package inventory
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/resource"
 	clienttesting "k8s.io/client-go/testing"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apply/retry"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
@@ -454,3 +460,89 @@ func toReactionFunc(objs object.ObjMetadataSet) clienttesting.ReactionFunc {
 		return true, list, err
 	}
 }
+
+func TestMergeConflict(t *testing.T) {
+	defer func(policy retry.Policy) { conflictRetryPolicy = policy }(conflictRetryPolicy)
+	conflictRetryPolicy.Backoff = time.Millisecond
+
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	_, err = invClient.Merge(inv, testObjs("a"), common.DryRunNone)
+	require.NoError(t, err)
+
+	// Another run stores "b" after the inventory is read, so the first
+	// update conflicts, and the union is computed again with "b".
+	conflicts := 0
+	tf.FakeDynamicClient.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		clusterInv, err := tf.FakeDynamicClient.Tracker().Get(configMapGVR, testNamespace, inventoryObjName)
+		require.NoError(t, err)
+		changedInv := clusterInv.(*unstructured.Unstructured).DeepCopy()
+		wrapped := WrapInventoryObj(changedInv)
+		require.NoError(t, wrapped.Store(testObjs("a", "b")))
+		changedInv, err = wrapped.GetObject()
+		require.NoError(t, err)
+		require.NoError(t, tf.FakeDynamicClient.Tracker().Update(configMapGVR, changedInv, testNamespace))
+		return true, nil, apierrors.NewConflict(configMapGVR.GroupResource(), inventoryObjName,
+			fmt.Errorf("the object has been modified"))
+	})
+
+	pruneIds, err := invClient.Merge(inv, testObjs("a", "c"), common.DryRunNone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, conflicts)
+	assertObjsEqual(t, testObjs("b"), pruneIds)
+	clusterObjs, err := invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a", "b", "c"), clusterObjs)
+}
+
+func TestConflictRetriesRunOut(t *testing.T) {
+	defer func(policy retry.Policy) { conflictRetryPolicy = policy }(conflictRetryPolicy)
+	conflictRetryPolicy.Backoff = time.Millisecond
+
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	_, err = invClient.Merge(inv, testObjs("a"), common.DryRunNone)
+	require.NoError(t, err)
+
+	updates := 0
+	tf.FakeDynamicClient.PrependReactor("update", "configmaps", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		updates++
+		return true, nil, apierrors.NewConflict(configMapGVR.GroupResource(), inventoryObjName,
+			fmt.Errorf("the object has been modified"))
+	})
+
+	_, err = invClient.Merge(inv, testObjs("a", "b"), common.DryRunNone)
+	var conflictErr *ConflictError
+	require.True(t, errors.As(err, &conflictErr), "unexpected error: %v", err)
+	assert.Equal(t, conflictRetryPolicy.Attempts, conflictErr.Attempts)
+	assert.Equal(t, conflictRetryPolicy.Attempts, updates)
+	assert.Equal(t, inventoryObjName, conflictErr.Name)
+	assert.True(t, apierrors.IsConflict(err))
+	// The conflict was already retried, so it is not retried again by
+	// the callers.
+	assert.False(t, retry.IsRetriable(err))
+
+	taskRetry := retry.Policy{Attempts: 3, Backoff: time.Millisecond}
+	err = taskRetry.Do(context.Background(), func() error {
+		return invClient.Replace(inv, testObjs("b"), common.DryRunNone)
+	}, nil)
+	require.True(t, errors.As(err, &conflictErr), "unexpected error: %v", err)
+	assert.Equal(t, 2*conflictRetryPolicy.Attempts, updates)
+
+	// The inventory is unchanged.
+	clusterObjs, err := invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a"), clusterObjs)
+}
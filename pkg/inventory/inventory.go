@@ -17,6 +17,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
@@ -36,6 +37,27 @@ type Storage interface {
 	GetObject() (*unstructured.Unstructured, error)
 }
 
+// StatusStorage is implemented by Storage that can persist the
+// actuation and reconcile status of the inventory objects in the
+// inventory object.
+type StatusStorage interface {
+	// LoadStatus retrieves the status of the objects from the inventory object
+	LoadStatus() ([]actuation.ObjectStatus, error)
+	// StoreStatus stores the status of the objects in the inventory object
+	StoreStatus(objStatus []actuation.ObjectStatus) error
+}
+
+// ResultStorage is implemented by Storage that can record the result of
+// the last run, the actuation and reconcile status of each inventory
+// object, alongside the object metadata.
+type ResultStorage interface {
+	// LoadResults retrieves the result of the last run from the inventory object
+	LoadResults() ([]actuation.ObjectStatus, error)
+	// StoreResults stores the result of the last run for the stored objects.
+	// Actual storing happens in "GetObject".
+	StoreResults(results []actuation.ObjectStatus) error
+}
+
 // StorageFactoryFunc creates the object which implements the Inventory
 // interface from the passed info object.
 type StorageFactoryFunc func(*unstructured.Unstructured) Storage
@@ -5,7 +5,12 @@
 
 package inventory
 
-import "sigs.k8s.io/cli-utils/pkg/object"
+import (
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
 
 const noInventoryErrorStr = `Package uninitialized. Please run "init" command.
 
@@ -72,3 +77,54 @@ func (e *NeedAdoptionError) Error() string {
 func NewNeedAdoptionError(err error) *NeedAdoptionError {
 	return &NeedAdoptionError{err: err}
 }
+
+// LockHeldError is returned when an inventory is locked by another run.
+type LockHeldError struct {
+	Namespace string
+	Name      string
+	// Holder identifies the run which holds the lock.
+	Holder string
+	// RenewTime is the last time the holder renewed the lock.
+	RenewTime time.Time
+}
+
+func (e *LockHeldError) Error() string {
+	return fmt.Sprintf("inventory is locked by %q (lock: %s/%s, renewed at %s)",
+		e.Holder, e.Namespace, e.Name, e.RenewTime.Format(time.RFC3339))
+}
+
+// LockNamespaceNotFoundError is returned when an inventory cannot be
+// locked, because its namespace does not exist.
+type LockNamespaceNotFoundError struct {
+	Namespace string
+}
+
+func (e *LockNamespaceNotFoundError) Error() string {
+	return fmt.Sprintf("inventory cannot be locked: namespace %q not found", e.Namespace)
+}
+
+// ConflictError is returned when the inventory could not be updated,
+// because the inventory object kept being changed concurrently until the
+// retries ran out.
+type ConflictError struct {
+	Namespace string
+	Name      string
+	// Attempts is the number of times the inventory was read and written.
+	Attempts int
+	err      error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("inventory %s/%s was changed concurrently, giving up after %d attempts: %v",
+		e.Namespace, e.Name, e.Attempts, e.err)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.err
+}
+
+// RetriesExhausted implements retry.ExhaustedError, so that the conflict,
+// which was already retried, is not retried again by the callers.
+func (e *ConflictError) RetriesExhausted() bool {
+	return true
+}
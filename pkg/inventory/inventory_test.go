@@ -7,9 +7,12 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
@@ -412,6 +415,69 @@ func TestLegacyInventoryName(t *testing.T) {
 	}
 }
 
+func TestConfigMapResults(t *testing.T) {
+	pod1 := ignoreErrInfoToObjMeta(pod1Info)
+	pod2 := ignoreErrInfoToObjMeta(pod2Info)
+	pod3 := ignoreErrInfoToObjMeta(pod3Info)
+	pod1Result := actuation.ObjectStatus{
+		ObjectReference: ObjectReferenceFromObjMetadata(pod1),
+		Strategy:        actuation.ActuationStrategyApply,
+		Actuation:       actuation.ActuationSucceeded,
+		Reconcile:       actuation.ReconcileSucceeded,
+		UID:             "pod-1-uid",
+		Generation:      3,
+	}
+	pod2Result := actuation.ObjectStatus{
+		ObjectReference: ObjectReferenceFromObjMetadata(pod2),
+		Strategy:        actuation.ActuationStrategyDelete,
+		Actuation:       actuation.ActuationFailed,
+		Reconcile:       actuation.ReconcileTimeout,
+	}
+
+	wrapped := WrapInventoryObj(copyInventoryInfo())
+	require.NoError(t, wrapped.Store(object.ObjMetadataSet{pod1, pod2, pod3}))
+	require.NoError(t, wrapped.(ResultStorage).StoreResults([]actuation.ObjectStatus{pod1Result, pod2Result}))
+	inv, err := wrapped.GetObject()
+	require.NoError(t, err)
+	data, _, _ := unstructured.NestedStringMap(inv.Object, "data")
+	assert.Equal(t, map[string]string{
+		pod1.String(): "1,A,S,S,3,pod-1-uid",
+		pod2.String(): "1,D,F,T,0,",
+		pod3.String(): "",
+	}, data)
+
+	results, err := WrapInventoryObj(inv).(ResultStorage).LoadResults()
+	require.NoError(t, err)
+	assert.Equal(t, []actuation.ObjectStatus{pod1Result, pod2Result}, results)
+
+	// Retained objects keep their result, unless a new one is stored.
+	pod2Result.Actuation = actuation.ActuationSucceeded
+	wrapped = WrapInventoryObj(inv)
+	require.NoError(t, wrapped.Store(object.ObjMetadataSet{pod1, pod2}))
+	require.NoError(t, wrapped.(ResultStorage).StoreResults([]actuation.ObjectStatus{pod2Result}))
+	inv, err = wrapped.GetObject()
+	require.NoError(t, err)
+	results, err = WrapInventoryObj(inv).(ResultStorage).LoadResults()
+	require.NoError(t, err)
+	assert.Equal(t, []actuation.ObjectStatus{pod1Result, pod2Result}, results)
+
+	// Results with an unknown encoding version are ignored.
+	require.NoError(t, unstructured.SetNestedStringMap(inv.Object, map[string]string{
+		pod1.String(): "2,A,S,S,3,pod-1-uid",
+		pod2.String(): "1,D,S,T,0,",
+	}, "data"))
+	results, err = WrapInventoryObj(inv).(ResultStorage).LoadResults()
+	require.NoError(t, err)
+	assert.Equal(t, []actuation.ObjectStatus{pod2Result}, results)
+
+	// Invalid results are an error.
+	require.NoError(t, unstructured.SetNestedStringMap(inv.Object, map[string]string{
+		pod1.String(): "1,A,X,S,3,pod-1-uid",
+	}, "data"))
+	_, err = WrapInventoryObj(inv).(ResultStorage).LoadResults()
+	assert.EqualError(t, err, "invalid inventory object status: "+pod1.String()+`: "1,A,X,S,3,pod-1-uid"`)
+}
+
 func copyInventoryInfo() *unstructured.Unstructured {
 	return inventoryObj.DeepCopy()
 }
@@ -4,14 +4,21 @@
 // Introduces the ConfigMap struct which implements
 // the Inventory interface. The ConfigMap wraps a
 // ConfigMap resource which stores the set of inventory
-// (object metadata).
+// (object metadata), and the result of the last run of
+// each object.
 
 package inventory
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/common"
 	"sigs.k8s.io/cli-utils/pkg/object"
 )
@@ -40,14 +47,18 @@ func InvInfoToConfigMap(inv Info) *unstructured.Unstructured {
 
 // ConfigMap wraps a ConfigMap resource and implements
 // the Inventory interface. This wrapper loads and stores the
-// object metadata (inventory) to and from the wrapped ConfigMap.
+// object metadata (inventory) to and from the keys of the
+// wrapped ConfigMap data, and the result of the last run of
+// each object to and from the values.
 type ConfigMap struct {
 	inv      *unstructured.Unstructured
 	objMetas object.ObjMetadataSet
+	results  map[object.ObjMetadata]actuation.ObjectStatus
 }
 
 var _ Info = &ConfigMap{}
 var _ Storage = &ConfigMap{}
+var _ ResultStorage = &ConfigMap{}
 
 func (icm *ConfigMap) Name() string {
 	return icm.inv.GetName()
@@ -100,10 +111,24 @@ func (icm *ConfigMap) Store(objMetas object.ObjMetadataSet) error {
 }
 
 // GetObject returns the wrapped object (ConfigMap) as a resource.Info
-// or an error if one occurs.
+// or an error if one occurs. The stored objects keep the result of
+// their last run, unless a new result was stored.
 func (icm *ConfigMap) GetObject() (*unstructured.Unstructured, error) {
 	// Create the objMap of all the resources, and compute the hash.
 	objMap := buildObjMap(icm.objMetas)
+	prevObjMap, _, _ := unstructured.NestedStringMap(icm.inv.Object, "data")
+	for _, id := range icm.objMetas {
+		result, found := icm.results[id]
+		if !found {
+			objMap[id.String()] = prevObjMap[id.String()]
+			continue
+		}
+		value, err := encodeObjStatus(result)
+		if err != nil {
+			return nil, err
+		}
+		objMap[id.String()] = value
+	}
 	// Create the inventory object by copying the template.
 	invCopy := icm.inv.DeepCopy()
 	// Adds the inventory map to the ConfigMap "data" section.
@@ -122,3 +147,133 @@ func buildObjMap(objMetas object.ObjMetadataSet) map[string]string {
 	}
 	return objMap
 }
+
+// LoadResults returns the result of the last run of the objects, decoded
+// from the values of the wrapped ConfigMap data. Objects without a
+// result, or with a result in an unknown encoding, are left out.
+func (icm *ConfigMap) LoadResults() ([]actuation.ObjectStatus, error) {
+	objMap, _, err := unstructured.NestedStringMap(icm.inv.Object, "data")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving object status from inventory object: %w", err)
+	}
+	keys := make([]string, 0, len(objMap))
+	for objStr := range objMap {
+		keys = append(keys, objStr)
+	}
+	sort.Strings(keys)
+	var results []actuation.ObjectStatus
+	for _, objStr := range keys {
+		if objMap[objStr] == "" {
+			continue
+		}
+		id, err := object.ParseObjMetadata(objStr)
+		if err != nil {
+			return nil, err
+		}
+		result, found, err := decodeObjStatus(id, objMap[objStr])
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// StoreResults is an Inventory interface function implemented to store
+// the result of the last run of the stored objects in the wrapped
+// ConfigMap. Actual storing happens in "GetObject".
+func (icm *ConfigMap) StoreResults(results []actuation.ObjectStatus) error {
+	icm.results = make(map[object.ObjMetadata]actuation.ObjectStatus, len(results))
+	for _, result := range results {
+		icm.results[ObjMetadataFromObjectReference(result.ObjectReference)] = result
+	}
+	return nil
+}
+
+// objStatusEncodingVersion is the version of the encoding of the object
+// status stored as the values of the ConfigMap data. The encoded status is
+// a comma separated list of the version, the strategy, actuation and
+// reconcile codes, the generation and the UID (ex: "1,A,S,S,3,<uid>").
+// Values with another version are ignored, so that a newer encoding does
+// not break older clients.
+const objStatusEncodingVersion = "1"
+
+// The codes of the strategy, actuation and reconcile status, indexed by
+// their value.
+const (
+	strategyCodes  = "AD"
+	actuationCodes = "PSKF"
+	reconcileCodes = "PSKFT"
+)
+
+// encodeObjStatus returns the compact encoding of the passed object status.
+func encodeObjStatus(objStatus actuation.ObjectStatus) (string, error) {
+	strategy, err := statusCode(strategyCodes, int(objStatus.Strategy))
+	if err != nil {
+		return "", fmt.Errorf("invalid strategy %s: %w", objStatus.Strategy, err)
+	}
+	actuationCode, err := statusCode(actuationCodes, int(objStatus.Actuation))
+	if err != nil {
+		return "", fmt.Errorf("invalid actuation status %s: %w", objStatus.Actuation, err)
+	}
+	reconcile, err := statusCode(reconcileCodes, int(objStatus.Reconcile))
+	if err != nil {
+		return "", fmt.Errorf("invalid reconcile status %s: %w", objStatus.Reconcile, err)
+	}
+	return strings.Join([]string{
+		objStatusEncodingVersion,
+		strategy,
+		actuationCode,
+		reconcile,
+		strconv.FormatInt(objStatus.Generation, 10),
+		string(objStatus.UID),
+	}, ","), nil
+}
+
+// decodeObjStatus decodes the status of the passed object from the passed
+// value. Returns false if the value is encoded with an unknown version.
+func decodeObjStatus(id object.ObjMetadata, value string) (actuation.ObjectStatus, bool, error) {
+	objStatus := actuation.ObjectStatus{
+		ObjectReference: ObjectReferenceFromObjMetadata(id),
+	}
+	fields := strings.Split(value, ",")
+	if fields[0] != objStatusEncodingVersion {
+		klog.V(4).Infof("ignoring inventory object status with unknown encoding: %s: %q", id, value)
+		return objStatus, false, nil
+	}
+	if len(fields) != 6 {
+		return objStatus, false, fmt.Errorf("invalid inventory object status: %s: %q", id, value)
+	}
+	strategy := statusIndex(strategyCodes, fields[1])
+	actuationStatus := statusIndex(actuationCodes, fields[2])
+	reconcile := statusIndex(reconcileCodes, fields[3])
+	generation, err := strconv.ParseInt(fields[4], 10, 64)
+	if strategy < 0 || actuationStatus < 0 || reconcile < 0 || err != nil {
+		return objStatus, false, fmt.Errorf("invalid inventory object status: %s: %q", id, value)
+	}
+	objStatus.Strategy = actuation.ActuationStrategy(strategy)
+	objStatus.Actuation = actuation.ActuationStatus(actuationStatus)
+	objStatus.Reconcile = actuation.ReconcileStatus(reconcile)
+	objStatus.Generation = generation
+	objStatus.UID = types.UID(fields[5])
+	return objStatus, true, nil
+}
+
+// statusCode returns the code with the passed index.
+func statusCode(codes string, index int) (string, error) {
+	if index < 0 || index >= len(codes) {
+		return "", fmt.Errorf("no code for value %d", index)
+	}
+	return codes[index : index+1], nil
+}
+
+// statusIndex returns the index of the passed code, or -1 if it is not
+// one of the passed codes.
+func statusIndex(codes string, code string) int {
+	if len(code) != 1 {
+		return -1
+	}
+	return strings.Index(codes, code)
+}
@@ -0,0 +1,274 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// Introduces the ResourceGroup struct which implements
+// the Inventory interface. The ResourceGroup wraps a
+// ResourceGroup custom resource which stores the set of
+// inventory (object metadata) in its spec, and the status
+// of the inventory objects in its status.
+
+package inventory
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// ResourceGroupGVK is the GroupVersionKind of the ResourceGroup inventory
+// object.
+var ResourceGroupGVK = schema.GroupVersionKind{
+	Group:   "cli-utils.sigs.k8s.io",
+	Version: "v1alpha1",
+	Kind:    "ResourceGroup",
+}
+
+// ResourceGroupCRD is the CustomResourceDefinition of the ResourceGroup
+// inventory object. The ClusterClient installs it when the first
+// ResourceGroup inventory object is created.
+var ResourceGroupCRD = []byte(strings.TrimSpace(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: resourcegroups.cli-utils.sigs.k8s.io
+spec:
+  conversion:
+    strategy: None
+  group: cli-utils.sigs.k8s.io
+  names:
+    kind: ResourceGroup
+    listKind: ResourceGroupList
+    plural: resourcegroups
+    singular: resourcegroup
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    schema:
+      openAPIV3Schema:
+        description: ResourceGroup is an inventory object which stores the
+          set of applied objects and their actuation and reconcile status.
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            properties:
+              resources:
+                items:
+                  properties:
+                    group:
+                      type: string
+                    kind:
+                      type: string
+                    name:
+                      type: string
+                    namespace:
+                      type: string
+                  required:
+                  - kind
+                  - name
+                  type: object
+                type: array
+            type: object
+          status:
+            properties:
+              resourceStatuses:
+                items:
+                  properties:
+                    group:
+                      type: string
+                    kind:
+                      type: string
+                    name:
+                      type: string
+                    namespace:
+                      type: string
+                    strategy:
+                      type: integer
+                    actuation:
+                      type: integer
+                    reconcile:
+                      type: integer
+                    uid:
+                      type: string
+                    generation:
+                      format: int64
+                      type: integer
+                  required:
+                  - kind
+                  - name
+                  type: object
+                type: array
+            type: object
+        type: object
+    served: true
+    storage: true
+    subresources:
+      status: {}
+`))
+
+// WrapResourceGroupObj takes a passed ResourceGroup, wraps it
+// with the ResourceGroup and upcasts the wrapper as the
+// Storage interface.
+func WrapResourceGroupObj(inv *unstructured.Unstructured) Storage {
+	return &ResourceGroup{inv: inv}
+}
+
+// WrapResourceGroupInfoObj takes a passed ResourceGroup, wraps
+// it with the ResourceGroup and upcasts the wrapper as the
+// Info interface.
+func WrapResourceGroupInfoObj(inv *unstructured.Unstructured) Info {
+	return &ResourceGroup{inv: inv}
+}
+
+// InvInfoToResourceGroup returns the ResourceGroup wrapped by the
+// passed Info, or nil if the Info is not a ResourceGroup.
+func InvInfoToResourceGroup(inv Info) *unstructured.Unstructured {
+	rg, ok := inv.(*ResourceGroup)
+	if ok {
+		return rg.inv
+	}
+	return nil
+}
+
+// IsResourceGroup returns true if the passed object is a ResourceGroup.
+func IsResourceGroup(obj *unstructured.Unstructured) bool {
+	return obj != nil && obj.GroupVersionKind().GroupKind() == ResourceGroupGVK.GroupKind()
+}
+
+// ResourceGroup wraps a ResourceGroup resource and implements
+// the Inventory interface. This wrapper loads and stores the
+// object metadata (inventory) to and from the "spec.resources"
+// field, and the object status to and from the
+// "status.resourceStatuses" field of the wrapped ResourceGroup.
+type ResourceGroup struct {
+	inv      *unstructured.Unstructured
+	objMetas object.ObjMetadataSet
+}
+
+var _ Info = &ResourceGroup{}
+var _ Storage = &ResourceGroup{}
+var _ StatusStorage = &ResourceGroup{}
+
+func (rg *ResourceGroup) Name() string {
+	return rg.inv.GetName()
+}
+
+func (rg *ResourceGroup) Namespace() string {
+	return rg.inv.GetNamespace()
+}
+
+func (rg *ResourceGroup) ID() string {
+	// Empty string if not set.
+	return rg.inv.GetLabels()[common.InventoryLabel]
+}
+
+func (rg *ResourceGroup) Strategy() Strategy {
+	return LabelStrategy
+}
+
+func (rg *ResourceGroup) UnstructuredInventory() *unstructured.Unstructured {
+	return rg.inv
+}
+
+// Load is an Inventory interface function returning the set of
+// object metadata from the wrapped ResourceGroup, or an error.
+func (rg *ResourceGroup) Load() (object.ObjMetadataSet, error) {
+	objs := object.ObjMetadataSet{}
+	items, _, err := unstructured.NestedSlice(rg.inv.Object, "spec", "resources")
+	if err != nil {
+		return objs, fmt.Errorf("error retrieving object metadata from inventory object: %w", err)
+	}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return objs, fmt.Errorf("invalid resource in inventory object: %v", item)
+		}
+		ref := actuation.ObjectReference{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &ref); err != nil {
+			return objs, fmt.Errorf("invalid resource in inventory object: %w", err)
+		}
+		objs = append(objs, ObjMetadataFromObjectReference(ref))
+	}
+	return objs, nil
+}
+
+// Store is an Inventory interface function implemented to store
+// the object metadata in the wrapped ResourceGroup. Actual storing
+// happens in "GetObject".
+func (rg *ResourceGroup) Store(objMetas object.ObjMetadataSet) error {
+	rg.objMetas = objMetas
+	return nil
+}
+
+// GetObject returns a copy of the wrapped ResourceGroup with the
+// stored object metadata, or an error if one occurs.
+func (rg *ResourceGroup) GetObject() (*unstructured.Unstructured, error) {
+	items := make([]interface{}, 0, len(rg.objMetas))
+	for _, id := range rg.objMetas {
+		ref := ObjectReferenceFromObjMetadata(id)
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&ref)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, m)
+	}
+	invCopy := rg.inv.DeepCopy()
+	if len(items) == 0 {
+		unstructured.RemoveNestedField(invCopy.Object, "spec", "resources")
+		return invCopy, nil
+	}
+	if err := unstructured.SetNestedSlice(invCopy.Object, items, "spec", "resources"); err != nil {
+		return nil, err
+	}
+	return invCopy, nil
+}
+
+// LoadStatus returns the status of the objects stored in the
+// "status.resourceStatuses" field of the wrapped ResourceGroup.
+func (rg *ResourceGroup) LoadStatus() ([]actuation.ObjectStatus, error) {
+	items, _, err := unstructured.NestedSlice(rg.inv.Object, "status", "resourceStatuses")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory status: %w", err)
+	}
+	var objStatus []actuation.ObjectStatus
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid resource status in inventory object: %v", item)
+		}
+		status := actuation.ObjectStatus{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &status); err != nil {
+			return nil, fmt.Errorf("failed to read inventory status: %w", err)
+		}
+		objStatus = append(objStatus, status)
+	}
+	return objStatus, nil
+}
+
+// StoreStatus stores the status of the objects in the
+// "status.resourceStatuses" field of the wrapped ResourceGroup.
+func (rg *ResourceGroup) StoreStatus(objStatus []actuation.ObjectStatus) error {
+	if len(objStatus) == 0 {
+		unstructured.RemoveNestedField(rg.inv.Object, "status", "resourceStatuses")
+		return nil
+	}
+	items := make([]interface{}, 0, len(objStatus))
+	for i := range objStatus {
+		m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&objStatus[i])
+		if err != nil {
+			return fmt.Errorf("failed to convert inventory status: %w", err)
+		}
+		items = append(items, m)
+	}
+	return unstructured.SetNestedSlice(rg.inv.Object, items, "status", "resourceStatuses")
+}
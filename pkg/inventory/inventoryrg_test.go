@@ -0,0 +1,167 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func newResourceGroup() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": ResourceGroupGVK.GroupVersion().String(),
+			"kind":       ResourceGroupGVK.Kind,
+			"metadata": map[string]interface{}{
+				"name":      inventoryObjName,
+				"namespace": testNamespace,
+				"labels": map[string]interface{}{
+					common.InventoryLabel: testInventoryLabel,
+				},
+			},
+		},
+	}
+}
+
+func TestResourceGroupLoadStore(t *testing.T) {
+	testCases := map[string]struct {
+		objs object.ObjMetadataSet
+	}{
+		"no objects": {
+			objs: object.ObjMetadataSet{},
+		},
+		"namespaced and cluster-scoped objects": {
+			objs: object.ObjMetadataSet{
+				ignoreErrInfoToObjMeta(pod1Info),
+				ignoreErrInfoToObjMeta(pod2Info),
+				{
+					GroupKind: ResourceGroupGVK.GroupKind(),
+					Name:      "cluster-scoped",
+				},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			rg := newResourceGroup()
+			info := WrapResourceGroupInfoObj(rg)
+			assert.Equal(t, inventoryObjName, info.Name())
+			assert.Equal(t, testNamespace, info.Namespace())
+			assert.Equal(t, testInventoryLabel, info.ID())
+			assert.Equal(t, rg, InvInfoToResourceGroup(info))
+			assert.Nil(t, InvInfoToResourceGroup(localInv))
+
+			wrapped := WrapResourceGroupObj(rg)
+			require.NoError(t, wrapped.Store(tc.objs))
+			stored, err := wrapped.GetObject()
+			require.NoError(t, err)
+			_, found, _ := unstructured.NestedSlice(rg.Object, "spec", "resources")
+			assert.False(t, found, "wrapped object must not be changed")
+
+			objs, err := WrapResourceGroupObj(stored).Load()
+			require.NoError(t, err)
+			assert.True(t, tc.objs.Equal(objs), "expected %v, got %v", tc.objs, objs)
+		})
+	}
+}
+
+func TestResourceGroupStatus(t *testing.T) {
+	objStatus := []actuation.ObjectStatus{
+		{
+			ObjectReference: ObjectReferenceFromObjMetadata(ignoreErrInfoToObjMeta(pod1Info)),
+			Strategy:        actuation.ActuationStrategyApply,
+			Actuation:       actuation.ActuationSucceeded,
+			Reconcile:       actuation.ReconcileSucceeded,
+			UID:             "pod-uid",
+			Generation:      1,
+		},
+		{
+			ObjectReference: ObjectReferenceFromObjMetadata(ignoreErrInfoToObjMeta(pod2Info)),
+			Strategy:        actuation.ActuationStrategyDelete,
+			Actuation:       actuation.ActuationFailed,
+			Reconcile:       actuation.ReconcilePending,
+		},
+	}
+
+	rg := newResourceGroup()
+	wrapped := WrapResourceGroupObj(rg).(StatusStorage)
+	loaded, err := wrapped.LoadStatus()
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	require.NoError(t, wrapped.StoreStatus(objStatus))
+	loaded, err = WrapResourceGroupObj(rg).(StatusStorage).LoadStatus()
+	require.NoError(t, err)
+	assert.Equal(t, objStatus, loaded)
+
+	require.NoError(t, wrapped.StoreStatus(nil))
+	_, found, _ := unstructured.NestedSlice(rg.Object, "status", "resourceStatuses")
+	assert.False(t, found)
+}
+
+// resettableRESTMapper is a RESTMapper which maps the ResourceGroup kind
+// once it has been reset.
+type resettableRESTMapper struct {
+	*meta.DefaultRESTMapper
+}
+
+func (m resettableRESTMapper) Reset() {
+	m.Add(ResourceGroupGVK, meta.RESTScopeNamespace)
+}
+
+func TestMergeInstallsResourceGroupCRD(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	crdCreated := false
+	tf.FakeDynamicClient.PrependReactor("create", "customresourcedefinitions", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		crdCreated = true
+		return true, action.(clienttesting.CreateAction).GetObject(), nil
+	})
+	tf.FakeDynamicClient.PrependReactor("get", "customresourcedefinitions", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		crd := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		err := unstructured.SetNestedSlice(crd.Object, []interface{}{
+			map[string]interface{}{"type": "Established", "status": "True"},
+		}, "status", "conditions")
+		return true, crd, err
+	})
+
+	invClient, err := NewClient(tf, WrapResourceGroupObj, InvInfoToResourceGroup)
+	require.NoError(t, err)
+	invClient.mapper = resettableRESTMapper{meta.NewDefaultRESTMapper(nil)}
+	invClient.discoveryClient = memory.NewMemCacheClient(&fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}})
+
+	objs := object.ObjMetadataSet{
+		ignoreErrInfoToObjMeta(pod1Info),
+		ignoreErrInfoToObjMeta(pod2Info),
+	}
+	pruneObjs, err := invClient.Merge(WrapResourceGroupInfoObj(newResourceGroup()), objs, common.DryRunNone)
+	require.NoError(t, err)
+	assert.Empty(t, pruneObjs)
+	assert.True(t, crdCreated, "expected ResourceGroup CRD to be installed")
+
+	mapping, err := invClient.getMapping(newResourceGroup())
+	require.NoError(t, err)
+	clusterInv, err := tf.FakeDynamicClient.Resource(mapping.Resource).Namespace(testNamespace).
+		Get(context.TODO(), inventoryObjName, metav1.GetOptions{})
+	require.NoError(t, err)
+	clusterObjs, err := WrapResourceGroupObj(clusterInv).Load()
+	require.NoError(t, err)
+	assert.True(t, objs.Equal(clusterObjs), "expected %v, got %v", objs, clusterObjs)
+}
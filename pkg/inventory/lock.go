@@ -0,0 +1,346 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the code to lock an inventory for the
+// length of a run, so that concurrent runs do not change the
+// same inventory at the same time. The lock is a
+// coordination.k8s.io Lease in the namespace of the inventory
+// object, named after the inventory object. The holder renews
+// the Lease while the run progresses. A Lease that is not
+// renewed within its duration is stale, and may be taken over.
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+// LockClient is implemented by inventory clients that can lock an
+// inventory for the length of a run.
+type LockClient interface {
+	// Lock takes the lock of the inventory for the holder of the passed
+	// options, and renews it until it is released. Returns a
+	// LockHeldError if another holder has the lock and it is not stale.
+	Lock(ctx context.Context, inv Info, options LockOptions) (*Lock, error)
+}
+
+var _ LockClient = &ClusterClient{}
+
+const (
+	// DefaultLockDuration is the default duration of an inventory lock.
+	DefaultLockDuration = time.Minute
+)
+
+// LockOptions defines how an inventory lock is taken and renewed.
+type LockOptions struct {
+	// Holder identifies the holder of the lock, and is reported to the
+	// runs that fail to take it.
+	Holder string
+
+	// LeaseDuration defines how long the lock is held without renewal.
+	// A lock that has not been renewed for longer is stale, and is taken
+	// over by the next run. If this is not provided, DefaultLockDuration
+	// is used.
+	LeaseDuration time.Duration
+
+	// RenewInterval defines how often the lock is renewed. If this is not
+	// provided, the lock is renewed three times per LeaseDuration.
+	RenewInterval time.Duration
+}
+
+// leaseGVR is the resource of the Leases which lock the inventories.
+var leaseGVR = schema.GroupVersionResource{
+	Group:    "coordination.k8s.io",
+	Version:  "v1",
+	Resource: "leases",
+}
+
+// Lock is a lock held on an inventory. It is renewed in the background
+// until it is released, or until it is lost, because it could not be
+// renewed in time or was taken over.
+type Lock struct {
+	cic       *ClusterClient
+	namespace string
+	name      string
+	options   LockOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu   sync.Mutex
+	lost bool
+}
+
+// Lock takes the lock of the passed inventory, creating its Lease if it
+// does not exist, or taking it over if it is stale. The returned Lock is
+// renewed until it is released or the passed context is cancelled.
+func (cic *ClusterClient) Lock(ctx context.Context, localInv Info, options LockOptions) (*Lock, error) {
+	if options.Holder == "" {
+		return nil, fmt.Errorf("inventory lock requires a holder")
+	}
+	if options.LeaseDuration <= 0 {
+		options.LeaseDuration = DefaultLockDuration
+	}
+	if options.RenewInterval <= 0 {
+		options.RenewInterval = options.LeaseDuration / 3
+	}
+	l := &Lock{
+		cic:       cic,
+		namespace: localInv.Namespace(),
+		name:      localInv.Name() + "-lock",
+		options:   options,
+		done:      make(chan struct{}),
+	}
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	l.ctx, l.cancel = context.WithCancel(ctx)
+	go l.renew()
+	return l, nil
+}
+
+// Context returns a context which is cancelled when the lock is released
+// or lost.
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Lost returns true if the lock was lost before it was released.
+func (l *Lock) Lost() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lost
+}
+
+// Release stops renewing the lock, and deletes its Lease if it is still
+// held, so that the next run can take the lock right away.
+func (l *Lock) Release() error {
+	l.cancel()
+	<-l.done
+	if l.Lost() {
+		return nil
+	}
+	lease, err := l.get(context.Background())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to release inventory lock: %w", err)
+	}
+	if holder(lease) != l.options.Holder {
+		return nil
+	}
+	klog.V(4).Infof("release inventory lock: %s/%s", l.namespace, l.name)
+	err = l.leases().Delete(context.Background(), l.name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+	if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to release inventory lock: %w", err)
+	}
+	return nil
+}
+
+// acquire creates the Lease of the lock, or takes over the existing Lease
+// if it is held by the same holder, or is stale. Returns a
+// LockNamespaceNotFoundError if the namespace of the inventory does not
+// exist, because the Lease cannot be created without it.
+func (l *Lock) acquire(ctx context.Context) error {
+	lease, err := l.get(ctx)
+	if apierrors.IsNotFound(err) {
+		now := metav1.NewMicroTime(time.Now())
+		lease = &coordinationv1.Lease{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: coordinationv1.SchemeGroupVersion.String(),
+				Kind:       "Lease",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      l.name,
+				Namespace: l.namespace,
+			},
+		}
+		l.hold(lease, now)
+		lease.Spec.AcquireTime = &now
+		klog.V(4).Infof("create inventory lock: %s/%s (holder: %q)", l.namespace, l.name, l.options.Holder)
+		err = l.create(ctx, lease)
+		if apierrors.IsAlreadyExists(err) {
+			// Another run created the lock first.
+			lease, err = l.get(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read inventory lock: %w", err)
+			}
+			return l.heldError(lease)
+		}
+		if apierrors.IsNotFound(err) {
+			return &LockNamespaceNotFoundError{Namespace: l.namespace}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create inventory lock: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read inventory lock: %w", err)
+	}
+	if holder(lease) != l.options.Holder && holder(lease) != "" && !isStale(lease, time.Now()) {
+		return l.heldError(lease)
+	}
+	now := metav1.NewMicroTime(time.Now())
+	if holder(lease) != l.options.Holder {
+		klog.V(4).Infof("take over inventory lock: %s/%s (holder: %q, previous holder: %q)",
+			l.namespace, l.name, l.options.Holder, holder(lease))
+		lease.Spec.AcquireTime = &now
+	}
+	l.hold(lease, now)
+	err = l.update(ctx, lease)
+	if apierrors.IsConflict(err) {
+		// Another run took over the lock first.
+		lease, err = l.get(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to read inventory lock: %w", err)
+		}
+		return l.heldError(lease)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to take inventory lock: %w", err)
+	}
+	return nil
+}
+
+// renew renews the lock until it is released or lost. The lock is lost if
+// it is taken over, or if it cannot be renewed before it becomes stale.
+func (l *Lock) renew() {
+	defer close(l.done)
+	lastRenew := time.Now()
+	ticker := time.NewTicker(l.options.RenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		lease, err := l.get(l.ctx)
+		if err == nil {
+			if holder(lease) != l.options.Holder {
+				klog.Warningf("inventory lock %s/%s taken over by %q", l.namespace, l.name, holder(lease))
+				l.setLost()
+				return
+			}
+			l.hold(lease, metav1.NewMicroTime(now))
+			err = l.update(l.ctx, lease)
+		}
+		if err == nil {
+			klog.V(4).Infof("renewed inventory lock: %s/%s", l.namespace, l.name)
+			lastRenew = now
+			continue
+		}
+		if l.ctx.Err() != nil {
+			return
+		}
+		klog.Warningf("failed to renew inventory lock %s/%s: %v", l.namespace, l.name, err)
+		if now.Sub(lastRenew) >= l.options.LeaseDuration {
+			l.setLost()
+			return
+		}
+	}
+}
+
+// setLost marks the lock as lost, and cancels its context.
+func (l *Lock) setLost() {
+	l.mu.Lock()
+	l.lost = true
+	l.mu.Unlock()
+	l.cancel()
+}
+
+// hold sets the holder and renew time of the passed Lease.
+func (l *Lock) hold(lease *coordinationv1.Lease, now metav1.MicroTime) {
+	durationSeconds := int32(l.options.LeaseDuration / time.Second)
+	if durationSeconds < 1 {
+		durationSeconds = 1
+	}
+	lease.Spec.HolderIdentity = &l.options.Holder
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &now
+}
+
+// heldError returns the error reporting that the passed Lease is held by
+// another holder.
+func (l *Lock) heldError(lease *coordinationv1.Lease) error {
+	err := &LockHeldError{
+		Namespace: l.namespace,
+		Name:      l.name,
+		Holder:    holder(lease),
+	}
+	if lease.Spec.RenewTime != nil {
+		err.RenewTime = lease.Spec.RenewTime.Time
+	}
+	return err
+}
+
+func (l *Lock) leases() dynamic.ResourceInterface {
+	return l.cic.dc.Resource(leaseGVR).Namespace(l.namespace)
+}
+
+func (l *Lock) get(ctx context.Context) (*coordinationv1.Lease, error) {
+	obj, err := l.leases().Get(ctx, l.name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	lease := &coordinationv1.Lease{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, lease); err != nil {
+		return nil, fmt.Errorf("invalid inventory lock: %w", err)
+	}
+	return lease, nil
+}
+
+func (l *Lock) create(ctx context.Context, lease *coordinationv1.Lease) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(lease)
+	if err != nil {
+		return err
+	}
+	_, err = l.leases().Create(ctx, &unstructured.Unstructured{Object: obj}, metav1.CreateOptions{})
+	return err
+}
+
+func (l *Lock) update(ctx context.Context, lease *coordinationv1.Lease) error {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(lease)
+	if err != nil {
+		return err
+	}
+	_, err = l.leases().Update(ctx, &unstructured.Unstructured{Object: obj}, metav1.UpdateOptions{})
+	return err
+}
+
+// holder returns the holder of the passed Lease, or an empty string if it
+// has none.
+func holder(lease *coordinationv1.Lease) string {
+	if lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+// isStale returns true if the passed Lease has not been renewed within its
+// duration.
+func isStale(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return now.After(expiry)
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clienttesting "k8s.io/client-go/testing"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+)
+
+func TestInventoryLock(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	leases := tf.FakeDynamicClient.Resource(leaseGVR).Namespace(testNamespace)
+	lockName := inventoryObjName + "-lock"
+
+	// A lock requires a holder.
+	_, err = invClient.Lock(context.Background(), inv, LockOptions{})
+	assert.EqualError(t, err, "inventory lock requires a holder")
+
+	lock, err := invClient.Lock(context.Background(), inv, LockOptions{Holder: "first"})
+	require.NoError(t, err)
+	lease, err := lock.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", holder(lease))
+	assert.Equal(t, int32(60), *lease.Spec.LeaseDurationSeconds)
+
+	// Another holder cannot take the lock while it is held.
+	_, err = invClient.Lock(context.Background(), inv, LockOptions{Holder: "second"})
+	var heldErr *LockHeldError
+	require.True(t, errors.As(err, &heldErr), "unexpected error: %v", err)
+	assert.Equal(t, "first", heldErr.Holder)
+	assert.Equal(t, testNamespace, heldErr.Namespace)
+	assert.Equal(t, lockName, heldErr.Name)
+	assert.Contains(t, err.Error(), `inventory is locked by "first"`)
+
+	// Releasing the lock deletes the lease, and cancels the lock context.
+	require.NoError(t, lock.Release())
+	assert.Error(t, lock.Context().Err())
+	assert.False(t, lock.Lost())
+	_, err = leases.Get(context.Background(), lockName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "unexpected error: %v", err)
+
+	lock, err = invClient.Lock(context.Background(), inv, LockOptions{Holder: "second"})
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+func TestInventoryLockStale(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+
+	// A lock that was not renewed within its duration is taken over.
+	staleLease := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "coordination.k8s.io/v1",
+			"kind":       "Lease",
+			"metadata": map[string]interface{}{
+				"name":      inventoryObjName + "-lock",
+				"namespace": testNamespace,
+			},
+			"spec": map[string]interface{}{
+				"holderIdentity":       "crashed",
+				"leaseDurationSeconds": int64(60),
+				"renewTime":            metav1.NewMicroTime(time.Now().Add(-2 * time.Minute)).Format(metav1.RFC3339Micro),
+			},
+		},
+	}
+	_, err = tf.FakeDynamicClient.Resource(leaseGVR).Namespace(testNamespace).
+		Create(context.Background(), staleLease, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	lock, err := invClient.Lock(context.Background(), inv, LockOptions{Holder: "next"})
+	require.NoError(t, err)
+	lease, err := lock.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "next", holder(lease))
+	assert.False(t, isStale(lease, time.Now()))
+
+	require.NoError(t, lock.Release())
+}
+
+func TestInventoryLockNamespaceNotFound(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	tf.FakeDynamicClient.PrependReactor("create", "leases", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, testNamespace)
+	})
+
+	// The inventory is not locked without its namespace.
+	_, err = invClient.Lock(context.Background(), inv, LockOptions{Holder: "first"})
+	var nsErr *LockNamespaceNotFoundError
+	require.True(t, errors.As(err, &nsErr), "unexpected error: %v", err)
+	assert.Equal(t, testNamespace, nsErr.Namespace)
+}
+
+func TestInventoryLockLost(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+
+	lock, err := invClient.Lock(context.Background(), inv, LockOptions{
+		Holder:        "first",
+		LeaseDuration: time.Second,
+		RenewInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	// Another run takes over the lock, so the lock is lost at the next
+	// renewal, and its context is cancelled.
+	lease, err := lock.get(context.Background())
+	require.NoError(t, err)
+	other := "second"
+	lease.Spec.HolderIdentity = &other
+	require.NoError(t, lock.update(context.Background(), lease))
+
+	select {
+	case <-lock.Context().Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock was not lost")
+	}
+	assert.True(t, lock.Lost())
+
+	// Releasing a lost lock does not delete the lease of the other run.
+	require.NoError(t, lock.Release())
+	lease, err = lock.get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "second", holder(lease))
+}
@@ -5,6 +5,7 @@ package inventory
 
 import (
 	"fmt"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -13,7 +14,9 @@ import (
 )
 
 // Manager wraps an Inventory with convenience methods that use ObjMetadata.
+// Manager is thread-safe.
 type Manager struct {
+	mu        sync.RWMutex
 	inventory *actuation.Inventory
 }
 
@@ -32,52 +35,52 @@ func (tc *Manager) Inventory() *actuation.Inventory {
 // ObjectStatus retrieves the status of an object with the specified ID.
 // The returned status is a pointer and can be updated in-place for efficiency.
 func (tc *Manager) ObjectStatus(id object.ObjMetadata) (*actuation.ObjectStatus, bool) {
-	for i, objStatus := range tc.inventory.Status.Objects {
-		if ObjMetadataEqualObjectReference(id, objStatus.ObjectReference) {
-			return &(tc.inventory.Status.Objects[i]), true
-		}
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectStatus(id)
+}
+
+// ObjectStatuses returns a copy of the status of all the objects in the
+// inventory.
+func (tc *Manager) ObjectStatuses() []actuation.ObjectStatus {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	if len(tc.inventory.Status.Objects) == 0 {
+		return nil
 	}
-	return nil, false
+	objStatuses := make([]actuation.ObjectStatus, len(tc.inventory.Status.Objects))
+	copy(objStatuses, tc.inventory.Status.Objects)
+	return objStatuses
 }
 
 // ObjectsWithActuationStatus retrieves the set of objects with the
 // specified actuation strategy and status.
 func (tc *Manager) ObjectsWithActuationStatus(strategy actuation.ActuationStrategy, status actuation.ActuationStatus) object.ObjMetadataSet {
-	var ids object.ObjMetadataSet
-	for _, objStatus := range tc.inventory.Status.Objects {
-		if objStatus.Strategy == strategy && objStatus.Actuation == status {
-			ids = append(ids, ObjMetadataFromObjectReference(objStatus.ObjectReference))
-		}
-	}
-	return ids
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(strategy, status)
 }
 
-// ObjectsWithActuationStatus retrieves the set of objects with the
+// ObjectsWithReconcileStatus retrieves the set of objects with the
 // specified reconcile status, regardless of actuation strategy.
 func (tc *Manager) ObjectsWithReconcileStatus(status actuation.ReconcileStatus) object.ObjMetadataSet {
-	var ids object.ObjMetadataSet
-	for _, objStatus := range tc.inventory.Status.Objects {
-		if objStatus.Reconcile == status {
-			ids = append(ids, ObjMetadataFromObjectReference(objStatus.ObjectReference))
-		}
-	}
-	return ids
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(status)
 }
 
 // SetObjectStatus updates or adds an ObjectStatus record to the inventory.
 func (tc *Manager) SetObjectStatus(id object.ObjMetadata, objStatus actuation.ObjectStatus) {
-	for i, objStatus := range tc.inventory.Status.Objects {
-		if ObjMetadataEqualObjectReference(id, objStatus.ObjectReference) {
-			tc.inventory.Status.Objects[i] = objStatus
-			return
-		}
-	}
-	tc.inventory.Status.Objects = append(tc.inventory.Status.Objects, objStatus)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, objStatus)
 }
 
 // IsSuccessfulApply returns true if the object apply was successful
 func (tc *Manager) IsSuccessfulApply(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -89,7 +92,9 @@ func (tc *Manager) IsSuccessfulApply(id object.ObjMetadata) bool {
 // resource identified by the provided id. Currently, we keep information
 // about the generation of the resource after the apply operation completed.
 func (tc *Manager) AddSuccessfulApply(id object.ObjMetadata, uid types.UID, gen int64) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyApply,
 		Actuation:       actuation.ActuationSucceeded,
@@ -102,13 +107,17 @@ func (tc *Manager) AddSuccessfulApply(id object.ObjMetadata, uid types.UID, gen
 // SuccessfulApplies returns all the objects (as ObjMetadata) that
 // were added as applied resources to the Manager.
 func (tc *Manager) SuccessfulApplies() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyApply,
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyApply,
 		actuation.ActuationSucceeded)
 }
 
 // AppliedResourceUID looks up the UID of a successfully applied resource
 func (tc *Manager) AppliedResourceUID(id object.ObjMetadata) (types.UID, bool) {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	return objStatus.UID, found &&
 		objStatus.Strategy == actuation.ActuationStrategyApply &&
 		objStatus.Actuation == actuation.ActuationSucceeded
@@ -117,6 +126,8 @@ func (tc *Manager) AppliedResourceUID(id object.ObjMetadata) (types.UID, bool) {
 // AppliedResourceUIDs returns a set with the UIDs of all the
 // successfully applied resources.
 func (tc *Manager) AppliedResourceUIDs() sets.String {
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
 	uids := sets.NewString()
 	for _, objStatus := range tc.inventory.Status.Objects {
 		if objStatus.Strategy == actuation.ActuationStrategyApply &&
@@ -132,7 +143,9 @@ func (tc *Manager) AppliedResourceUIDs() sets.String {
 // AppliedGeneration looks up the generation of the given resource
 // after it was applied.
 func (tc *Manager) AppliedGeneration(id object.ObjMetadata) (int64, bool) {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return 0, false
 	}
@@ -141,7 +154,9 @@ func (tc *Manager) AppliedGeneration(id object.ObjMetadata) (int64, bool) {
 
 // IsSuccessfulDelete returns true if the object delete was successful
 func (tc *Manager) IsSuccessfulDelete(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -155,7 +170,9 @@ func (tc *Manager) IsSuccessfulDelete(id object.ObjMetadata) bool {
 // object was scheduled to be deleted asynchronously, which might cause further
 // updates by finalizers. The UID will change if the object is re-created.
 func (tc *Manager) AddSuccessfulDelete(id object.ObjMetadata, uid types.UID) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyDelete,
 		Actuation:       actuation.ActuationSucceeded,
@@ -167,13 +184,17 @@ func (tc *Manager) AddSuccessfulDelete(id object.ObjMetadata, uid types.UID) {
 // SuccessfulDeletes returns all the objects (as ObjMetadata) that
 // were successfully deleted.
 func (tc *Manager) SuccessfulDeletes() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyDelete,
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyDelete,
 		actuation.ActuationSucceeded)
 }
 
 // IsFailedApply returns true if the object failed to apply
 func (tc *Manager) IsFailedApply(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -183,7 +204,9 @@ func (tc *Manager) IsFailedApply(id object.ObjMetadata) bool {
 
 // AddFailedApply registers that the object failed to apply
 func (tc *Manager) AddFailedApply(id object.ObjMetadata) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyApply,
 		Actuation:       actuation.ActuationFailed,
@@ -193,12 +216,16 @@ func (tc *Manager) AddFailedApply(id object.ObjMetadata) {
 
 // FailedApplies returns all the objects that failed to apply
 func (tc *Manager) FailedApplies() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyApply, actuation.ActuationFailed)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyApply, actuation.ActuationFailed)
 }
 
 // IsFailedDelete returns true if the object failed to delete
 func (tc *Manager) IsFailedDelete(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -208,7 +235,9 @@ func (tc *Manager) IsFailedDelete(id object.ObjMetadata) bool {
 
 // AddFailedDelete registers that the object failed to delete
 func (tc *Manager) AddFailedDelete(id object.ObjMetadata) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyDelete,
 		Actuation:       actuation.ActuationFailed,
@@ -218,13 +247,17 @@ func (tc *Manager) AddFailedDelete(id object.ObjMetadata) {
 
 // FailedDeletes returns all the objects that failed to delete
 func (tc *Manager) FailedDeletes() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyDelete,
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyDelete,
 		actuation.ActuationFailed)
 }
 
 // IsSkippedApply returns true if the object apply was skipped
 func (tc *Manager) IsSkippedApply(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -234,7 +267,9 @@ func (tc *Manager) IsSkippedApply(id object.ObjMetadata) bool {
 
 // AddSkippedApply registers that the object apply was skipped
 func (tc *Manager) AddSkippedApply(id object.ObjMetadata) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyApply,
 		Actuation:       actuation.ActuationSkipped,
@@ -244,12 +279,16 @@ func (tc *Manager) AddSkippedApply(id object.ObjMetadata) {
 
 // SkippedApplies returns all the objects where apply was skipped
 func (tc *Manager) SkippedApplies() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyApply, actuation.ActuationSkipped)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyApply, actuation.ActuationSkipped)
 }
 
 // IsSkippedDelete returns true if the object delete was skipped
 func (tc *Manager) IsSkippedDelete(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -259,7 +298,9 @@ func (tc *Manager) IsSkippedDelete(id object.ObjMetadata) bool {
 
 // AddSkippedDelete registers that the object delete was skipped
 func (tc *Manager) AddSkippedDelete(id object.ObjMetadata) {
-	tc.SetObjectStatus(id, actuation.ObjectStatus{
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.setObjectStatus(id, actuation.ObjectStatus{
 		ObjectReference: ObjectReferenceFromObjMetadata(id),
 		Strategy:        actuation.ActuationStrategyDelete,
 		Actuation:       actuation.ActuationSkipped,
@@ -269,13 +310,17 @@ func (tc *Manager) AddSkippedDelete(id object.ObjMetadata) {
 
 // SkippedDeletes returns all the objects where deletion was skipped
 func (tc *Manager) SkippedDeletes() object.ObjMetadataSet {
-	return tc.ObjectsWithActuationStatus(actuation.ActuationStrategyDelete,
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithActuationStatus(actuation.ActuationStrategyDelete,
 		actuation.ActuationSkipped)
 }
 
 // IsSuccessfulReconcile returns true if the object is reconciled
 func (tc *Manager) IsSuccessfulReconcile(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -284,7 +329,9 @@ func (tc *Manager) IsSuccessfulReconcile(id object.ObjMetadata) bool {
 
 // SetSuccessfulReconcile registers that the object is reconciled
 func (tc *Manager) SetSuccessfulReconcile(id object.ObjMetadata) error {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return fmt.Errorf("object not in inventory: %q", id)
 	}
@@ -294,12 +341,16 @@ func (tc *Manager) SetSuccessfulReconcile(id object.ObjMetadata) error {
 
 // SuccessfulReconciles returns all the reconciled objects
 func (tc *Manager) SuccessfulReconciles() object.ObjMetadataSet {
-	return tc.ObjectsWithReconcileStatus(actuation.ReconcileSucceeded)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(actuation.ReconcileSucceeded)
 }
 
 // IsFailedReconcile returns true if the object failed to reconcile
 func (tc *Manager) IsFailedReconcile(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -308,7 +359,9 @@ func (tc *Manager) IsFailedReconcile(id object.ObjMetadata) bool {
 
 // SetFailedReconcile registers that the object failed to reconcile
 func (tc *Manager) SetFailedReconcile(id object.ObjMetadata) error {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return fmt.Errorf("object not in inventory: %q", id)
 	}
@@ -318,12 +371,16 @@ func (tc *Manager) SetFailedReconcile(id object.ObjMetadata) error {
 
 // FailedReconciles returns all the objects that failed to reconcile
 func (tc *Manager) FailedReconciles() object.ObjMetadataSet {
-	return tc.ObjectsWithReconcileStatus(actuation.ReconcileFailed)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(actuation.ReconcileFailed)
 }
 
 // IsSkippedReconcile returns true if the object reconcile was skipped
 func (tc *Manager) IsSkippedReconcile(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -332,7 +389,9 @@ func (tc *Manager) IsSkippedReconcile(id object.ObjMetadata) bool {
 
 // SetSkippedReconcile registers that the object reconcile was skipped
 func (tc *Manager) SetSkippedReconcile(id object.ObjMetadata) error {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return fmt.Errorf("object not in inventory: %q", id)
 	}
@@ -342,12 +401,16 @@ func (tc *Manager) SetSkippedReconcile(id object.ObjMetadata) error {
 
 // SkippedReconciles returns all the objects where reconcile was skipped
 func (tc *Manager) SkippedReconciles() object.ObjMetadataSet {
-	return tc.ObjectsWithReconcileStatus(actuation.ReconcileSkipped)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(actuation.ReconcileSkipped)
 }
 
 // IsTimeoutReconcile returns true if the object reconcile was skipped
 func (tc *Manager) IsTimeoutReconcile(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -356,7 +419,9 @@ func (tc *Manager) IsTimeoutReconcile(id object.ObjMetadata) bool {
 
 // SetTimeoutReconcile registers that the object reconcile was skipped
 func (tc *Manager) SetTimeoutReconcile(id object.ObjMetadata) error {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return fmt.Errorf("object not in inventory: %q", id)
 	}
@@ -366,12 +431,16 @@ func (tc *Manager) SetTimeoutReconcile(id object.ObjMetadata) error {
 
 // TimeoutReconciles returns all the objects where reconcile was skipped
 func (tc *Manager) TimeoutReconciles() object.ObjMetadataSet {
-	return tc.ObjectsWithReconcileStatus(actuation.ReconcileTimeout)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(actuation.ReconcileTimeout)
 }
 
 // IsPendingReconcile returns true if the object reconcile is pending
 func (tc *Manager) IsPendingReconcile(id object.ObjMetadata) bool {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return false
 	}
@@ -380,7 +449,9 @@ func (tc *Manager) IsPendingReconcile(id object.ObjMetadata) bool {
 
 // SetPendingReconcile registers that the object reconcile is pending
 func (tc *Manager) SetPendingReconcile(id object.ObjMetadata) error {
-	objStatus, found := tc.ObjectStatus(id)
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	objStatus, found := tc.objectStatus(id)
 	if !found {
 		return fmt.Errorf("object not in inventory: %q", id)
 	}
@@ -390,5 +461,46 @@ func (tc *Manager) SetPendingReconcile(id object.ObjMetadata) error {
 
 // PendingReconciles returns all the objects where reconcile is pending
 func (tc *Manager) PendingReconciles() object.ObjMetadataSet {
-	return tc.ObjectsWithReconcileStatus(actuation.ReconcilePending)
+	tc.mu.RLock()
+	defer tc.mu.RUnlock()
+	return tc.objectsWithReconcileStatus(actuation.ReconcilePending)
+}
+
+func (tc *Manager) objectStatus(id object.ObjMetadata) (*actuation.ObjectStatus, bool) {
+	for i, objStatus := range tc.inventory.Status.Objects {
+		if ObjMetadataEqualObjectReference(id, objStatus.ObjectReference) {
+			return &(tc.inventory.Status.Objects[i]), true
+		}
+	}
+	return nil, false
+}
+
+func (tc *Manager) objectsWithActuationStatus(strategy actuation.ActuationStrategy, status actuation.ActuationStatus) object.ObjMetadataSet {
+	var ids object.ObjMetadataSet
+	for _, objStatus := range tc.inventory.Status.Objects {
+		if objStatus.Strategy == strategy && objStatus.Actuation == status {
+			ids = append(ids, ObjMetadataFromObjectReference(objStatus.ObjectReference))
+		}
+	}
+	return ids
+}
+
+func (tc *Manager) objectsWithReconcileStatus(status actuation.ReconcileStatus) object.ObjMetadataSet {
+	var ids object.ObjMetadataSet
+	for _, objStatus := range tc.inventory.Status.Objects {
+		if objStatus.Reconcile == status {
+			ids = append(ids, ObjMetadataFromObjectReference(objStatus.ObjectReference))
+		}
+	}
+	return ids
+}
+
+func (tc *Manager) setObjectStatus(id object.ObjMetadata, objStatus actuation.ObjectStatus) {
+	for i, objStatus := range tc.inventory.Status.Objects {
+		if ObjMetadataEqualObjectReference(id, objStatus.ObjectReference) {
+			tc.inventory.Status.Objects[i] = objStatus
+			return
+		}
+	}
+	tc.inventory.Status.Objects = append(tc.inventory.Status.Objects, objStatus)
 }
@@ -69,6 +69,52 @@ const (
 // OwningInventoryKey is the annotation key indicating the inventory owning an object.
 const OwningInventoryKey = "config.k8s.io/owning-inventory"
 
+// PolicyKey is the annotation key overriding the inventory policy of a run
+// for an object.
+const PolicyKey = "config.k8s.io/inventory-policy"
+
+// The values of the PolicyKey annotation.
+const (
+	PolicyMustMatchValue          = "must-match"
+	PolicyAdoptIfNoInventoryValue = "adopt-if-no-inventory"
+	PolicyAdoptAllValue           = "adopt-all"
+)
+
+// PolicySource identifies what decided the inventory policy of an object.
+type PolicySource string
+
+const (
+	// PolicySourceRun: the policy of the run decided.
+	PolicySourceRun PolicySource = "run"
+	// PolicySourceAnnotation: the PolicyKey annotation of the
+	// object decided.
+	PolicySourceAnnotation PolicySource = "annotation"
+)
+
+// ObjectPolicy returns the inventory policy of the passed object, which is
+// the policy of its PolicyKey annotation if it has one, or the
+// passed policy of the run otherwise, and what decided it. Returns an
+// error if the annotation has an unknown value.
+func ObjectPolicy(obj *unstructured.Unstructured, policy Policy) (Policy, PolicySource, error) {
+	if obj == nil {
+		return policy, PolicySourceRun, nil
+	}
+	value, found := obj.GetAnnotations()[PolicyKey]
+	if !found {
+		return policy, PolicySourceRun, nil
+	}
+	switch value {
+	case PolicyMustMatchValue:
+		return PolicyMustMatch, PolicySourceAnnotation, nil
+	case PolicyAdoptIfNoInventoryValue:
+		return PolicyAdoptIfNoInventory, PolicySourceAnnotation, nil
+	case PolicyAdoptAllValue:
+		return PolicyAdoptAll, PolicySourceAnnotation, nil
+	}
+	return policy, PolicySourceRun, fmt.Errorf("invalid annotation %s: %q: must be one of %q, %q or %q",
+		PolicyKey, value, PolicyMustMatchValue, PolicyAdoptIfNoInventoryValue, PolicyAdoptAllValue)
+}
+
 // IDMatchStatus represents the result of comparing the
 // id from current inventory info and the inventory-id from a live object.
 //go:generate stringer -type=IDMatchStatus
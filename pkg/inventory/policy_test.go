@@ -253,3 +253,67 @@ func TestCanPrune(t *testing.T) {
 		}
 	}
 }
+
+func TestObjectPolicy(t *testing.T) {
+	testcases := []struct {
+		name     string
+		obj      *unstructured.Unstructured
+		policy   Policy
+		expected Policy
+		source   PolicySource
+		isError  bool
+	}{
+		{
+			name:     "nil object",
+			obj:      nil,
+			policy:   PolicyAdoptIfNoInventory,
+			expected: PolicyAdoptIfNoInventory,
+			source:   PolicySourceRun,
+		},
+		{
+			name:     "no annotation",
+			obj:      testObjectWithAnnotation(OwningInventoryKey, "random-id"),
+			policy:   PolicyMustMatch,
+			expected: PolicyMustMatch,
+			source:   PolicySourceRun,
+		},
+		{
+			name:     "must-match annotation",
+			obj:      testObjectWithAnnotation(PolicyKey, "must-match"),
+			policy:   PolicyAdoptAll,
+			expected: PolicyMustMatch,
+			source:   PolicySourceAnnotation,
+		},
+		{
+			name:     "adopt-if-no-inventory annotation",
+			obj:      testObjectWithAnnotation(PolicyKey, "adopt-if-no-inventory"),
+			policy:   PolicyMustMatch,
+			expected: PolicyAdoptIfNoInventory,
+			source:   PolicySourceAnnotation,
+		},
+		{
+			name:     "adopt-all annotation",
+			obj:      testObjectWithAnnotation(PolicyKey, "adopt-all"),
+			policy:   PolicyMustMatch,
+			expected: PolicyAdoptAll,
+			source:   PolicySourceAnnotation,
+		},
+		{
+			name:     "invalid annotation",
+			obj:      testObjectWithAnnotation(PolicyKey, "AdoptAll"),
+			policy:   PolicyMustMatch,
+			expected: PolicyMustMatch,
+			source:   PolicySourceRun,
+			isError:  true,
+		},
+	}
+	for _, tc := range testcases {
+		policy, source, err := ObjectPolicy(tc.obj, tc.policy)
+		if tc.isError != (err != nil) {
+			t.Errorf("%s: expected error %v, but got %v", tc.name, tc.isError, err)
+		}
+		if policy != tc.expected || source != tc.source {
+			t.Errorf("%s: expected %v from %s, but got %v from %s", tc.name, tc.expected, tc.source, policy, source)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+//
+// This file contains the code to shard the objects of an
+// inventory across several inventory objects, so that large
+// inventories do not exceed the size limit of a single object.
+// The first shard is the inventory object itself. The other
+// shards are inventory objects of the same kind, in the same
+// namespace, with the same inventory id label and with the
+// shard label.
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+// DefaultShardSizeLimit is the default maximum encoded size, in bytes, of
+// each inventory object. It leaves room below the 1MiB size limit of
+// objects for the fields added by the API server.
+const DefaultShardSizeLimit = 768 * 1024
+
+// assignShards distributes the passed objects across shards whose objects
+// have a total size of at most capacity, and returns the objects of each
+// shard. sizes is the size of each object in a shard. The first shard is
+// the inventory object itself. Objects stay in the shard they are
+// currently stored in, so that as few shards as possible change. New
+// objects fill the shards with room first, and then new shards. An object
+// larger than capacity is stored alone in its shard. The returned shards
+// may be empty, except for the first shard which is always returned. If
+// capacity is zero or less, the shards are not limited in size, and new
+// objects are stored in the first shard.
+func assignShards(current []object.ObjMetadataSet, objs object.ObjMetadataSet,
+	sizes map[object.ObjMetadata]int, capacity int) []object.ObjMetadataSet {
+	shardCount := len(current)
+	if shardCount == 0 {
+		shardCount = 1
+	}
+	shards := make([]object.ObjMetadataSet, shardCount)
+	used := make([]int, shardCount)
+	hasRoom := func(i int, id object.ObjMetadata) bool {
+		return capacity <= 0 || len(shards[i]) == 0 || used[i]+sizes[id] <= capacity
+	}
+	add := func(i int, id object.ObjMetadata) {
+		shards[i] = append(shards[i], id)
+		used[i] += sizes[id]
+	}
+	wanted := objs.ToMap()
+	assigned := make(map[object.ObjMetadata]struct{}, len(objs))
+	for i, shardObjs := range current {
+		for _, id := range shardObjs {
+			if _, found := wanted[id]; !found {
+				continue
+			}
+			if _, found := assigned[id]; found || !hasRoom(i, id) {
+				continue
+			}
+			add(i, id)
+			assigned[id] = struct{}{}
+		}
+	}
+	for _, id := range objs {
+		if _, found := assigned[id]; found {
+			continue
+		}
+		next := 0
+		for next < len(shards) && !hasRoom(next, id) {
+			next++
+		}
+		if next == len(shards) {
+			shards = append(shards, object.ObjMetadataSet{})
+			used = append(used, 0)
+		}
+		add(next, id)
+		assigned[id] = struct{}{}
+	}
+	return shards
+}
+
+// shardSizes returns the encoded size of each passed object in an
+// inventory object, including its result, and the room left for the
+// objects in each shard, which is ShardSizeLimit minus the encoded size of
+// the passed inventory object without objects. The result of an object is
+// the passed result, or else the result stored in the passed inventory
+// objects, which are the inventory object and its shards. Returns a zero
+// capacity if the shards are not limited in size.
+func (cic *ClusterClient) shardSizes(inv *unstructured.Unstructured, invObjs object.UnstructuredSet,
+	objs object.ObjMetadataSet, results []actuation.ObjectStatus) (map[object.ObjMetadata]int, int, error) {
+	if cic.ShardSizeLimit <= 0 {
+		return nil, 0, nil
+	}
+	objResults := make(map[object.ObjMetadata]actuation.ObjectStatus)
+	for _, invObj := range invObjs {
+		resultStorage, ok := cic.InventoryFactoryFunc(invObj).(ResultStorage)
+		if !ok {
+			continue
+		}
+		stored, err := resultStorage.LoadResults()
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, result := range stored {
+			objResults[ObjMetadataFromObjectReference(result.ObjectReference)] = result
+		}
+	}
+	for _, result := range results {
+		objResults[ObjMetadataFromObjectReference(result.ObjectReference)] = result
+	}
+
+	emptyInv, err := cic.storeInventory(inv, object.ObjMetadataSet{}, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	baseSize, err := encodedSize(emptyInv)
+	if err != nil {
+		return nil, 0, err
+	}
+	capacity := cic.ShardSizeLimit - baseSize
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	// The size of an object is measured in an empty shard, which is
+	// small to copy.
+	template := newShard(inv, 0)
+	emptySize, err := encodedSize(template)
+	if err != nil {
+		return nil, 0, err
+	}
+	sizes := make(map[object.ObjMetadata]int, len(objs))
+	for _, id := range objs {
+		var objResult []actuation.ObjectStatus
+		if result, found := objResults[id]; found {
+			objResult = []actuation.ObjectStatus{result}
+		}
+		shard, err := cic.storeInventory(template, object.ObjMetadataSet{id}, objResult)
+		if err != nil {
+			return nil, 0, err
+		}
+		size, err := encodedSize(shard)
+		if err != nil {
+			return nil, 0, err
+		}
+		sizes[id] = size - emptySize
+	}
+	return sizes, capacity, nil
+}
+
+// encodedSize returns the size of the JSON encoding of the passed object.
+func encodedSize(obj *unstructured.Unstructured) (int, error) {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode inventory object: %w", err)
+	}
+	return len(data), nil
+}
+
+// shardIndex returns the index of the passed shard, or 0 if the passed
+// object is not a shard.
+func shardIndex(obj *unstructured.Unstructured) int {
+	index, err := strconv.Atoi(obj.GetLabels()[common.InventoryShardLabel])
+	if err != nil {
+		return 0
+	}
+	return index
+}
+
+// isShard returns true if the passed object is an additional shard of an
+// inventory object.
+func isShard(obj *unstructured.Unstructured) bool {
+	_, found := obj.GetLabels()[common.InventoryShardLabel]
+	return found
+}
+
+// newShard returns a new, empty shard with the passed index of the passed
+// inventory object.
+func newShard(inv *unstructured.Unstructured, index int) *unstructured.Unstructured {
+	shard := &unstructured.Unstructured{}
+	shard.SetGroupVersionKind(inv.GroupVersionKind())
+	shard.SetNamespace(inv.GetNamespace())
+	shard.SetName(fmt.Sprintf("%s-shard-%d", inv.GetName(), index))
+	shard.SetLabels(map[string]string{
+		common.InventoryLabel:      inv.GetLabels()[common.InventoryLabel],
+		common.InventoryShardLabel: strconv.Itoa(index),
+	})
+	return shard
+}
+
+// getClusterShards returns the additional shards of the passed inventory
+// object from the cluster, ordered by index. Returns an empty list if the
+// inventory object has no inventory id label.
+func (cic *ClusterClient) getClusterShards(inv *unstructured.Unstructured) (object.UnstructuredSet, error) {
+	id := inv.GetLabels()[common.InventoryLabel]
+	if id == "" {
+		return object.UnstructuredSet{}, nil
+	}
+	mapping, err := cic.getMapping(inv)
+	if err != nil {
+		return nil, err
+	}
+	labelSelector := fmt.Sprintf("%s=%s,%s", common.InventoryLabel, id, common.InventoryShardLabel)
+	klog.V(4).Infof("inventory shards fetch by label (namespace: %q, selector: %q)", inv.GetNamespace(), labelSelector)
+	uList, err := cic.dc.Resource(mapping.Resource).Namespace(inv.GetNamespace()).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory shards from cluster: %w", err)
+	}
+	shards := object.UnstructuredSet{}
+	for i := range uList.Items {
+		shards = append(shards, &uList.Items[i])
+	}
+	sort.SliceStable(shards, func(i, j int) bool {
+		return shardIndex(shards[i]) < shardIndex(shards[j])
+	})
+	return shards, nil
+}
+
+// getClusterShardObjs returns the objects stored in the additional shards
+// of the passed inventory object.
+func (cic *ClusterClient) getClusterShardObjs(inv *unstructured.Unstructured) (object.ObjMetadataSet, error) {
+	shards, err := cic.getClusterShards(inv)
+	if err != nil {
+		return nil, err
+	}
+	objs := object.ObjMetadataSet{}
+	for _, shard := range shards {
+		shardObjs, err := cic.InventoryFactoryFunc(shard).Load()
+		if err != nil {
+			return nil, err
+		}
+		objs = objs.Union(shardObjs)
+	}
+	return objs, nil
+}
+
+// storeShards stores the passed objects across the passed cluster
+// inventory object and its shards, with shards of at most ShardSizeLimit
+// bytes, with the passed results if the inventory objects can record them.
+// Only shards whose objects or results change are updated. Creates shards
+// when the existing ones are full, and deletes the shards that become
+// empty. Returns the updated inventory object.
+func (cic *ClusterClient) storeShards(clusterInv *unstructured.Unstructured, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus, dryRun common.DryRunStrategy) (*unstructured.Unstructured, error) {
+	shards, err := cic.getClusterShards(clusterInv)
+	if err != nil {
+		return nil, err
+	}
+	invObjs := append(object.UnstructuredSet{clusterInv}, shards...)
+	current := make([]object.ObjMetadataSet, len(invObjs))
+	for i, invObj := range invObjs {
+		current[i], err = cic.InventoryFactoryFunc(invObj).Load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sizes, capacity, err := cic.shardSizes(clusterInv, invObjs, objs, results)
+	if err != nil {
+		return nil, err
+	}
+	assigned := assignShards(current, objs, sizes, capacity)
+
+	nextIndex := 1
+	if len(shards) > 0 {
+		nextIndex = shardIndex(shards[len(shards)-1]) + 1
+	}
+	for i, shardObjs := range assigned {
+		if i >= len(invObjs) {
+			if err := cic.createShard(clusterInv, nextIndex, shardObjs, results, dryRun); err != nil {
+				return nil, err
+			}
+			nextIndex++
+			continue
+		}
+		if i > 0 && len(shardObjs) == 0 {
+			// Delete the shard that became empty.
+			klog.V(4).Infof("delete empty cluster inventory shard: %s/%s", invObjs[i].GetNamespace(), invObjs[i].GetName())
+			if err := cic.deleteInventoryObjByName(invObjs[i], dryRun); err != nil {
+				return nil, fmt.Errorf("failed to delete empty inventory shard: %w", err)
+			}
+			continue
+		}
+		invObj, err := cic.storeInventory(invObjs[i], shardObjs, results)
+		if err != nil {
+			return nil, err
+		}
+		if !shardObjs.Equal(current[i]) || !equality.Semantic.DeepEqual(invObj.Object, invObjs[i].Object) {
+			klog.V(4).Infof("update cluster inventory: %s/%s (%d objects)", invObj.GetNamespace(), invObj.GetName(), len(shardObjs))
+			appliedObj, err := cic.applyInventoryObj(invObj, dryRun)
+			if err != nil {
+				return nil, fmt.Errorf("failed to write updated inventory to cluster: %w", err)
+			}
+			// Status update requires the latest ResourceVersion
+			invObj.SetResourceVersion(appliedObj.GetResourceVersion())
+		}
+		invObjs[i] = invObj
+	}
+	return invObjs[0], nil
+}
+
+// createShards creates the shards of a new inventory object that has
+// already been created, to store the objects that did not fit in it.
+func (cic *ClusterClient) createShards(inv *unstructured.Unstructured, shards []object.ObjMetadataSet,
+	dryRun common.DryRunStrategy) error {
+	for i, shardObjs := range shards {
+		if err := cic.createShard(inv, i+1, shardObjs, nil, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createShard creates the shard with the passed index of the passed
+// inventory object, storing the passed objects and results.
+func (cic *ClusterClient) createShard(inv *unstructured.Unstructured, index int, objs object.ObjMetadataSet,
+	results []actuation.ObjectStatus, dryRun common.DryRunStrategy) error {
+	shard, err := cic.storeInventory(newShard(inv, index), objs, results)
+	if err != nil {
+		return err
+	}
+	klog.V(4).Infof("create cluster inventory shard: %s/%s (%d objects)", shard.GetNamespace(), shard.GetName(), len(objs))
+	if _, err := cic.createInventoryObj(shard, dryRun); err != nil {
+		return fmt.Errorf("failed to create inventory shard: %w", err)
+	}
+	return nil
+}
+
+// deleteShards deletes the additional shards of the passed inventory
+// object from the cluster.
+func (cic *ClusterClient) deleteShards(inv *unstructured.Unstructured, dryRun common.DryRunStrategy) error {
+	shards, err := cic.getClusterShards(inv)
+	if err != nil {
+		return err
+	}
+	for _, shard := range shards {
+		if err := cic.deleteInventoryObjByName(shard, dryRun); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+	"sigs.k8s.io/cli-utils/pkg/common"
+	"sigs.k8s.io/cli-utils/pkg/object"
+)
+
+func testObjs(names ...string) object.ObjMetadataSet {
+	objs := object.ObjMetadataSet{}
+	for _, name := range names {
+		objs = append(objs, object.ObjMetadata{
+			GroupKind: schema.GroupKind{Kind: "Pod"},
+			Name:      name,
+			Namespace: testNamespace,
+		})
+	}
+	return objs
+}
+
+// shardSizeLimit returns a ShardSizeLimit that fits the passed number of
+// objects from testObjs, without results, in each shard.
+func shardSizeLimit(t *testing.T, invClient *ClusterClient, inv Info, objsPerShard int) int {
+	invClient.ShardSizeLimit = DefaultShardSizeLimit
+	sizes, capacity, err := invClient.shardSizes(invClient.invToUnstructuredFunc(inv), nil, testObjs("a"), nil)
+	require.NoError(t, err)
+	objSize := sizes[testObjs("a")[0]]
+	baseSize := DefaultShardSizeLimit - capacity
+	return baseSize + objsPerShard*objSize + objSize/2
+}
+
+func TestAssignShards(t *testing.T) {
+	testCases := map[string]struct {
+		current  []object.ObjMetadataSet
+		objs     object.ObjMetadataSet
+		sizes    map[object.ObjMetadata]int
+		capacity int
+		expected []object.ObjMetadataSet
+	}{
+		"no objects": {
+			objs:     testObjs(),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{nil},
+		},
+		"not sharded": {
+			objs:     testObjs("a", "b", "c"),
+			expected: []object.ObjMetadataSet{testObjs("a", "b", "c")},
+		},
+		"new inventory": {
+			objs:     testObjs("a", "b", "c", "d", "e"),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+				testObjs("e"),
+			},
+		},
+		"objects stay in their shard": {
+			current: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+			},
+			objs:     testObjs("d", "c", "b", "a"),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+			},
+		},
+		"new objects fill shards with room": {
+			current: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+			},
+			objs:     testObjs("b", "c", "d", "e", "f", "g"),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("b", "e"),
+				testObjs("c", "d"),
+				testObjs("f", "g"),
+			},
+		},
+		"removed objects empty a shard": {
+			current: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+				testObjs("e"),
+			},
+			objs:     testObjs("a", "e"),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("a"),
+				nil,
+				testObjs("e"),
+			},
+		},
+		"shard size reduced": {
+			current: []object.ObjMetadataSet{
+				testObjs("a", "b", "c"),
+			},
+			objs:     testObjs("a", "b", "c"),
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c"),
+			},
+		},
+		"objects fill shards by size": {
+			objs: testObjs("a", "b", "c", "d"),
+			sizes: map[object.ObjMetadata]int{
+				testObjs("a")[0]: 2,
+				testObjs("c")[0]: 2,
+			},
+			capacity: 3,
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "b"),
+				testObjs("c", "d"),
+			},
+		},
+		"object larger than the capacity is stored alone": {
+			objs: testObjs("a", "b", "c"),
+			sizes: map[object.ObjMetadata]int{
+				testObjs("b")[0]: 5,
+			},
+			capacity: 2,
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "c"),
+				testObjs("b"),
+			},
+		},
+		"sharding disabled with existing shards": {
+			current: []object.ObjMetadataSet{
+				testObjs("a"),
+				testObjs("b"),
+			},
+			objs: testObjs("a", "b", "c"),
+			expected: []object.ObjMetadataSet{
+				testObjs("a", "c"),
+				testObjs("b"),
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			sizes := map[object.ObjMetadata]int{}
+			for _, id := range tc.objs {
+				sizes[id] = 1
+			}
+			for id, size := range tc.sizes {
+				sizes[id] = size
+			}
+			shards := assignShards(tc.current, tc.objs, sizes, tc.capacity)
+			assert.Equal(t, tc.expected, shards)
+		})
+	}
+}
+
+func TestShardedInventory(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	invClient.ShardSizeLimit = shardSizeLimit(t, invClient, inv, 2)
+
+	clusterInventories := func() map[string]object.ObjMetadataSet {
+		list, err := tf.FakeDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+			Namespace(testNamespace).List(context.TODO(), metav1.ListOptions{})
+		require.NoError(t, err)
+		invs := map[string]object.ObjMetadataSet{}
+		for i := range list.Items {
+			assert.Equal(t, testInventoryLabel, list.Items[i].GetLabels()[common.InventoryLabel])
+			objs, err := WrapInventoryObj(&list.Items[i]).Load()
+			require.NoError(t, err)
+			invs[list.Items[i].GetName()] = objs
+		}
+		return invs
+	}
+	assertShards := func(expected map[string]object.ObjMetadataSet) {
+		actual := clusterInventories()
+		require.Len(t, actual, len(expected), "shards: %v", actual)
+		for name, objs := range expected {
+			assert.True(t, objs.Equal(actual[name]), "shard %s: expected %v, got %v", name, objs, actual[name])
+		}
+	}
+	shardName := func(index int) string {
+		return fmt.Sprintf("%s-shard-%d", inventoryObjName, index)
+	}
+
+	// The initial inventory is created with shards.
+	pruneObjs, err := invClient.Merge(inv, testObjs("a", "b", "c", "d", "e"), common.DryRunNone)
+	require.NoError(t, err)
+	assert.Empty(t, pruneObjs)
+	assertShards(map[string]object.ObjMetadataSet{
+		inventoryObjName: testObjs("a", "b"),
+		shardName(1):     testObjs("c", "d"),
+		shardName(2):     testObjs("e"),
+	})
+	clusterInv, err := invClient.GetClusterInventoryInfo(inv)
+	require.NoError(t, err)
+	assert.Equal(t, inventoryObjName, clusterInv.GetName())
+
+	// Objects are loaded and merged across the shards.
+	clusterObjs, err := invClient.GetClusterObjs(inv)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("a", "b", "c", "d", "e"), clusterObjs)
+
+	pruneObjs, err = invClient.Merge(inv, testObjs("a", "f", "g"), common.DryRunNone)
+	require.NoError(t, err)
+	assertObjsEqual(t, testObjs("b", "c", "d", "e"), pruneObjs)
+	assertShards(map[string]object.ObjMetadataSet{
+		inventoryObjName: testObjs("a", "b"),
+		shardName(1):     testObjs("c", "d"),
+		shardName(2):     testObjs("e", "f"),
+		shardName(3):     testObjs("g"),
+	})
+
+	// Shards that become empty are deleted.
+	err = invClient.Replace(inv, testObjs("a", "f", "g"), common.DryRunNone)
+	require.NoError(t, err)
+	assertShards(map[string]object.ObjMetadataSet{
+		inventoryObjName: testObjs("a"),
+		shardName(2):     testObjs("f"),
+		shardName(3):     testObjs("g"),
+	})
+
+	// The shards are deleted with the inventory object.
+	err = invClient.DeleteInventoryObj(inv, common.DryRunNone)
+	require.NoError(t, err)
+	assertShards(map[string]object.ObjMetadataSet{})
+}
+
+func TestShardedInventoryResults(t *testing.T) {
+	tf := cmdtesting.NewTestFactory().WithNamespace(testNamespace)
+	defer tf.Cleanup()
+
+	invClient, err := NewClient(tf, WrapInventoryObj, InvInfoToConfigMap)
+	require.NoError(t, err)
+	inv := WrapInventoryInfoObj(inventoryObj.DeepCopy())
+	invClient.ShardSizeLimit = shardSizeLimit(t, invClient, inv, 2)
+
+	results := func(names ...string) []actuation.ObjectStatus {
+		var results []actuation.ObjectStatus
+		for _, id := range testObjs(names...) {
+			results = append(results, actuation.ObjectStatus{
+				ObjectReference: ObjectReferenceFromObjMetadata(id),
+				Strategy:        actuation.ActuationStrategyApply,
+				Actuation:       actuation.ActuationSucceeded,
+				Reconcile:       actuation.ReconcileSucceeded,
+				UID:             types.UID(id.Name + "-uid"),
+			})
+		}
+		return results
+	}
+
+	// No results are recorded before the inventory exists.
+	objResults, err := invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.Empty(t, objResults)
+
+	_, err = invClient.Merge(inv, testObjs("a", "b", "c"), common.DryRunNone)
+	require.NoError(t, err)
+	objResults, err = invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.Empty(t, objResults)
+
+	// The results are recorded across the shards.
+	err = invClient.ReplaceWithResults(inv, testObjs("a", "b", "c", "d", "e"), results("a", "b", "c", "d", "e"), common.DryRunNone)
+	require.NoError(t, err)
+	objResults, err = invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, results("a", "b", "c", "d", "e"), objResults)
+	// The results count towards the size of the shards, so each shard
+	// only fits one object with its result.
+	list, err := tf.FakeDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).
+		Namespace(testNamespace).List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 5)
+
+	// The results of the retained objects are kept by Merge and Replace.
+	_, err = invClient.Merge(inv, testObjs("f"), common.DryRunNone)
+	require.NoError(t, err)
+	err = invClient.Replace(inv, testObjs("a", "c", "f"), common.DryRunNone)
+	require.NoError(t, err)
+	objResults, err = invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, results("a", "c"), objResults)
+
+	// Dry-run does not record results.
+	err = invClient.ReplaceWithResults(inv, testObjs("a", "c", "f"), results("f"), common.DryRunClient)
+	require.NoError(t, err)
+	objResults, err = invClient.GetClusterObjResults(inv)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, results("a", "c"), objResults)
+}
+
+func assertObjsEqual(t *testing.T, expected, actual object.ObjMetadataSet) {
+	assert.True(t, expected.Equal(actual), "expected %v, got %v", expected, actual)
+}
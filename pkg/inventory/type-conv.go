@@ -4,6 +4,10 @@
 package inventory
 
 import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
 	"sigs.k8s.io/cli-utils/pkg/object"
@@ -38,3 +42,38 @@ func ObjMetadataFromObjectReference(ref actuation.ObjectReference) object.ObjMet
 		Namespace: ref.Namespace,
 	}
 }
+
+// ObjStatusFromUnstructured reads the status of the objects stored in the
+// "status.objects" field of an inventory object.
+func ObjStatusFromUnstructured(inv *unstructured.Unstructured) ([]actuation.ObjectStatus, error) {
+	status, found, err := unstructured.NestedMap(inv.Object, "status")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory status: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	invStatus := actuation.InventoryStatus{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(status, &invStatus); err != nil {
+		return nil, fmt.Errorf("failed to read inventory status: %w", err)
+	}
+	return invStatus.Objects, nil
+}
+
+// SetObjStatusInUnstructured stores the status of the objects in the
+// "status.objects" field of an inventory object. Other status fields are
+// left unchanged.
+func SetObjStatusInUnstructured(inv *unstructured.Unstructured, objStatus []actuation.ObjectStatus) error {
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&actuation.InventoryStatus{
+		Objects: objStatus,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to convert inventory status: %w", err)
+	}
+	objects, found := status["objects"]
+	if !found {
+		unstructured.RemoveNestedField(inv.Object, "status", "objects")
+		return nil
+	}
+	return unstructured.SetNestedField(inv.Object, objects, "status", "objects")
+}
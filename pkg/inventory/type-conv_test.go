@@ -0,0 +1,97 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/apis/actuation"
+)
+
+func TestObjStatusUnstructured(t *testing.T) {
+	objStatuses := []actuation.ObjectStatus{
+		{
+			ObjectReference: actuation.ObjectReference{
+				Group:     "apps",
+				Kind:      "Deployment",
+				Name:      "foo",
+				Namespace: "default",
+			},
+			Strategy:   actuation.ActuationStrategyApply,
+			Actuation:  actuation.ActuationSucceeded,
+			Reconcile:  actuation.ReconcileSucceeded,
+			UID:        "dep-uid",
+			Generation: 2,
+		},
+		{
+			ObjectReference: actuation.ObjectReference{
+				Kind:      "ConfigMap",
+				Name:      "bar",
+				Namespace: "default",
+			},
+			Strategy:  actuation.ActuationStrategyDelete,
+			Actuation: actuation.ActuationFailed,
+			Reconcile: actuation.ReconcilePending,
+		},
+	}
+
+	testCases := map[string]struct {
+		status      map[string]interface{}
+		objStatuses []actuation.ObjectStatus
+		expected    []actuation.ObjectStatus
+	}{
+		"no status": {
+			objStatuses: nil,
+			expected:    nil,
+		},
+		"object status stored": {
+			objStatuses: objStatuses,
+			expected:    objStatuses,
+		},
+		"object status replaced": {
+			status: map[string]interface{}{
+				"objects": []interface{}{
+					map[string]interface{}{"kind": "Secret", "name": "old"},
+				},
+			},
+			objStatuses: objStatuses[:1],
+			expected:    objStatuses[:1],
+		},
+		"object status removed": {
+			status: map[string]interface{}{
+				"objects": []interface{}{
+					map[string]interface{}{"kind": "Secret", "name": "old"},
+				},
+			},
+			objStatuses: nil,
+			expected:    nil,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			inv := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "cli-utils.example.io/v1alpha1",
+				"kind":       "Inventory",
+			}}
+			if tc.status != nil {
+				tc.status["phase"] = "Ready"
+				inv.Object["status"] = tc.status
+			}
+			err := SetObjStatusInUnstructured(inv, tc.objStatuses)
+			require.NoError(t, err)
+			actual, err := ObjStatusFromUnstructured(inv)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			if tc.status != nil {
+				// Other status fields are kept.
+				phase, _, _ := unstructured.NestedString(inv.Object, "status", "phase")
+				assert.Equal(t, "Ready", phase)
+			}
+		})
+	}
+}
@@ -4,6 +4,9 @@
 package manifestreader
 
 import (
+	"os"
+	"path/filepath"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/kustomize/kyaml/kio"
 	"sigs.k8s.io/kustomize/kyaml/kio/kioutil"
@@ -18,14 +21,23 @@ var _ ManifestReader = &PathManifestReader{}
 type PathManifestReader struct {
 	Path string
 
+	// SkipFiles are the paths of the files which are not read from the
+	// package, like an inventory file stored in the package directory.
+	SkipFiles []string
+
 	ReaderOptions
 }
 
 // Read reads the manifests and returns them as Info objects.
 func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 	var objs []*unstructured.Unstructured
+	skipFunc, err := p.skipFileFunc()
+	if err != nil {
+		return objs, err
+	}
 	nodes, err := (&kio.LocalPackageReader{
-		PackagePath: p.Path,
+		PackagePath:  p.Path,
+		FileSkipFunc: skipFunc,
 	}).Read()
 	if err != nil {
 		return objs, err
@@ -48,3 +60,31 @@ func (p *PathManifestReader) Read() ([]*unstructured.Unstructured, error) {
 	err = SetNamespaces(p.Mapper, objs, p.Namespace, p.EnforceNamespace)
 	return objs, err
 }
+
+// skipFileFunc returns the function which skips the SkipFiles when the
+// package is read, or nil if there are none. The function is passed the
+// path of each file relative to the package directory, or to the parent
+// directory if the package is a single file.
+func (p *PathManifestReader) skipFileFunc() (kio.LocalPackageSkipFileFunc, error) {
+	if len(p.SkipFiles) == 0 {
+		return nil, nil
+	}
+	root, err := filepath.Abs(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		root = filepath.Dir(root)
+	}
+	skip := make(map[string]bool, len(p.SkipFiles))
+	for _, path := range p.SkipFiles {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		skip[absPath] = true
+	}
+	return func(relPath string) bool {
+		return skip[filepath.Join(root, relPath)]
+	}, nil
+}
@@ -19,6 +19,7 @@ func TestPathManifestReader_Read(t *testing.T) {
 		namespace        string
 		enforceNamespace bool
 		validate         bool
+		skipFiles        []string
 
 		infosCount int
 		namespaces []string
@@ -44,6 +45,18 @@ func TestPathManifestReader_Read(t *testing.T) {
 			infosCount: 2,
 			namespaces: []string{"default", "default"},
 		},
+		"skipped files are not read": {
+			manifests: map[string]string{
+				"dep.yaml": depManifest,
+				"cm.yaml":  cmManifest,
+			},
+			namespace:        "default",
+			enforceNamespace: true,
+			skipFiles:        []string{"cm.yaml"},
+
+			infosCount: 1,
+			namespaces: []string{"default"},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -64,8 +77,14 @@ func TestPathManifestReader_Read(t *testing.T) {
 				assert.NoError(t, err)
 			}
 
+			var skipFiles []string
+			for _, filename := range tc.skipFiles {
+				skipFiles = append(skipFiles, filepath.Join(dir, filename))
+			}
+
 			objs, err := (&PathManifestReader{
-				Path: dir,
+				Path:      dir,
+				SkipFiles: skipFiles,
 				ReaderOptions: ReaderOptions{
 					Mapper:           mapper,
 					Namespace:        tc.namespace,
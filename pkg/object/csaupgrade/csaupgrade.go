@@ -0,0 +1,156 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package csaupgrade moves the ownership of the fields of live objects
+// that were applied client-side to a server-side apply field manager.
+//
+// Fields applied client-side are owned by update operations, so a later
+// server-side apply that no longer includes them does not remove them.
+// Once the field manager owns them through an apply operation, the next
+// server-side apply removes the fields that were removed locally.
+package csaupgrade
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// ClientSideApplyManager is the field manager kubectl uses for client-side
+// apply.
+const ClientSideApplyManager = "kubectl-client-side-apply"
+
+// lastAppliedPath is the path of the last-applied-configuration annotation
+// in the managed fields.
+var lastAppliedPath = fieldpath.MakePathOrDie("metadata", "annotations", v1.LastAppliedConfigAnnotation)
+
+// UpgradeManagedFields moves the fields owned by the update operations of
+// the csaManagers to the apply operation of the ssaManager, and removes
+// the last-applied-configuration annotation from the object. Returns true
+// if the object was changed.
+func UpgradeManagedFields(obj *unstructured.Unstructured, csaManagers sets.String, ssaManager string) (bool, error) {
+	entries := obj.GetManagedFields()
+	upgraded := make([]metav1.ManagedFieldsEntry, 0, len(entries))
+	owned := &fieldpath.Set{}
+	ssaIndex := -1
+	var ssaEntry metav1.ManagedFieldsEntry
+	migrated := false
+	for _, e := range entries {
+		isCSA := e.Operation == metav1.ManagedFieldsOperationUpdate && e.Subresource == "" &&
+			csaManagers.Has(e.Manager)
+		isSSA := e.Operation == metav1.ManagedFieldsOperationApply && e.Subresource == "" &&
+			e.Manager == ssaManager
+		if !isCSA && !isSSA {
+			upgraded = append(upgraded, e)
+			continue
+		}
+		if e.FieldsV1 != nil {
+			set := &fieldpath.Set{}
+			if err := set.FromJSON(bytes.NewReader(e.FieldsV1.Raw)); err != nil {
+				return false, fmt.Errorf("failed to parse managed fields of %q: %w", e.Manager, err)
+			}
+			owned = owned.Union(set)
+		}
+		if ssaIndex < 0 {
+			// Keep the position of the first entry that is merged.
+			ssaIndex = len(upgraded)
+			upgraded = append(upgraded, metav1.ManagedFieldsEntry{})
+		}
+		if isSSA {
+			ssaEntry = e
+		} else {
+			migrated = true
+			if ssaEntry.APIVersion == "" {
+				ssaEntry.APIVersion = e.APIVersion
+			}
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	_, hasLastApplied := annotations[v1.LastAppliedConfigAnnotation]
+	if !migrated && !hasLastApplied {
+		return false, nil
+	}
+	if hasLastApplied {
+		delete(annotations, v1.LastAppliedConfigAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+	if !migrated {
+		return true, nil
+	}
+
+	owned = owned.Difference(fieldpath.NewSet(lastAppliedPath))
+	if owned.Empty() {
+		upgraded = append(upgraded[:ssaIndex], upgraded[ssaIndex+1:]...)
+	} else {
+		fields, err := owned.ToJSON()
+		if err != nil {
+			return false, fmt.Errorf("failed to serialize managed fields of %q: %w", ssaManager, err)
+		}
+		now := metav1.Now()
+		ssaEntry.Manager = ssaManager
+		ssaEntry.Operation = metav1.ManagedFieldsOperationApply
+		ssaEntry.Time = &now
+		ssaEntry.FieldsType = "FieldsV1"
+		ssaEntry.FieldsV1 = &metav1.FieldsV1{Raw: fields}
+		upgraded[ssaIndex] = ssaEntry
+	}
+	obj.SetManagedFields(upgraded)
+	return true, nil
+}
+
+// UpgradeManagedFieldsPatch returns a JSON patch that upgrades the managed
+// fields of the object, like UpgradeManagedFields, or nil if the object
+// does not need to be upgraded. The patch fails if the object was changed
+// since it was read.
+func UpgradeManagedFieldsPatch(obj *unstructured.Unstructured, csaManagers sets.String,
+	ssaManager string) ([]byte, error) {
+	upgraded := obj.DeepCopy()
+	changed, err := UpgradeManagedFields(upgraded, csaManagers, ssaManager)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	var patch []map[string]interface{}
+	if rv := obj.GetResourceVersion(); rv != "" {
+		patch = append(patch, map[string]interface{}{
+			"op":    "test",
+			"path":  "/metadata/resourceVersion",
+			"value": rv,
+		})
+	}
+	if _, found := obj.GetAnnotations()[v1.LastAppliedConfigAnnotation]; found {
+		patch = append(patch, map[string]interface{}{
+			"op":   "remove",
+			"path": "/metadata/annotations/" + escapePointer(v1.LastAppliedConfigAnnotation),
+		})
+	}
+	if !managedFieldsEqual(upgraded, obj) {
+		patch = append(patch, map[string]interface{}{
+			"op":    "replace",
+			"path":  "/metadata/managedFields",
+			"value": upgraded.GetManagedFields(),
+		})
+	}
+	return json.Marshal(patch)
+}
+
+func managedFieldsEqual(a, b *unstructured.Unstructured) bool {
+	aFields, _, _ := unstructured.NestedFieldNoCopy(a.Object, "metadata", "managedFields")
+	bFields, _, _ := unstructured.NestedFieldNoCopy(b.Object, "metadata", "managedFields")
+	aJSON, aErr := json.Marshal(aFields)
+	bJSON, bErr := json.Marshal(bFields)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// escapePointer escapes a key for use in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -0,0 +1,185 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package csaupgrade
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	lastAppliedFields = `{"f:metadata":{"f:annotations":{"f:kubectl.kubernetes.io/last-applied-configuration":{}}}}`
+	csaFields         = `{"f:metadata":{"f:annotations":{".":{},"f:kubectl.kubernetes.io/last-applied-configuration":{}}},"f:spec":{"f:replicas":{}}}`
+	templateFields    = `{"f:spec":{"f:template":{"f:spec":{"f:containers":{}}}}}`
+	replicasFields    = `{"f:metadata":{"f:annotations":{}},"f:spec":{"f:replicas":{}}}`
+	mergedFields      = `{"f:metadata":{"f:annotations":{}},"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{}}}}}`
+	statusFields      = `{"f:status":{"f:replicas":{}}}`
+)
+
+func deployment(lastApplied bool, entries ...metav1.ManagedFieldsEntry) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":            "dep",
+				"namespace":       "default",
+				"resourceVersion": "42",
+			},
+			"spec": map[string]interface{}{
+				"replicas": int64(1),
+			},
+		},
+	}
+	if lastApplied {
+		obj.SetAnnotations(map[string]string{
+			v1.LastAppliedConfigAnnotation: `{"spec":{"replicas":1}}`,
+		})
+	}
+	obj.SetManagedFields(entries)
+	return obj
+}
+
+func entry(manager string, op metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  op,
+		APIVersion: "apps/v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func TestUpgradeManagedFields(t *testing.T) {
+	csaManagers := sets.NewString(ClientSideApplyManager, "kubectl")
+
+	testCases := map[string]struct {
+		obj             *unstructured.Unstructured
+		expectedChanged bool
+		expectedEntries []metav1.ManagedFieldsEntry
+	}{
+		"applied server-side; unchanged": {
+			obj: deployment(false,
+				entry("kubectl", metav1.ManagedFieldsOperationApply, replicasFields),
+			),
+			expectedChanged: false,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, replicasFields),
+			},
+		},
+		"applied client-side by kubectl; fields moved to apply": {
+			obj: deployment(true,
+				entry(ClientSideApplyManager, metav1.ManagedFieldsOperationUpdate, csaFields),
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+			),
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, replicasFields),
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+			},
+		},
+		"applied client-side with the field manager; fields moved to apply": {
+			obj: deployment(true,
+				entry("kubectl", metav1.ManagedFieldsOperationUpdate, csaFields),
+			),
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, replicasFields),
+			},
+		},
+		"applied both ways; fields merged into apply": {
+			obj: deployment(true,
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+				entry(ClientSideApplyManager, metav1.ManagedFieldsOperationUpdate, csaFields),
+				entry("kubectl", metav1.ManagedFieldsOperationApply, templateFields),
+			),
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+				entry("kubectl", metav1.ManagedFieldsOperationApply, mergedFields),
+			},
+		},
+		"only last-applied annotation owned; entry removed": {
+			obj: deployment(true,
+				entry(ClientSideApplyManager, metav1.ManagedFieldsOperationUpdate, lastAppliedFields),
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+			),
+			expectedChanged: true,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("controller", metav1.ManagedFieldsOperationUpdate, statusFields),
+			},
+		},
+		"updates by other managers; unchanged": {
+			obj: deployment(false,
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, replicasFields),
+			),
+			expectedChanged: false,
+			expectedEntries: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, replicasFields),
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			changed, err := UpgradeManagedFields(tc.obj, csaManagers, "kubectl")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedChanged, changed)
+
+			entries := tc.obj.GetManagedFields()
+			for i := range entries {
+				if entries[i].Operation == metav1.ManagedFieldsOperationApply && changed {
+					assert.NotNil(t, entries[i].Time)
+					entries[i].Time = nil
+				}
+			}
+			assert.Equal(t, tc.expectedEntries, entries)
+			assert.NotContains(t, tc.obj.GetAnnotations(), v1.LastAppliedConfigAnnotation)
+		})
+	}
+}
+
+func TestUpgradeManagedFieldsPatch(t *testing.T) {
+	csaManagers := sets.NewString(ClientSideApplyManager)
+
+	obj := deployment(false,
+		entry("kubectl", metav1.ManagedFieldsOperationApply, replicasFields),
+	)
+	patch, err := UpgradeManagedFieldsPatch(obj, csaManagers, "kubectl")
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+
+	obj = deployment(true,
+		entry(ClientSideApplyManager, metav1.ManagedFieldsOperationUpdate, csaFields),
+	)
+	patch, err = UpgradeManagedFieldsPatch(obj, csaManagers, "kubectl")
+	require.NoError(t, err)
+
+	var ops []map[string]interface{}
+	require.NoError(t, json.Unmarshal(patch, &ops))
+	require.Len(t, ops, 3)
+	assert.Equal(t, map[string]interface{}{
+		"op":    "test",
+		"path":  "/metadata/resourceVersion",
+		"value": "42",
+	}, ops[0])
+	assert.Equal(t, map[string]interface{}{
+		"op":   "remove",
+		"path": "/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration",
+	}, ops[1])
+	assert.Equal(t, "replace", ops[2]["op"])
+	assert.Equal(t, "/metadata/managedFields", ops[2]["path"])
+	assert.Len(t, ops[2]["value"], 1)
+
+	// The passed object is not changed.
+	assert.Contains(t, obj.GetAnnotations(), v1.LastAppliedConfigAnnotation)
+	assert.Equal(t, ClientSideApplyManager, obj.GetManagedFields()[0].Manager)
+}
@@ -0,0 +1,291 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package drift detects changes made to live objects after they were
+// applied, by comparing the live objects with the last applied state.
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// Source is where the last applied state of an object was read from.
+type Source int
+
+const (
+	// SourceNone means the object has no last applied state, because it
+	// was not applied with the field manager or with client-side apply.
+	SourceNone Source = iota
+	// SourceManagedFields means the last applied state was read from the
+	// server-side apply managedFields.
+	SourceManagedFields
+	// SourceLastApplied means the last applied state was read from the
+	// last-applied-configuration annotation.
+	SourceLastApplied
+)
+
+// String returns the name of the source.
+func (s Source) String() string {
+	switch s {
+	case SourceManagedFields:
+		return "managedFields"
+	case SourceLastApplied:
+		return "last-applied-configuration"
+	default:
+		return "none"
+	}
+}
+
+// Options configure how drift is detected.
+type Options struct {
+	// FieldManager is the field manager used for server-side apply.
+	FieldManager string
+	// IgnoreManagers are field managers whose changes are not drift,
+	// like controllers that update the objects they manage.
+	IgnoreManagers []string
+}
+
+// Result is the drift of a single object.
+type Result struct {
+	// Source is where the last applied state was read from.
+	Source Source
+	// Fields are the paths of the fields that changed since the object
+	// was applied, sorted.
+	Fields []string
+}
+
+// Drifted returns true if any fields changed since the object was applied.
+func (r Result) Drifted() bool {
+	return len(r.Fields) > 0
+}
+
+// Detect compares the live object with its last applied state.
+//
+// If the object was applied server-side by the field manager, changing
+// an applied field moves its ownership to the manager that changed it.
+// The drifted fields are the fields owned through an update by other
+// managers after the last apply, that are not also owned by the field
+// manager, and that are part of the applied field set. Otherwise, the
+// fields in the last-applied-configuration annotation are compared with
+// their live values.
+func Detect(live *unstructured.Unstructured, o Options) (Result, error) {
+	entries := live.GetManagedFields()
+	applied, found := lastApply(entries, o.FieldManager)
+	if found {
+		fields, err := managedFieldsDrift(entries, applied, o)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Source: SourceManagedFields, Fields: fields}, nil
+	}
+	if lastApplied, found := live.GetAnnotations()[v1.LastAppliedConfigAnnotation]; found {
+		fields, err := lastAppliedDrift(live, lastApplied)
+		if err != nil {
+			return Result{}, err
+		}
+		return Result{Source: SourceLastApplied, Fields: fields}, nil
+	}
+	return Result{Source: SourceNone}, nil
+}
+
+// lastApply returns the latest server-side apply entry of the field
+// manager, if there is one.
+func lastApply(entries []metav1.ManagedFieldsEntry, manager string) (metav1.ManagedFieldsEntry, bool) {
+	var applied metav1.ManagedFieldsEntry
+	found := false
+	for _, e := range entries {
+		if e.Manager != manager || e.Operation != metav1.ManagedFieldsOperationApply || e.Subresource != "" {
+			continue
+		}
+		if !found || (e.Time != nil && applied.Time != nil && applied.Time.Before(e.Time)) {
+			applied = e
+			found = true
+		}
+	}
+	return applied, found
+}
+
+func managedFieldsDrift(entries []metav1.ManagedFieldsEntry, applied metav1.ManagedFieldsEntry,
+	o Options) ([]string, error) {
+	owned := &fieldpath.Set{}
+	changed := &fieldpath.Set{}
+	for _, e := range entries {
+		if e.Subresource != "" || e.FieldsV1 == nil {
+			continue
+		}
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(e.FieldsV1.Raw)); err != nil {
+			return nil, fmt.Errorf("failed to parse managed fields of %q: %w", e.Manager, err)
+		}
+		switch {
+		case e.Manager == o.FieldManager:
+			owned = owned.Union(set)
+		case e.Operation != metav1.ManagedFieldsOperationUpdate || ignored(e.Manager, o.IgnoreManagers):
+		case applied.Time == nil || e.Time == nil || applied.Time.Before(e.Time):
+			changed = changed.Union(set)
+		}
+	}
+	var fields []string
+	changed.Difference(owned).Leaves().Iterate(func(p fieldpath.Path) {
+		if inAppliedSet(owned, p) {
+			fields = append(fields, p.String())
+		}
+	})
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// inAppliedSet returns true if a field moved to another manager may have
+// been applied by the field manager. A moved field is no longer in the
+// set of the field manager, so a field is considered applied when its
+// parent still is. The status is never applied, and neither are metadata
+// fields moved to another manager: a changed label or annotation cannot
+// be told apart from one added by a controller.
+func inAppliedSet(owned *fieldpath.Set, p fieldpath.Path) bool {
+	if len(p) < 2 || p[0].FieldName == nil {
+		return false
+	}
+	switch *p[0].FieldName {
+	case "status", "metadata":
+		return false
+	}
+	set := owned
+	for _, pe := range p[:len(p)-1] {
+		child, found := set.Children.Get(pe)
+		if !found {
+			return false
+		}
+		set = child
+	}
+	return true
+}
+
+func ignored(manager string, ignoreManagers []string) bool {
+	for _, m := range ignoreManagers {
+		if manager == m {
+			return true
+		}
+	}
+	return false
+}
+
+func lastAppliedDrift(live *unstructured.Unstructured, lastApplied string) ([]string, error) {
+	applied := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lastApplied), &applied); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", v1.LastAppliedConfigAnnotation, err)
+	}
+	var fields []string
+	compareFields(applied, live.Object, "", &fields)
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// compareFields appends the paths of the fields in the applied map whose
+// live value is different. The applied object is compared as a subset of
+// the live object, so that fields defaulted or added by the server are
+// not drift. Maps are compared field by field, and lists item by item.
+func compareFields(applied, live map[string]interface{}, path string, fields *[]string) {
+	for key, appliedValue := range applied {
+		compareValues(appliedValue, live[key], path+"."+key, fields)
+	}
+}
+
+// compareValues appends the path, or the paths of its fields, if the live
+// value differs from the applied value. A missing live value is nil.
+func compareValues(appliedValue, liveValue interface{}, path string, fields *[]string) {
+	switch applied := appliedValue.(type) {
+	case map[string]interface{}:
+		if live, ok := liveValue.(map[string]interface{}); ok {
+			compareFields(applied, live, path, fields)
+			return
+		}
+	case []interface{}:
+		if live, ok := liveValue.([]interface{}); ok {
+			compareLists(applied, live, path, fields)
+			return
+		}
+	}
+	if !reflect.DeepEqual(normalize(appliedValue), normalize(liveValue)) {
+		*fields = append(*fields, path)
+	}
+}
+
+// compareLists compares the items of an applied list with the live list.
+// Lists of objects with a name, like containers, are matched by name and
+// the live list may contain more items. Other lists are matched by index
+// and must have the same length.
+func compareLists(applied, live []interface{}, path string, fields *[]string) {
+	if names, ok := itemNames(applied); ok {
+		liveNames, _ := itemNames(live)
+		for i, name := range names {
+			var liveItem interface{}
+			for j, liveName := range liveNames {
+				if liveName == name {
+					liveItem = live[j]
+					break
+				}
+			}
+			compareValues(applied[i], liveItem, fmt.Sprintf("%s[name=%s]", path, name), fields)
+		}
+		return
+	}
+	if len(applied) != len(live) {
+		*fields = append(*fields, path)
+		return
+	}
+	for i := range applied {
+		compareValues(applied[i], live[i], fmt.Sprintf("%s[%d]", path, i), fields)
+	}
+}
+
+// itemNames returns the names of the items of a list, if all of them are
+// objects with a name.
+func itemNames(list []interface{}) ([]string, bool) {
+	names := make([]string, len(list))
+	for i, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		names[i] = name
+	}
+	return names, len(list) > 0
+}
+
+// normalize converts numbers to float64, because the live object may
+// contain int64 values, and the JSON decoded applied object only float64.
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = normalize(value)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, value := range v {
+			l[i] = normalize(value)
+		}
+		return l
+	default:
+		return value
+	}
+}
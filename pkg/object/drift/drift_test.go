@@ -0,0 +1,209 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package drift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var (
+	applyTime  = metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	beforeTime = metav1.NewTime(applyTime.Add(-time.Hour))
+	afterTime  = metav1.NewTime(applyTime.Add(time.Hour))
+)
+
+func deployment(replicas int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":      "dep",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"replicas": replicas,
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":            "app",
+								"image":           "app:v1",
+								"imagePullPolicy": "IfNotPresent",
+								"args":            []interface{}{"--port", int64(8080)},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func entry(manager string, op metav1.ManagedFieldsOperationType, t metav1.Time,
+	fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  op,
+		APIVersion: "apps/v1",
+		Time:       &t,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+const (
+	appliedFields    = `{"f:metadata":{"f:annotations":{"f:owner":{}}},"f:spec":{"f:replicas":{},"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{".":{},"f:image":{},"f:name":{}}}}}}}`
+	replicasFields   = `{"f:spec":{"f:replicas":{}}}`
+	statusFields     = `{"f:status":{"f:replicas":{}}}`
+	annotationFields = `{"f:metadata":{"f:annotations":{"f:revision":{}}}}`
+	strategyFields   = `{"f:spec":{"f:strategy":{"f:type":{}}}}`
+	pullPolicyFields = `{"f:spec":{"f:template":{"f:spec":{"f:containers":{"k:{\"name\":\"app\"}":{"f:imagePullPolicy":{}}}}}}}`
+)
+
+func TestDetect(t *testing.T) {
+	testCases := map[string]struct {
+		managedFields  []metav1.ManagedFieldsEntry
+		lastApplied    string
+		replicas       int64
+		expectedSource Source
+		expectedFields []string
+		isError        bool
+	}{
+		"applied server-side, no changes": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, appliedFields),
+			},
+			replicas:       1,
+			expectedSource: SourceManagedFields,
+		},
+		"field changed by another manager after apply": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, `{"f:spec":{"f:template":{}}}`),
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, afterTime, replicasFields),
+			},
+			replicas:       3,
+			expectedSource: SourceManagedFields,
+			expectedFields: []string{".spec.replicas"},
+		},
+		"field changed before apply": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl-edit", metav1.ManagedFieldsOperationUpdate, beforeTime, replicasFields),
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, `{"f:spec":{"f:template":{}}}`),
+			},
+			replicas:       3,
+			expectedSource: SourceManagedFields,
+		},
+		"field changed by ignored manager": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, `{"f:spec":{"f:template":{}}}`),
+				entry("hpa-controller", metav1.ManagedFieldsOperationUpdate, afterTime, replicasFields),
+			},
+			replicas:       3,
+			expectedSource: SourceManagedFields,
+		},
+		"status and metadata changed by another manager": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, appliedFields),
+				entry("other", metav1.ManagedFieldsOperationUpdate, afterTime, statusFields),
+				entry("other", metav1.ManagedFieldsOperationUpdate, afterTime, annotationFields),
+			},
+			replicas:       1,
+			expectedSource: SourceManagedFields,
+		},
+		"field outside the applied field set": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, appliedFields),
+				entry("other", metav1.ManagedFieldsOperationUpdate, afterTime, strategyFields),
+			},
+			replicas:       1,
+			expectedSource: SourceManagedFields,
+		},
+		"list item field changed by another manager": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationApply, applyTime, appliedFields),
+				entry("other", metav1.ManagedFieldsOperationUpdate, afterTime, pullPolicyFields),
+			},
+			replicas:       1,
+			expectedSource: SourceManagedFields,
+			expectedFields: []string{`.spec.template.spec.containers[name="app"].imagePullPolicy`},
+		},
+		"last-applied-configuration, no changes": {
+			lastApplied:    `{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":1}}`,
+			replicas:       1,
+			expectedSource: SourceLastApplied,
+		},
+		"last-applied-configuration, field changed": {
+			lastApplied:    `{"apiVersion":"apps/v1","kind":"Deployment","spec":{"replicas":1,"paused":true}}`,
+			replicas:       3,
+			expectedSource: SourceLastApplied,
+			expectedFields: []string{".spec.paused", ".spec.replicas"},
+		},
+		"last-applied-configuration, list with defaulted fields": {
+			lastApplied:    `{"spec":{"template":{"spec":{"containers":[{"name":"app","image":"app:v1","args":["--port",8080]}]}}}}`,
+			replicas:       1,
+			expectedSource: SourceLastApplied,
+		},
+		"last-applied-configuration, list item changed": {
+			lastApplied:    `{"spec":{"template":{"spec":{"containers":[{"name":"app","image":"app:v0","args":["--port",80]}]}}}}`,
+			replicas:       1,
+			expectedSource: SourceLastApplied,
+			expectedFields: []string{
+				".spec.template.spec.containers[name=app].args[1]",
+				".spec.template.spec.containers[name=app].image",
+			},
+		},
+		"last-applied-configuration, list item removed": {
+			lastApplied:    `{"spec":{"template":{"spec":{"containers":[{"name":"app","args":["--port"]},{"name":"sidecar"}]}}}}`,
+			replicas:       1,
+			expectedSource: SourceLastApplied,
+			expectedFields: []string{
+				".spec.template.spec.containers[name=app].args",
+				".spec.template.spec.containers[name=sidecar]",
+			},
+		},
+		"invalid last-applied-configuration": {
+			lastApplied: `{`,
+			isError:     true,
+		},
+		"not applied": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				entry("kubectl-create", metav1.ManagedFieldsOperationUpdate, applyTime, appliedFields),
+			},
+			replicas:       1,
+			expectedSource: SourceNone,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			live := deployment(tc.replicas)
+			live.SetManagedFields(tc.managedFields)
+			if tc.lastApplied != "" {
+				live.SetAnnotations(map[string]string{
+					v1.LastAppliedConfigAnnotation: tc.lastApplied,
+				})
+			}
+
+			result, err := Detect(live, Options{
+				FieldManager:   "kubectl",
+				IgnoreManagers: []string{"hpa-controller"},
+			})
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSource, result.Source)
+			assert.Equal(t, tc.expectedFields, result.Fields)
+			assert.Equal(t, len(tc.expectedFields) > 0, result.Drifted())
+		})
+	}
+}
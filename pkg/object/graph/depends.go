@@ -28,27 +28,15 @@ func SortObjs(objs object.UnstructuredSet) ([]object.UnstructuredSet, error) {
 	if len(objs) == 0 {
 		return objSets, nil
 	}
-	var errors []error
 	// Convert to IDs (same length & order as objs)
 	ids := object.UnstructuredSetToObjMetadataSet(objs)
 	// Create the graph, and build a map of object metadata to the object (Unstructured).
-	g := New()
+	g, errors := newDependencyGraph(objs, ids)
 	objToUnstructured := map[object.ObjMetadata]*unstructured.Unstructured{}
 	for i, obj := range objs {
 		id := ids[i]
 		objToUnstructured[id] = obj
 	}
-	// Add objects as graph vertices
-	addVertices(g, ids)
-	// Add dependencies as graph edges
-	addCRDEdges(g, objs, ids)
-	addNamespaceEdges(g, objs, ids)
-	if err := addDependsOnEdges(g, objs, ids); err != nil {
-		errors = append(errors, err)
-	}
-	if err := addApplyTimeMutationEdges(g, objs, ids); err != nil {
-		errors = append(errors, err)
-	}
 	// Run topological sort on the graph.
 	sortedObjSets, err := g.Sort()
 	if err != nil {
@@ -75,6 +63,49 @@ func SortObjs(objs object.UnstructuredSet) ([]object.UnstructuredSet, error) {
 	return objSets, nil
 }
 
+// Dependencies returns the set of objects each of the passed objects
+// depends on, using the same explicit and implicit dependencies as
+// SortObjs. Dependencies on objects outside the passed set are ignored.
+func Dependencies(objs object.UnstructuredSet) (map[object.ObjMetadata]object.ObjMetadataSet, error) {
+	ids := object.UnstructuredSetToObjMetadataSet(objs)
+	g, errors := newDependencyGraph(objs, ids)
+	deps := make(map[object.ObjMetadata]object.ObjMetadataSet, len(ids))
+	for _, id := range ids {
+		deps[id] = object.ObjMetadataSet{}
+	}
+	for _, edge := range g.GetEdges() {
+		if _, found := deps[edge.From]; !found || !ids.Contains(edge.To) {
+			continue
+		}
+		deps[edge.From] = append(deps[edge.From], edge.To)
+	}
+	if len(errors) > 0 {
+		return deps, multierror.Wrap(errors...)
+	}
+	return deps, nil
+}
+
+// newDependencyGraph returns a graph with the passed objects as vertices
+// and their dependencies as edges, along with any errors encountered
+// while reading the dependencies.
+// The objs and ids must match in order and length (optimization).
+func newDependencyGraph(objs object.UnstructuredSet, ids object.ObjMetadataSet) (*Graph, []error) {
+	var errors []error
+	g := New()
+	// Add objects as graph vertices
+	addVertices(g, ids)
+	// Add dependencies as graph edges
+	addCRDEdges(g, objs, ids)
+	addNamespaceEdges(g, objs, ids)
+	if err := addDependsOnEdges(g, objs, ids); err != nil {
+		errors = append(errors, err)
+	}
+	if err := addApplyTimeMutationEdges(g, objs, ids); err != nil {
+		errors = append(errors, err)
+	}
+	return g, errors
+}
+
 // ReverseSortObjs is the same as SortObjs but using reverse ordering.
 func ReverseSortObjs(objs object.UnstructuredSet) ([]object.UnstructuredSet, error) {
 	// Sorted objects using normal ordering.
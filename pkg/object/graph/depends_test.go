@@ -374,6 +374,91 @@ func TestReverseSortObjs(t *testing.T) {
 	}
 }
 
+func TestDependencies(t *testing.T) {
+	deploymentID := testutil.ToIdentifier(t, resources["deployment"])
+	secretID := testutil.ToIdentifier(t, resources["secret"])
+	namespaceID := testutil.ToIdentifier(t, resources["namespace"])
+	podID := testutil.ToIdentifier(t, resources["pod"])
+	defaultPodID := testutil.ToIdentifier(t, resources["default-pod"])
+
+	testCases := map[string]struct {
+		objs     []*unstructured.Unstructured
+		expected map[object.ObjMetadata]object.ObjMetadataSet
+		isError  bool
+	}{
+		"no objects returns no dependencies": {
+			objs:     []*unstructured.Unstructured{},
+			expected: map[object.ObjMetadata]object.ObjMetadataSet{},
+		},
+		"independent objects have no dependencies": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["default-pod"]),
+			},
+			expected: map[object.ObjMetadata]object.ObjMetadataSet{
+				defaultPodID: {},
+			},
+		},
+		"explicit and implicit dependencies": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddDependsOn(t, secretID)),
+				testutil.Unstructured(t, resources["secret"]),
+				testutil.Unstructured(t, resources["namespace"]),
+				testutil.Unstructured(t, resources["pod"]),
+			},
+			expected: map[object.ObjMetadata]object.ObjMetadataSet{
+				deploymentID: {namespaceID, secretID},
+				secretID:     {namespaceID},
+				namespaceID:  {},
+				podID:        {namespaceID},
+			},
+		},
+		"namespace outside the set is ignored": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["pod"]),
+			},
+			expected: map[object.ObjMetadata]object.ObjMetadataSet{
+				podID: {},
+			},
+		},
+		"external depends-on is an error": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddDependsOn(t, secretID)),
+			},
+			isError: true,
+		},
+		"cyclic dependencies are returned without error": {
+			objs: []*unstructured.Unstructured{
+				testutil.Unstructured(t, resources["deployment"],
+					testutil.AddDependsOn(t, secretID)),
+				testutil.Unstructured(t, resources["secret"],
+					testutil.AddDependsOn(t, deploymentID)),
+			},
+			expected: map[object.ObjMetadata]object.ObjMetadataSet{
+				deploymentID: {secretID},
+				secretID:     {deploymentID},
+			},
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			actual, err := Dependencies(tc.objs)
+			if tc.isError {
+				assert.NotNil(t, err, "expected error, but received none")
+				return
+			}
+			assert.Nil(t, err, "unexpected error received")
+			assert.Equal(t, len(tc.expected), len(actual))
+			for id, expectedDeps := range tc.expected {
+				assert.True(t, expectedDeps.Equal(actual[id]),
+					"expected dependencies of %s: %v, got: %v", id, expectedDeps, actual[id])
+			}
+		})
+	}
+}
+
 func TestApplyTimeMutationEdges(t *testing.T) {
 	testCases := map[string]struct {
 		objs          []*unstructured.Unstructured
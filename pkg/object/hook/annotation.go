@@ -0,0 +1,162 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+// Package hook provides reading of the hook annotations, which mark
+// objects (usually Jobs or Pods) to be run at a point of the apply or
+// destroy lifecycle, instead of being applied with the other objects.
+package hook
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/multierror"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+)
+
+const (
+	// Annotation marks an object as a hook, and specifies the Phase it
+	// runs in.
+	Annotation = "config.k8s.io/hook"
+	// DeletePolicyAnnotation specifies whether the hook object is deleted
+	// after it has run.
+	DeletePolicyAnnotation = "config.k8s.io/hook-delete-policy"
+)
+
+// Phase is the point of the lifecycle a hook runs in.
+type Phase string
+
+const (
+	// PreApply hooks run before any objects are applied.
+	PreApply Phase = "pre-apply"
+	// PostApply hooks run after all objects are applied and pruned.
+	PostApply Phase = "post-apply"
+	// PreDelete hooks run before any objects are destroyed.
+	PreDelete Phase = "pre-delete"
+	// PostDelete hooks run after all objects are destroyed.
+	PostDelete Phase = "post-delete"
+)
+
+// Phases are all the valid phases, in lifecycle order.
+var Phases = []Phase{PreApply, PostApply, PreDelete, PostDelete}
+
+// DeletePolicy specifies when a hook object is deleted.
+type DeletePolicy string
+
+const (
+	// DeleteNever keeps the hook object after it has run. This is the
+	// default. The object is still replaced when the hook runs again.
+	DeleteNever DeletePolicy = "never"
+	// DeleteOnSuccess deletes the hook object if it succeeded.
+	DeleteOnSuccess DeletePolicy = "succeeded"
+	// DeleteAlways deletes the hook object after it has run, whether it
+	// succeeded or not.
+	DeleteAlways DeletePolicy = "always"
+)
+
+// HasAnnotation returns true if the hook annotation is present, false
+// if not.
+func HasAnnotation(u *unstructured.Unstructured) bool {
+	if u == nil {
+		return false
+	}
+	_, found := u.GetAnnotations()[Annotation]
+	return found
+}
+
+// ReadAnnotation reads the hook annotation and returns the phase.
+// Returns an empty phase if the object is not a hook.
+func ReadAnnotation(u *unstructured.Unstructured) (Phase, error) {
+	if u == nil {
+		return "", nil
+	}
+	phaseStr, found := u.GetAnnotations()[Annotation]
+	if !found {
+		return "", nil
+	}
+	for _, phase := range Phases {
+		if phaseStr == string(phase) {
+			return phase, nil
+		}
+	}
+	return "", object.InvalidAnnotationError{
+		Annotation: Annotation,
+		Cause:      fmt.Errorf("unknown hook phase %q, must be one of %q", phaseStr, Phases),
+	}
+}
+
+// ReadDeletePolicy reads the hook delete policy annotation. Returns
+// DeleteNever if the annotation is not present.
+func ReadDeletePolicy(u *unstructured.Unstructured) (DeletePolicy, error) {
+	if u == nil {
+		return DeleteNever, nil
+	}
+	policyStr, found := u.GetAnnotations()[DeletePolicyAnnotation]
+	if !found {
+		return DeleteNever, nil
+	}
+	switch policy := DeletePolicy(policyStr); policy {
+	case DeleteNever, DeleteOnSuccess, DeleteAlways:
+		return policy, nil
+	default:
+		return DeleteNever, object.InvalidAnnotationError{
+			Annotation: DeletePolicyAnnotation,
+			Cause: fmt.Errorf("unknown hook delete policy %q, must be one of %q",
+				policyStr, []DeletePolicy{DeleteNever, DeleteOnSuccess, DeleteAlways}),
+		}
+	}
+}
+
+// SplitObjects separates the hooks from the other objects. Objects with
+// an invalid hook annotation are returned as hooks, so they are never
+// applied as regular objects.
+func SplitObjects(objs object.UnstructuredSet) (object.UnstructuredSet, object.UnstructuredSet) {
+	regular := object.UnstructuredSet{}
+	hooks := object.UnstructuredSet{}
+	for _, obj := range objs {
+		if HasAnnotation(obj) {
+			hooks = append(hooks, obj)
+		} else {
+			regular = append(regular, obj)
+		}
+	}
+	return regular, hooks
+}
+
+// FilterPhase returns the hooks that run in the passed phase. Hooks with
+// an invalid annotation are ignored.
+func FilterPhase(hooks object.UnstructuredSet, phase Phase) object.UnstructuredSet {
+	filtered := object.UnstructuredSet{}
+	for _, obj := range hooks {
+		if p, err := ReadAnnotation(obj); err == nil && p == phase {
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered
+}
+
+// Validate returns a validation error for each of the hooks with an
+// invalid hook or delete policy annotation.
+func Validate(hooks object.UnstructuredSet) error {
+	var errors []error
+	for _, obj := range hooks {
+		var objErrors []error
+		if _, err := ReadAnnotation(obj); err != nil {
+			objErrors = append(objErrors, err)
+		}
+		if _, err := ReadDeletePolicy(obj); err != nil {
+			objErrors = append(objErrors, err)
+		}
+		if len(objErrors) > 0 {
+			errors = append(errors, validation.NewError(
+				multierror.Wrap(objErrors...),
+				object.UnstructuredToObjMetadata(obj),
+			))
+		}
+	}
+	if len(errors) > 0 {
+		return multierror.Wrap(errors...)
+	}
+	return nil
+}
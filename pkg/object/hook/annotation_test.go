@@ -0,0 +1,154 @@
+// Copyright 2022 The Kubernetes Authors.
+// SPDX-License-Identifier: Apache-2.0
+
+package hook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/cli-utils/pkg/object"
+	"sigs.k8s.io/cli-utils/pkg/object/validation"
+)
+
+func job(name string, annotations map[string]string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "test-namespace",
+			},
+		},
+	}
+	u.SetAnnotations(annotations)
+	return u
+}
+
+func TestReadAnnotation(t *testing.T) {
+	testCases := map[string]struct {
+		obj           *unstructured.Unstructured
+		expectedPhase Phase
+		isError       bool
+	}{
+		"nil object": {
+			obj:           nil,
+			expectedPhase: "",
+		},
+		"no annotation": {
+			obj:           job("job", nil),
+			expectedPhase: "",
+		},
+		"pre-apply": {
+			obj:           job("job", map[string]string{Annotation: "pre-apply"}),
+			expectedPhase: PreApply,
+		},
+		"post-delete": {
+			obj:           job("job", map[string]string{Annotation: "post-delete"}),
+			expectedPhase: PostDelete,
+		},
+		"unknown phase": {
+			obj:     job("job", map[string]string{Annotation: "pre-prune"}),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			phase, err := ReadAnnotation(tc.obj)
+			if tc.isError {
+				assert.Error(t, err)
+				assert.IsType(t, object.InvalidAnnotationError{}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPhase, phase)
+		})
+	}
+}
+
+func TestReadDeletePolicy(t *testing.T) {
+	testCases := map[string]struct {
+		obj            *unstructured.Unstructured
+		expectedPolicy DeletePolicy
+		isError        bool
+	}{
+		"no annotation defaults to never": {
+			obj:            job("job", map[string]string{Annotation: "pre-apply"}),
+			expectedPolicy: DeleteNever,
+		},
+		"succeeded": {
+			obj: job("job", map[string]string{
+				Annotation:             "pre-apply",
+				DeletePolicyAnnotation: "succeeded",
+			}),
+			expectedPolicy: DeleteOnSuccess,
+		},
+		"always": {
+			obj: job("job", map[string]string{
+				Annotation:             "pre-apply",
+				DeletePolicyAnnotation: "always",
+			}),
+			expectedPolicy: DeleteAlways,
+		},
+		"unknown policy": {
+			obj: job("job", map[string]string{
+				Annotation:             "pre-apply",
+				DeletePolicyAnnotation: "sometimes",
+			}),
+			isError: true,
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			policy, err := ReadDeletePolicy(tc.obj)
+			if tc.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPolicy, policy)
+		})
+	}
+}
+
+func TestSplitObjects(t *testing.T) {
+	regular := job("regular", nil)
+	preApply := job("pre", map[string]string{Annotation: "pre-apply"})
+	postApply := job("post", map[string]string{Annotation: "post-apply"})
+	invalid := job("invalid", map[string]string{Annotation: "unknown"})
+
+	objs, hooks := SplitObjects(object.UnstructuredSet{regular, preApply, postApply, invalid})
+	assert.Equal(t, object.UnstructuredSet{regular}, objs)
+	assert.Equal(t, object.UnstructuredSet{preApply, postApply, invalid}, hooks)
+
+	assert.Equal(t, object.UnstructuredSet{preApply}, FilterPhase(hooks, PreApply))
+	assert.Equal(t, object.UnstructuredSet{postApply}, FilterPhase(hooks, PostApply))
+	assert.Equal(t, object.UnstructuredSet{}, FilterPhase(hooks, PreDelete))
+}
+
+func TestValidate(t *testing.T) {
+	valid := job("valid", map[string]string{
+		Annotation:             "pre-apply",
+		DeletePolicyAnnotation: "always",
+	})
+	invalidPhase := job("invalid-phase", map[string]string{Annotation: "unknown"})
+	invalidPolicy := job("invalid-policy", map[string]string{
+		Annotation:             "post-apply",
+		DeletePolicyAnnotation: "sometimes",
+	})
+
+	assert.NoError(t, Validate(object.UnstructuredSet{valid}))
+
+	err := Validate(object.UnstructuredSet{valid, invalidPhase, invalidPolicy})
+	assert.Error(t, err)
+	collector := &validation.Collector{}
+	collector.Collect(err)
+	assert.Equal(t, object.ObjMetadataSet{
+		object.UnstructuredToObjMetadata(invalidPhase),
+		object.UnstructuredToObjMetadata(invalidPolicy),
+	}, collector.InvalidIds)
+}
@@ -5,15 +5,16 @@ package common
 
 import (
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/cli-utils/pkg/print/stats"
 )
 
 // ResultErrorFromStats takes a stats object and returns either a ResultError or
 // nil depending on whether the stats reports that resources failed apply/prune/delete
-// or reconciliation.
+// or reconciliation, or drifted from their last applied state.
 func ResultErrorFromStats(s stats.Stats) error {
-	if s.FailedActuationSum() > 0 || s.FailedReconciliationSum() > 0 {
+	if s.FailedActuationSum() > 0 || s.FailedReconciliationSum() > 0 || s.DriftedSum() > 0 {
 		return &ResultError{
 			Stats: s,
 		}
@@ -22,24 +23,27 @@ func ResultErrorFromStats(s stats.Stats) error {
 }
 
 // ResultError is returned from printers when the apply/destroy operations completed, but one or
-// more resources either failed apply/prune/delete, or failed to reconcile.
+// more resources either failed apply/prune/delete, failed to reconcile, or drifted.
 type ResultError struct {
 	Stats stats.Stats
 }
 
 func (a *ResultError) Error() string {
-	switch {
-	case a.Stats.FailedActuationSum() > 0 && a.Stats.FailedReconciliationSum() > 0:
-		return fmt.Sprintf("%d resources failed, %d resources failed to reconcile before timeout",
-			a.Stats.FailedActuationSum(), a.Stats.FailedReconciliationSum())
-	case a.Stats.FailedActuationSum() > 0:
-		return fmt.Sprintf("%d resources failed", a.Stats.FailedActuationSum())
-	case a.Stats.FailedReconciliationSum() > 0:
-		return fmt.Sprintf("%d resources failed to reconcile before timeout",
-			a.Stats.FailedReconciliationSum())
-	default:
+	var parts []string
+	if a.Stats.FailedActuationSum() > 0 {
+		parts = append(parts, fmt.Sprintf("%d resources failed", a.Stats.FailedActuationSum()))
+	}
+	if a.Stats.FailedReconciliationSum() > 0 {
+		parts = append(parts, fmt.Sprintf("%d resources failed to reconcile before timeout",
+			a.Stats.FailedReconciliationSum()))
+	}
+	if a.Stats.DriftedSum() > 0 {
+		parts = append(parts, fmt.Sprintf("%d resources drifted", a.Stats.DriftedSum()))
+	}
+	if len(parts) == 0 {
 		// Should not happen as this error is only used when at least one resource
-		// either failed to apply/prune/delete or reconcile.
+		// either failed to apply/prune/delete, reconcile or drifted.
 		return "unknown error"
 	}
+	return strings.Join(parts, ", ")
 }
@@ -18,6 +18,10 @@ type Formatter interface {
 	FormatPruneEvent(pe event.PruneEvent) error
 	FormatDeleteEvent(de event.DeleteEvent) error
 	FormatWaitEvent(we event.WaitEvent) error
+	FormatRollbackEvent(re event.RollbackEvent) error
+	FormatHookEvent(he event.HookEvent) error
+	FormatDriftEvent(de event.DriftEvent) error
+	FormatRetryEvent(re event.RetryEvent) error
 	FormatErrorEvent(ee event.ErrorEvent) error
 	FormatActionGroupEvent(
 		age event.ActionGroupEvent,
@@ -96,6 +100,22 @@ func (b *BaseListPrinter) Print(ch <-chan event.Event, previewStrategy common.Dr
 			if err := formatter.FormatWaitEvent(e.WaitEvent); err != nil {
 				return err
 			}
+		case event.RollbackType:
+			if err := formatter.FormatRollbackEvent(e.RollbackEvent); err != nil {
+				return err
+			}
+		case event.HookType:
+			if err := formatter.FormatHookEvent(e.HookEvent); err != nil {
+				return err
+			}
+		case event.DriftType:
+			if err := formatter.FormatDriftEvent(e.DriftEvent); err != nil {
+				return err
+			}
+		case event.RetryType:
+			if err := formatter.FormatRetryEvent(e.RetryEvent); err != nil {
+				return err
+			}
 		case event.ActionGroupType:
 			if err := formatter.FormatActionGroupEvent(
 				e.ActionGroupEvent,
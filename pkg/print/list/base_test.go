@@ -34,6 +34,10 @@ type countingFormatter struct {
 	pruneEvents      []event.PruneEvent
 	deleteEvents     []event.DeleteEvent
 	waitEvents       []event.WaitEvent
+	rollbackEvents   []event.RollbackEvent
+	hookEvents       []event.HookEvent
+	driftEvents      []event.DriftEvent
+	retryEvents      []event.RetryEvent
 	errorEvent       event.ErrorEvent
 	actionGroupEvent []event.ActionGroupEvent
 }
@@ -68,6 +72,26 @@ func (c *countingFormatter) FormatWaitEvent(e event.WaitEvent) error {
 	return nil
 }
 
+func (c *countingFormatter) FormatRollbackEvent(e event.RollbackEvent) error {
+	c.rollbackEvents = append(c.rollbackEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatHookEvent(e event.HookEvent) error {
+	c.hookEvents = append(c.hookEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatDriftEvent(e event.DriftEvent) error {
+	c.driftEvents = append(c.driftEvents, e)
+	return nil
+}
+
+func (c *countingFormatter) FormatRetryEvent(e event.RetryEvent) error {
+	c.retryEvents = append(c.retryEvents, e)
+	return nil
+}
+
 func (c *countingFormatter) FormatErrorEvent(e event.ErrorEvent) error {
 	c.errorEvent = e
 	return nil
@@ -12,15 +12,19 @@ import (
 // Stats captures the summarized numbers from apply/prune/delete and
 // reconciliation of resources.
 type Stats struct {
-	ApplyStats  ApplyStats
-	PruneStats  PruneStats
-	DeleteStats DeleteStats
-	WaitStats   WaitStats
+	ApplyStats    ApplyStats
+	PruneStats    PruneStats
+	DeleteStats   DeleteStats
+	WaitStats     WaitStats
+	RollbackStats RollbackStats
+	HookStats     HookStats
+	DriftStats    DriftStats
 }
 
 // FailedActuationSum returns the number of resources that failed actuation.
 func (s *Stats) FailedActuationSum() int {
-	return s.ApplyStats.Failed + s.PruneStats.Failed + s.DeleteStats.Failed
+	return s.ApplyStats.Failed + s.PruneStats.Failed + s.DeleteStats.Failed + s.RollbackStats.Failed +
+		s.HookStats.Failed + s.DriftStats.Failed
 }
 
 // FailedReconciliationSum returns the number of resources that failed reconciliation.
@@ -28,6 +32,12 @@ func (s *Stats) FailedReconciliationSum() int {
 	return s.WaitStats.Failed + s.WaitStats.Timeout
 }
 
+// DriftedSum returns the number of resources that drifted from their
+// last applied state, or were deleted.
+func (s *Stats) DriftedSum() int {
+	return s.DriftStats.Drifted + s.DriftStats.Missing
+}
+
 // Handle updates the stats based on an event.
 func (s *Stats) Handle(e event.Event) {
 	switch e.Type {
@@ -51,6 +61,20 @@ func (s *Stats) Handle(e event.Event) {
 		s.DeleteStats.Inc(e.DeleteEvent.Operation)
 	case event.WaitType:
 		s.WaitStats.Inc(e.WaitEvent.Operation)
+	case event.RollbackType:
+		if e.RollbackEvent.Error != nil {
+			s.RollbackStats.IncFailed()
+			return
+		}
+		s.RollbackStats.Inc(e.RollbackEvent.Operation)
+	case event.HookType:
+		s.HookStats.Inc(e.HookEvent.Operation)
+	case event.DriftType:
+		if e.DriftEvent.Error != nil {
+			s.DriftStats.IncFailed()
+			return
+		}
+		s.DriftStats.Inc(e.DriftEvent.Operation)
 	}
 }
 
@@ -59,6 +83,9 @@ type ApplyStats struct {
 	Created           int
 	Unchanged         int
 	Configured        int
+	Replaced          int
+	Migrated          int
+	Skipped           int
 	Failed            int
 }
 
@@ -73,6 +100,12 @@ func (a *ApplyStats) Inc(op event.ApplyEventOperation) {
 		a.Unchanged++
 	case event.Configured:
 		a.Configured++
+	case event.Replaced:
+		a.Replaced++
+	case event.Migrated:
+		a.Migrated++
+	case event.ApplySkipped:
+		a.Skipped++
 	default:
 		panic(fmt.Errorf("unknown apply operation %s", op.String()))
 	}
@@ -83,7 +116,7 @@ func (a *ApplyStats) IncFailed() {
 }
 
 func (a *ApplyStats) Sum() int {
-	return a.ServersideApplied + a.Configured + a.Unchanged + a.Created + a.Failed
+	return a.ServersideApplied + a.Configured + a.Unchanged + a.Created + a.Replaced + a.Migrated + a.Skipped + a.Failed
 }
 
 type PruneStats struct {
@@ -145,3 +178,82 @@ func (w *WaitStats) Inc(op event.WaitEventOperation) {
 		w.Failed++
 	}
 }
+
+type RollbackStats struct {
+	Restored  int
+	Recreated int
+	Removed   int
+	Failed    int
+}
+
+func (r *RollbackStats) Inc(op event.RollbackEventOperation) {
+	switch op {
+	case event.RollbackUnspecified:
+	case event.RollbackRestored:
+		r.Restored++
+	case event.RollbackRecreated:
+		r.Recreated++
+	case event.RollbackRemoved:
+		r.Removed++
+	}
+}
+
+func (r *RollbackStats) IncFailed() {
+	r.Failed++
+}
+
+func (r *RollbackStats) Sum() int {
+	return r.Restored + r.Recreated + r.Removed + r.Failed
+}
+
+type HookStats struct {
+	Succeeded int
+	Failed    int
+	Deleted   int
+}
+
+func (h *HookStats) Inc(op event.HookEventOperation) {
+	switch op {
+	case event.HookUnspecified, event.HookStarted:
+	case event.HookSucceeded:
+		h.Succeeded++
+	case event.HookFailed:
+		h.Failed++
+	case event.HookDeleted:
+		h.Deleted++
+	}
+}
+
+func (h *HookStats) Sum() int {
+	return h.Succeeded + h.Failed
+}
+
+type DriftStats struct {
+	InSync  int
+	Drifted int
+	Missing int
+	Unknown int
+	Failed  int
+}
+
+func (d *DriftStats) Inc(op event.DriftEventOperation) {
+	switch op {
+	case event.DriftUnspecified:
+	case event.DriftInSync:
+		d.InSync++
+	case event.DriftDetected:
+		d.Drifted++
+	case event.DriftMissing:
+		d.Missing++
+	case event.DriftUnknown:
+		d.Unknown++
+	}
+}
+
+func (d *DriftStats) IncFailed() {
+	d.Failed++
+}
+
+func (d *DriftStats) Sum() int {
+	return d.InSync + d.Drifted + d.Missing + d.Unknown + d.Failed
+}
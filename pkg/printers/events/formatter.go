@@ -61,9 +61,14 @@ func (ef *formatter) FormatValidationEvent(ve event.ValidationEvent) error {
 func (ef *formatter) FormatApplyEvent(ae event.ApplyEvent) error {
 	gk := ae.Identifier.GroupKind
 	name := ae.Identifier.Name
-	if ae.Error != nil {
+	if len(ae.Conflicts) > 0 {
+		ef.print("%s apply failed: conflicts with other field managers: %s",
+			resourceIDToString(gk, name), conflictsToString(ae.Conflicts))
+	} else if ae.Error != nil {
 		ef.print("%s apply failed: %s", resourceIDToString(gk, name),
 			ae.Error.Error())
+	} else if ae.Operation == event.ApplySkipped {
+		ef.print("%s apply skipped%s", resourceIDToString(gk, name), reasonSuffix(ae.Reason))
 	} else {
 		ef.print("%s %s", resourceIDToString(gk, name),
 			strings.ToLower(ae.Operation.String()))
@@ -89,7 +94,7 @@ func (ef *formatter) FormatPruneEvent(pe event.PruneEvent) error {
 	case event.Pruned:
 		ef.print("%s pruned", resourceIDToString(gk, pe.Identifier.Name))
 	case event.PruneSkipped:
-		ef.print("%s prune skipped", resourceIDToString(gk, pe.Identifier.Name))
+		ef.print("%s prune skipped%s", resourceIDToString(gk, pe.Identifier.Name), reasonSuffix(pe.Reason))
 	}
 	return nil
 }
@@ -108,7 +113,7 @@ func (ef *formatter) FormatDeleteEvent(de event.DeleteEvent) error {
 	case event.Deleted:
 		ef.print("%s deleted", resourceIDToString(gk, name))
 	case event.DeleteSkipped:
-		ef.print("%s delete skipped", resourceIDToString(gk, name))
+		ef.print("%s delete skipped%s", resourceIDToString(gk, name), reasonSuffix(de.Reason))
 	}
 	return nil
 }
@@ -119,19 +124,97 @@ func (ef *formatter) FormatWaitEvent(we event.WaitEvent) error {
 
 	switch we.Operation {
 	case event.ReconcilePending:
-		ef.print("%s reconcile pending", resourceIDToString(gk, name))
+		ef.print("%s reconcile pending%s", resourceIDToString(gk, name), reasonSuffix(we.Reason))
 	case event.Reconciled:
 		ef.print("%s reconciled", resourceIDToString(gk, name))
 	case event.ReconcileSkipped:
 		ef.print("%s reconcile skipped", resourceIDToString(gk, name))
 	case event.ReconcileTimeout:
-		ef.print("%s reconcile timeout", resourceIDToString(gk, name))
+		ef.print("%s reconcile timeout%s", resourceIDToString(gk, name), reasonSuffix(we.Reason))
 	case event.ReconcileFailed:
 		ef.print("%s reconcile failed", resourceIDToString(gk, name))
 	}
 	return nil
 }
 
+func (ef *formatter) FormatRollbackEvent(re event.RollbackEvent) error {
+	gk := re.Identifier.GroupKind
+	name := re.Identifier.Name
+
+	if re.Error != nil {
+		ef.print("%s rollback failed: %s", resourceIDToString(gk, name),
+			re.Error.Error())
+		return nil
+	}
+
+	switch re.Operation {
+	case event.RollbackRestored:
+		ef.print("%s rolled back", resourceIDToString(gk, name))
+	case event.RollbackRecreated:
+		ef.print("%s recreated", resourceIDToString(gk, name))
+	case event.RollbackRemoved:
+		ef.print("%s removed", resourceIDToString(gk, name))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatHookEvent(he event.HookEvent) error {
+	gk := he.Identifier.GroupKind
+	name := he.Identifier.Name
+
+	switch he.Operation {
+	case event.HookStarted:
+		ef.print("%s hook started", resourceIDToString(gk, name))
+	case event.HookSucceeded:
+		ef.print("%s hook succeeded", resourceIDToString(gk, name))
+	case event.HookFailed:
+		ef.print("%s hook failed: %s", resourceIDToString(gk, name),
+			he.Error.Error())
+	case event.HookDeleted:
+		if he.Error != nil {
+			ef.print("%s hook deletion failed: %s", resourceIDToString(gk, name),
+				he.Error.Error())
+			return nil
+		}
+		ef.print("%s hook deleted", resourceIDToString(gk, name))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatDriftEvent(de event.DriftEvent) error {
+	gk := de.Identifier.GroupKind
+	name := de.Identifier.Name
+
+	if de.Error != nil {
+		ef.print("%s drift check failed: %s", resourceIDToString(gk, name),
+			de.Error.Error())
+		return nil
+	}
+
+	switch de.Operation {
+	case event.DriftInSync:
+		ef.print("%s in sync", resourceIDToString(gk, name))
+	case event.DriftDetected:
+		ef.print("%s drifted: %s", resourceIDToString(gk, name),
+			strings.Join(de.Fields, ", "))
+	case event.DriftMissing:
+		ef.print("%s missing", resourceIDToString(gk, name))
+	case event.DriftUnknown:
+		ef.print("%s unknown: no last applied state", resourceIDToString(gk, name))
+	}
+	return nil
+}
+
+func (ef *formatter) FormatRetryEvent(re event.RetryEvent) error {
+	resource := resourceIDToString(re.Identifier.GroupKind, re.Identifier.Name)
+	if re.Action == event.InventoryAction {
+		resource = fmt.Sprintf("inventory %s", re.Identifier.Name)
+	}
+	ef.print("%s %s failed, retrying in %s (attempt %d/%d): %s", resource,
+		strings.ToLower(re.Action.String()), re.Delay, re.Attempt, re.MaxAttempts, re.Error.Error())
+	return nil
+}
+
 func (ef *formatter) FormatErrorEvent(_ event.ErrorEvent) error {
 	return nil
 }
@@ -153,6 +236,21 @@ func (ef *formatter) FormatActionGroupEvent(
 		if as.ServersideApplied > 0 {
 			output += fmt.Sprintf(", %d serverside applied", as.ServersideApplied)
 		}
+		// Only print information about replaced resources if some of the
+		// resources actually were replaced.
+		if as.Replaced > 0 {
+			output += fmt.Sprintf(", %d replaced", as.Replaced)
+		}
+		// Only print information about migrated resources if some of the
+		// resources actually were migrated.
+		if as.Migrated > 0 {
+			output += fmt.Sprintf(", %d migrated", as.Migrated)
+		}
+		// Only print information about skipped resources if some of the
+		// resources actually were skipped.
+		if as.Skipped > 0 {
+			output += fmt.Sprintf(", %d skipped", as.Skipped)
+		}
 		ef.print(output)
 	}
 
@@ -177,6 +275,32 @@ func (ef *formatter) FormatActionGroupEvent(
 		ef.print("%d resource(s) reconciled, %d skipped, %d failed to reconcile, %d timed out", ws.Reconciled,
 			ws.Skipped, ws.Failed, ws.Timeout)
 	}
+
+	if age.Action == event.RollbackAction &&
+		age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		rs := s.RollbackStats
+		if rs.Sum() > 0 {
+			ef.print("%d resource(s) rolled back. %d restored, %d recreated, %d removed, %d failed", rs.Sum(),
+				rs.Restored, rs.Recreated, rs.Removed, rs.Failed)
+		}
+	}
+
+	if age.Action == event.HookAction &&
+		age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		hs := s.HookStats
+		ef.print("%d hook(s) run, %d succeeded, %d failed", hs.Sum(),
+			hs.Succeeded, hs.Failed)
+	}
+
+	if age.Action == event.DriftAction &&
+		age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		ds := s.DriftStats
+		ef.print("%d resource(s) checked, %d in sync, %d drifted, %d missing, %d unknown, %d failed",
+			ds.Sum(), ds.InSync, ds.Drifted, ds.Missing, ds.Unknown, ds.Failed)
+	}
 	return nil
 }
 
@@ -193,3 +317,25 @@ func (ef *formatter) print(format string, a ...interface{}) {
 func resourceIDToString(gk schema.GroupKind, name string) string {
 	return fmt.Sprintf("%s/%s", strings.ToLower(gk.String()), name)
 }
+
+// conflictsToString returns the fields of the server-side apply conflicts
+// with the field managers that own them, separated by commas.
+func conflictsToString(conflicts []event.ApplyConflict) string {
+	var sb strings.Builder
+	for i, conflict := range conflicts {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		_, _ = fmt.Fprintf(&sb, "%s (%s)", conflict.Field, conflict.Manager)
+	}
+	return sb.String()
+}
+
+// reasonSuffix returns the reason an operation was skipped, formatted to
+// be appended to the event, or an empty string if there is no reason.
+func reasonSuffix(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return ": " + reason
+}
@@ -63,6 +63,28 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 			},
 			expected: "deployment.apps/my-dep apply failed: this is a test error",
 		},
+		"apply event with conflicts should display the conflicts": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error:      fmt.Errorf("Apply failed with 2 conflicts"),
+				Conflicts: []event.ApplyConflict{
+					{Field: ".spec.replicas", Manager: "controller"},
+					{Field: ".metadata.labels.app", Manager: "other"},
+				},
+			},
+			expected: "deployment.apps/my-dep apply failed: conflicts with other field managers: " +
+				".spec.replicas (controller), .metadata.labels.app (other)",
+		},
+		"resource skipped after cancellation": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Operation:  event.ApplySkipped,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Reason:     "cancelled",
+			},
+			expected: "deployment.apps/my-dep apply skipped: cancelled",
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -259,6 +281,26 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 			},
 			expected: "deployment.apps/my-dep reconcile timeout",
 		},
+		"resource reconcile timeout with reason": {
+			previewStrategy: common.DryRunNone,
+			event: event.WaitEvent{
+				GroupName:  "wait-1",
+				Identifier: createIdentifier("", "Namespace", "", "my-ns"),
+				Operation:  event.ReconcileTimeout,
+				Reason:     "deletion blocked by finalizers: kubernetes",
+			},
+			expected: "namespace/my-ns reconcile timeout: deletion blocked by finalizers: kubernetes",
+		},
+		"resource reconcile pending with reason": {
+			previewStrategy: common.DryRunNone,
+			event: event.WaitEvent{
+				GroupName:  "wait-1",
+				Identifier: createIdentifier("", "Namespace", "", "my-ns"),
+				Operation:  event.ReconcilePending,
+				Reason:     "removed finalizers: kubernetes",
+			},
+			expected: "namespace/my-ns reconcile pending: removed finalizers: kubernetes",
+		},
 		"resource reconcile timeout (client-side dry-run)": {
 			previewStrategy: common.DryRunClient,
 			event: event.WaitEvent{
@@ -19,18 +19,27 @@
 // pertains to a particular resource, the fields group, kind, name and namespace
 // will always be present.
 //
-// Events of type apply can have two different values for eventType, each which comes
+// Events of type apply can have three different values for eventType, each which comes
 // with a specific set of fields:
 //  * resourceApplied: A resource has been applied to the cluster.
 //    * fields identifying the resource.
 //    * operation: The operation that was performed on the resource. Must be one of
-//      created, configured, unchanged and serversideApplied.
+//      created, configured, unchanged, serversideApplied, migrated and skipped.
+//    * reason: Why the resource was skipped, if it was, like the apply being
+//      cancelled.
+//  * resourceFailed: A resource could not be applied.
+//    * fields identifying the resource.
+//    * error: The error message.
+//    * conflicts: The fields owned by other field managers, if server-side
+//      apply failed because of conflicts. Each has a field and a manager.
 //  * completed: All resources have been applied.
 //    * count: Total number of resources applied
 //    * createdCount: Number of resources created.
 //    * configuredCount: Number of resources configured.
 //    * unchangedCount: Number of resources unchanged.
 //    * serversideAppliedCount: Number of resources applied serverside.
+//    * migratedCount: Number of resources migrated to serverside apply.
+//    * skippedCount: Number of resources skipped.
 //
 // Events of type status is a notification when either the status of resource
 // has changed, or when a set of resources has reached their desired status. Events
@@ -51,6 +60,7 @@
 //    * fields identifying the resource.
 //    * operation: The operation that was performed on the resource. Must be one
 //      of pruned or skipped.
+//    * reason: Why the resource was skipped, if it was.
 //  * completed: All resources have been pruned or skipped.
 //    * count: Total number of resources pruned or skipped.
 //    * prunedCount: Number of resources pruned.
@@ -63,11 +73,21 @@
 //    * fields identifying the resource.
 //    * operation: The operation that was performed on the resource. Must be one
 //      of deleted or skipped.
+//    * reason: Why the resource was skipped, if it was.
 //  * completed: All resources have been deleted or skipped.
 //    * count: Total number of resources deleted or skipped.
 //    * deletedCount: Number of resources deleted.
 //    * skippedCount: Number of resources skipped.
 //
+// Events of type wait report the progress of waiting for resources to reach
+// their desired status after they have been applied or deleted:
+//  * resourceReconciled: The reconcile status of a resource has changed.
+//    * fields identifying the resource.
+//    * operation: The reconcile status of the resource. Must be one of
+//      Pending, Reconciled, Skipped, Timeout or Failed.
+//    * reason: Why the resource is still pending or timed out, if known,
+//      like the finalizers that block the deletion of the resource.
+//
 // Events of type error means there is an unrecoverable error and further
 // processing will stop. Only a single value for eventType is possible:
 //  * error: A fatal error has happened.
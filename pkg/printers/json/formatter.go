@@ -52,9 +52,22 @@ func (jf *formatter) FormatApplyEvent(ae event.ApplyEvent) error {
 	eventInfo := jf.baseResourceEvent(ae.Identifier)
 	if ae.Error != nil {
 		eventInfo["error"] = ae.Error.Error()
+		if len(ae.Conflicts) > 0 {
+			conflicts := make([]interface{}, len(ae.Conflicts))
+			for i, conflict := range ae.Conflicts {
+				conflicts[i] = map[string]interface{}{
+					"field":   conflict.Field,
+					"manager": conflict.Manager,
+				}
+			}
+			eventInfo["conflicts"] = conflicts
+		}
 		return jf.printEvent("apply", "resourceFailed", eventInfo)
 	}
 	eventInfo["operation"] = ae.Operation.String()
+	if ae.Reason != "" {
+		eventInfo["reason"] = ae.Reason
+	}
 	return jf.printEvent("apply", "resourceApplied", eventInfo)
 }
 
@@ -76,6 +89,9 @@ func (jf *formatter) FormatPruneEvent(pe event.PruneEvent) error {
 		return jf.printEvent("prune", "resourceFailed", eventInfo)
 	}
 	eventInfo["operation"] = pe.Operation.String()
+	if pe.Reason != "" {
+		eventInfo["reason"] = pe.Reason
+	}
 	return jf.printEvent("prune", "resourcePruned", eventInfo)
 }
 
@@ -86,15 +102,62 @@ func (jf *formatter) FormatDeleteEvent(de event.DeleteEvent) error {
 		return jf.printEvent("delete", "resourceFailed", eventInfo)
 	}
 	eventInfo["operation"] = de.Operation.String()
+	if de.Reason != "" {
+		eventInfo["reason"] = de.Reason
+	}
 	return jf.printEvent("delete", "resourceDeleted", eventInfo)
 }
 
 func (jf *formatter) FormatWaitEvent(we event.WaitEvent) error {
 	eventInfo := jf.baseResourceEvent(we.Identifier)
 	eventInfo["operation"] = we.Operation.String()
+	if we.Reason != "" {
+		eventInfo["reason"] = we.Reason
+	}
 	return jf.printEvent("wait", "resourceReconciled", eventInfo)
 }
 
+func (jf *formatter) FormatRollbackEvent(re event.RollbackEvent) error {
+	eventInfo := jf.baseResourceEvent(re.Identifier)
+	if re.Error != nil {
+		eventInfo["error"] = re.Error.Error()
+		return jf.printEvent("rollback", "resourceFailed", eventInfo)
+	}
+	eventInfo["operation"] = re.Operation.String()
+	return jf.printEvent("rollback", "resourceRolledBack", eventInfo)
+}
+
+func (jf *formatter) FormatHookEvent(he event.HookEvent) error {
+	eventInfo := jf.baseResourceEvent(he.Identifier)
+	eventInfo["operation"] = he.Operation.String()
+	if he.Error != nil {
+		eventInfo["error"] = he.Error.Error()
+	}
+	return jf.printEvent("hook", "hookRun", eventInfo)
+}
+
+func (jf *formatter) FormatDriftEvent(de event.DriftEvent) error {
+	eventInfo := jf.baseResourceEvent(de.Identifier)
+	if de.Error != nil {
+		eventInfo["error"] = de.Error.Error()
+		return jf.printEvent("drift", "resourceFailed", eventInfo)
+	}
+	eventInfo["operation"] = de.Operation.String()
+	eventInfo["source"] = de.Source
+	eventInfo["fields"] = de.Fields
+	return jf.printEvent("drift", "resourceChecked", eventInfo)
+}
+
+func (jf *formatter) FormatRetryEvent(re event.RetryEvent) error {
+	eventInfo := jf.baseResourceEvent(re.Identifier)
+	eventInfo["action"] = re.Action.String()
+	eventInfo["attempt"] = re.Attempt
+	eventInfo["maxAttempts"] = re.MaxAttempts
+	eventInfo["delay"] = re.Delay.String()
+	eventInfo["error"] = re.Error.Error()
+	return jf.printEvent("retry", "retrying", eventInfo)
+}
+
 func (jf *formatter) FormatErrorEvent(ee event.ErrorEvent) error {
 	return jf.printEvent("error", "error", map[string]interface{}{
 		"error": ee.Err.Error(),
@@ -116,6 +179,9 @@ func (jf *formatter) FormatActionGroupEvent(
 			"unchangedCount":  as.Unchanged,
 			"configuredCount": as.Configured,
 			"serverSideCount": as.ServersideApplied,
+			"replacedCount":   as.Replaced,
+			"migratedCount":   as.Migrated,
+			"skippedCount":    as.Skipped,
 			"failedCount":     as.Failed,
 		}); err != nil {
 			return err
@@ -153,6 +219,43 @@ func (jf *formatter) FormatActionGroupEvent(
 		})
 	}
 
+	if age.Action == event.RollbackAction && age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		rs := s.RollbackStats
+		if rs.Sum() == 0 {
+			return nil
+		}
+		return jf.printEvent("rollback", "completed", map[string]interface{}{
+			"restored":  rs.Restored,
+			"recreated": rs.Recreated,
+			"removed":   rs.Removed,
+			"failed":    rs.Failed,
+		})
+	}
+
+	if age.Action == event.HookAction && age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		hs := s.HookStats
+		return jf.printEvent("hook", "completed", map[string]interface{}{
+			"count":     hs.Sum(),
+			"succeeded": hs.Succeeded,
+			"failed":    hs.Failed,
+		})
+	}
+
+	if age.Action == event.DriftAction && age.Type == event.Finished &&
+		list.IsLastActionGroup(age, ags) {
+		ds := s.DriftStats
+		return jf.printEvent("drift", "completed", map[string]interface{}{
+			"count":   ds.Sum(),
+			"inSync":  ds.InSync,
+			"drifted": ds.Drifted,
+			"missing": ds.Missing,
+			"unknown": ds.Unknown,
+			"failed":  ds.Failed,
+		})
+	}
+
 	return nil
 }
 
@@ -68,6 +68,27 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 				},
 			},
 		},
+		"resource skipped after cancellation": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Operation:  event.ApplySkipped,
+				Identifier: createIdentifier("apps", "Deployment", "default", "my-dep"),
+				Reason:     "cancelled",
+			},
+			expected: []map[string]interface{}{
+				{
+					"eventType": "resourceApplied",
+					"group":     "apps",
+					"kind":      "Deployment",
+					"name":      "my-dep",
+					"namespace": "default",
+					"operation": "ApplySkipped",
+					"reason":    "cancelled",
+					"timestamp": "",
+					"type":      "apply",
+				},
+			},
+		},
 		"resource updated with server dryrun": {
 			previewStrategy: common.DryRunServer,
 			event: event.ApplyEvent{
@@ -106,6 +127,34 @@ func TestFormatter_FormatApplyEvent(t *testing.T) {
 				},
 			},
 		},
+		"resource apply conflicts": {
+			previewStrategy: common.DryRunNone,
+			event: event.ApplyEvent{
+				Identifier: createIdentifier("apps", "Deployment", "", "my-dep"),
+				Error:      errors.New("Apply failed with 1 conflict"),
+				Conflicts: []event.ApplyConflict{
+					{Field: ".spec.replicas", Manager: "controller"},
+				},
+			},
+			expected: []map[string]interface{}{
+				{
+					"eventType": "resourceFailed",
+					"group":     "apps",
+					"kind":      "Deployment",
+					"name":      "my-dep",
+					"namespace": "",
+					"timestamp": "",
+					"type":      "apply",
+					"error":     "Apply failed with 1 conflict",
+					"conflicts": []interface{}{
+						map[string]interface{}{
+							"field":   ".spec.replicas",
+							"manager": "controller",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -459,6 +508,26 @@ func TestFormatter_FormatWaitEvent(t *testing.T) {
 				"type":      "wait",
 			},
 		},
+		"resource reconcile timeout with reason": {
+			previewStrategy: common.DryRunNone,
+			event: event.WaitEvent{
+				GroupName:  "wait-1",
+				Operation:  event.ReconcileTimeout,
+				Identifier: createIdentifier("", "Namespace", "", "my-ns"),
+				Reason:     "deletion blocked by finalizers: kubernetes",
+			},
+			expected: map[string]interface{}{
+				"eventType": "resourceReconciled",
+				"group":     "",
+				"kind":      "Namespace",
+				"name":      "my-ns",
+				"namespace": "",
+				"operation": "Timeout",
+				"reason":    "deletion blocked by finalizers: kubernetes",
+				"timestamp": "",
+				"type":      "wait",
+			},
+		},
 	}
 
 	for tn, tc := range testCases {
@@ -529,7 +598,10 @@ func TestFormatter_FormatActionGroupEvent(t *testing.T) {
 				"count":           42,
 				"createdCount":    0,
 				"failedCount":     0,
+				"migratedCount":   0,
+				"replacedCount":   0,
 				"serverSideCount": 42,
+				"skippedCount":    0,
 				"timestamp":       "2022-01-06T05:22:48Z",
 				"type":            "apply",
 				"unchangedCount":  0,
@@ -25,8 +25,10 @@ func newResourceStateCollector(resourceGroups []event.ActionGroup) *resourceStat
 	for _, group := range resourceGroups {
 		action := group.Action
 		// Keep the action that describes the operation for the resource
-		// rather than that we will wait for it.
-		if action == event.WaitAction {
+		// rather than that we will wait for it or roll it back. Hooks are
+		// run, not applied, so they are not listed.
+		if action == event.WaitAction || action == event.RollbackAction ||
+			action == event.HookAction {
 			continue
 		}
 		for _, identifier := range group.Identifiers {
@@ -86,6 +88,11 @@ type resourceInfo struct {
 	// a resource has been applied to the cluster.
 	ApplyOpResult event.ApplyEventOperation
 
+	// ApplyConflicts contains the fields owned by other
+	// field managers, if server-side apply failed because
+	// of conflicts.
+	ApplyConflicts []event.ApplyConflict
+
 	// PruneOpResult contains the result after
 	// a prune operation on a resource
 	PruneOpResult event.PruneEventOperation
@@ -97,6 +104,10 @@ type resourceInfo struct {
 	// WaitOpResult contains the result after
 	// a wait operation on a resource
 	WaitOpResult event.WaitEventOperation
+
+	// DriftOpResult contains the result after
+	// a drift check on a resource
+	DriftOpResult event.DriftEventOperation
 }
 
 // Identifier returns the identifier for the given resource.
@@ -195,6 +206,8 @@ func (r *resourceStateCollector) processEvent(ev event.Event) error {
 		r.processPruneEvent(ev.PruneEvent)
 	case event.WaitType:
 		r.processWaitEvent(ev.WaitEvent)
+	case event.DriftType:
+		r.processDriftEvent(ev.DriftEvent)
 	case event.ErrorType:
 		return ev.ErrorEvent.Err
 	}
@@ -254,6 +267,7 @@ func (r *resourceStateCollector) processApplyEvent(e event.ApplyEvent) {
 		previous.Error = e.Error
 	}
 	previous.ApplyOpResult = e.Operation
+	previous.ApplyConflicts = e.Conflicts
 }
 
 // processPruneEvent handles event related to prune operations.
@@ -283,6 +297,21 @@ func (r *resourceStateCollector) processWaitEvent(e event.WaitEvent) {
 	previous.WaitOpResult = e.Operation
 }
 
+// processDriftEvent handles events related to drift checks.
+func (r *resourceStateCollector) processDriftEvent(e event.DriftEvent) {
+	identifier := e.Identifier
+	klog.V(7).Infof("processing drift event for %s", identifier)
+	previous, found := r.resourceInfos[identifier]
+	if !found {
+		klog.V(4).Infof("%s drift event not found in ResourceInfos; no processing", identifier)
+		return
+	}
+	if e.Error != nil {
+		previous.Error = e.Error
+	}
+	previous.DriftOpResult = e.Operation
+}
+
 // ResourceState contains the latest state for all the resources.
 type ResourceState struct {
 	resourceInfos ResourceInfos
@@ -317,9 +346,11 @@ func (r *resourceStateCollector) LatestState() *ResourceState {
 			resourceStatus: ri.resourceStatus,
 			ResourceAction: ri.ResourceAction,
 			ApplyOpResult:  ri.ApplyOpResult,
+			ApplyConflicts: ri.ApplyConflicts,
 			PruneOpResult:  ri.PruneOpResult,
 			DeleteOpResult: ri.DeleteOpResult,
 			WaitOpResult:   ri.WaitOpResult,
+			DriftOpResult:  ri.DriftOpResult,
 		})
 	}
 	sort.Sort(resourceInfos)
@@ -351,6 +382,11 @@ func (r *resourceStateCollector) Stats() stats.Stats {
 				s.DeleteStats.IncFailed()
 			}
 			s.DeleteStats.Inc(res.DeleteOpResult)
+		case event.DriftAction:
+			if res.Error != nil {
+				s.DriftStats.IncFailed()
+			}
+			s.DriftStats.Inc(res.DriftOpResult)
 		}
 		s.WaitStats.Inc(res.WaitOpResult)
 	}
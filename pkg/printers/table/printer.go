@@ -6,6 +6,7 @@ package table
 import (
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
@@ -103,6 +104,10 @@ var (
 				if resInfo.PruneOpResult != event.PruneUnspecified {
 					text = resInfo.PruneOpResult.String()
 				}
+			case event.DriftAction:
+				if resInfo.DriftOpResult != event.DriftUnspecified {
+					text = resInfo.DriftOpResult.String()
+				}
 			}
 
 			if len(text) > width {
@@ -144,6 +149,34 @@ var (
 		},
 	}
 
+	messageColumnDef = table.ColumnDef{
+		// Column containing the server-side apply conflicts, if any, and
+		// otherwise the status message.
+		ColumnName:   "message",
+		ColumnHeader: "MESSAGE",
+		ColumnWidth:  40,
+		PrintResourceFunc: func(w io.Writer, width int, r table.Resource) (
+			int,
+			error,
+		) {
+			resInfo, ok := r.(*resourceInfo)
+			if !ok || len(resInfo.ApplyConflicts) == 0 {
+				return table.MustColumn("message").PrintResource(w, width, r)
+			}
+
+			fields := make([]string, len(resInfo.ApplyConflicts))
+			for i, conflict := range resInfo.ApplyConflicts {
+				fields[i] = fmt.Sprintf("%s (%s)", conflict.Field, conflict.Manager)
+			}
+			text := "Conflicts: " + strings.Join(fields, ", ")
+			if len(text) > width {
+				text = text[:width]
+			}
+			_, err := fmt.Fprint(w, text)
+			return len(text), err
+		},
+	}
+
 	columns = []table.ColumnDefinition{
 		table.MustColumn("namespace"),
 		table.MustColumn("resource"),
@@ -152,7 +185,7 @@ var (
 		reconciledColumnDef,
 		table.MustColumn("conditions"),
 		table.MustColumn("age"),
-		table.MustColumn("message"),
+		messageColumnDef,
 	}
 )
 
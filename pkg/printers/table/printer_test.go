@@ -9,6 +9,7 @@ import (
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"sigs.k8s.io/cli-utils/pkg/apply/event"
+	pe "sigs.k8s.io/cli-utils/pkg/kstatus/polling/event"
 	"sigs.k8s.io/cli-utils/pkg/print/table"
 	"sigs.k8s.io/cli-utils/pkg/printers/printer"
 	printertesting "sigs.k8s.io/cli-utils/pkg/printers/testutil"
@@ -76,6 +77,60 @@ func TestActionColumnDef(t *testing.T) {
 	}
 }
 
+func TestMessageColumnDef(t *testing.T) {
+	testCases := map[string]struct {
+		resource       table.Resource
+		columnWidth    int
+		expectedOutput string
+	}{
+		"status message": {
+			resource: &resourceInfo{
+				resourceStatus: &pe.ResourceStatus{
+					Message: "Resource is current",
+				},
+			},
+			columnWidth:    40,
+			expectedOutput: "Resource is current",
+		},
+		"apply conflicts": {
+			resource: &resourceInfo{
+				resourceStatus: &pe.ResourceStatus{
+					Message: "Resource is current",
+				},
+				ApplyConflicts: []event.ApplyConflict{
+					{Field: ".spec.replicas", Manager: "controller"},
+					{Field: ".spec.paused", Manager: "other"},
+				},
+			},
+			columnWidth:    60,
+			expectedOutput: "Conflicts: .spec.replicas (controller), .spec.paused (other)",
+		},
+		"trimmed output": {
+			resource: &resourceInfo{
+				ApplyConflicts: []event.ApplyConflict{
+					{Field: ".spec.replicas", Manager: "controller"},
+				},
+			},
+			columnWidth:    15,
+			expectedOutput: "Conflicts: .spe",
+		},
+	}
+
+	for tn, tc := range testCases {
+		t.Run(tn, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := messageColumnDef.PrintResource(&buf, tc.columnWidth, tc.resource)
+			if err != nil {
+				t.Error(err)
+			}
+
+			if want, got := tc.expectedOutput, buf.String(); want != got {
+				t.Errorf("expected %q, but got %q", want, got)
+			}
+		})
+	}
+}
+
 func TestPrint(t *testing.T) {
 	printertesting.PrintResultErrorTest(t, func() printer.Printer {
 		ioStreams, _, _, _ := genericclioptions.NewTestIOStreams()
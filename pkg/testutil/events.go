@@ -25,6 +25,10 @@ type ExpEvent struct {
 	DeleteEvent      *ExpDeleteEvent
 	WaitEvent        *ExpWaitEvent
 	ValidationEvent  *ExpValidationEvent
+	RollbackEvent    *ExpRollbackEvent
+	HookEvent        *ExpHookEvent
+	DriftEvent       *ExpDriftEvent
+	RetryEvent       *ExpRetryEvent
 }
 
 type ExpInitEvent struct {
@@ -80,6 +84,35 @@ type ExpValidationEvent struct {
 	Error       error
 }
 
+type ExpRollbackEvent struct {
+	GroupName  string
+	Operation  event.RollbackEventOperation
+	Identifier object.ObjMetadata
+	Error      error
+}
+
+type ExpHookEvent struct {
+	GroupName  string
+	Operation  event.HookEventOperation
+	Identifier object.ObjMetadata
+	Error      error
+}
+
+type ExpDriftEvent struct {
+	GroupName  string
+	Operation  event.DriftEventOperation
+	Identifier object.ObjMetadata
+	Error      error
+}
+
+type ExpRetryEvent struct {
+	GroupName  string
+	Action     event.ResourceAction
+	Identifier object.ObjMetadata
+	Attempt    int
+	Error      error
+}
+
 func VerifyEvents(expEvents []ExpEvent, events []event.Event) error {
 	if len(expEvents) == 0 && len(events) == 0 {
 		return nil
@@ -252,6 +285,38 @@ func isMatch(ee ExpEvent, e event.Event) bool {
 		}
 		return de.Error == nil
 
+	case event.RetryType:
+		ree := ee.RetryEvent
+		if ree == nil {
+			return true
+		}
+		re := e.RetryEvent
+
+		if ree.Identifier != object.NilObjMetadata {
+			if ree.Identifier != re.Identifier {
+				return false
+			}
+		}
+
+		if ree.GroupName != "" {
+			if ree.GroupName != re.GroupName {
+				return false
+			}
+		}
+
+		if ree.Action != re.Action {
+			return false
+		}
+
+		if ree.Attempt != re.Attempt {
+			return false
+		}
+
+		if ree.Error != nil {
+			return re.Error != nil
+		}
+		return re.Error == nil
+
 	case event.WaitType:
 		wee := ee.WaitEvent
 		if wee == nil {
@@ -294,6 +359,90 @@ func isMatch(ee ExpEvent, e event.Event) bool {
 		}
 		return ve.Error == nil
 
+	case event.RollbackType:
+		ree := ee.RollbackEvent
+		if ree == nil {
+			return true
+		}
+		re := e.RollbackEvent
+
+		if ree.Identifier != object.NilObjMetadata {
+			if ree.Identifier != re.Identifier {
+				return false
+			}
+		}
+
+		if ree.GroupName != "" {
+			if ree.GroupName != re.GroupName {
+				return false
+			}
+		}
+
+		if ree.Operation != re.Operation {
+			return false
+		}
+
+		if ree.Error != nil {
+			return re.Error != nil
+		}
+		return re.Error == nil
+
+	case event.HookType:
+		hee := ee.HookEvent
+		if hee == nil {
+			return true
+		}
+		he := e.HookEvent
+
+		if hee.Identifier != object.NilObjMetadata {
+			if hee.Identifier != he.Identifier {
+				return false
+			}
+		}
+
+		if hee.GroupName != "" {
+			if hee.GroupName != he.GroupName {
+				return false
+			}
+		}
+
+		if hee.Operation != he.Operation {
+			return false
+		}
+
+		if hee.Error != nil {
+			return he.Error != nil
+		}
+		return he.Error == nil
+
+	case event.DriftType:
+		dee := ee.DriftEvent
+		if dee == nil {
+			return true
+		}
+		de := e.DriftEvent
+
+		if dee.Identifier != object.NilObjMetadata {
+			if dee.Identifier != de.Identifier {
+				return false
+			}
+		}
+
+		if dee.GroupName != "" {
+			if dee.GroupName != de.GroupName {
+				return false
+			}
+		}
+
+		if dee.Operation != de.Operation {
+			return false
+		}
+
+		if dee.Error != nil {
+			return de.Error != nil
+		}
+		return de.Error == nil
+
 	default:
 		return true
 	}
@@ -397,6 +546,51 @@ func EventToExpEvent(e event.Event) ExpEvent {
 				Error:       e.ValidationEvent.Error,
 			},
 		}
+
+	case event.RollbackType:
+		return ExpEvent{
+			EventType: event.RollbackType,
+			RollbackEvent: &ExpRollbackEvent{
+				GroupName:  e.RollbackEvent.GroupName,
+				Identifier: e.RollbackEvent.Identifier,
+				Operation:  e.RollbackEvent.Operation,
+				Error:      e.RollbackEvent.Error,
+			},
+		}
+
+	case event.HookType:
+		return ExpEvent{
+			EventType: event.HookType,
+			HookEvent: &ExpHookEvent{
+				GroupName:  e.HookEvent.GroupName,
+				Identifier: e.HookEvent.Identifier,
+				Operation:  e.HookEvent.Operation,
+				Error:      e.HookEvent.Error,
+			},
+		}
+
+	case event.DriftType:
+		return ExpEvent{
+			EventType: event.DriftType,
+			DriftEvent: &ExpDriftEvent{
+				GroupName:  e.DriftEvent.GroupName,
+				Identifier: e.DriftEvent.Identifier,
+				Operation:  e.DriftEvent.Operation,
+				Error:      e.DriftEvent.Error,
+			},
+		}
+
+	case event.RetryType:
+		return ExpEvent{
+			EventType: event.RetryType,
+			RetryEvent: &ExpRetryEvent{
+				GroupName:  e.RetryEvent.GroupName,
+				Action:     e.RetryEvent.Action,
+				Identifier: e.RetryEvent.Identifier,
+				Attempt:    e.RetryEvent.Attempt,
+				Error:      e.RetryEvent.Error,
+			},
+		}
 	}
 	return ExpEvent{}
 }
@@ -466,6 +660,30 @@ func (ape GroupedEventsByID) Less(i, j int) bool {
 			return false
 		}
 		return ape[i].WaitEvent.Identifier.String() < ape[j].WaitEvent.Identifier.String()
+	case event.RollbackType:
+		if ape[i].RollbackEvent.GroupName != ape[j].RollbackEvent.GroupName {
+			// don't change order if not the same task group
+			return false
+		}
+		return ape[i].RollbackEvent.Identifier.String() < ape[j].RollbackEvent.Identifier.String()
+	case event.HookType:
+		if ape[i].HookEvent.GroupName != ape[j].HookEvent.GroupName {
+			// don't change order if not the same task group
+			return false
+		}
+		return ape[i].HookEvent.Identifier.String() < ape[j].HookEvent.Identifier.String()
+	case event.DriftType:
+		if ape[i].DriftEvent.GroupName != ape[j].DriftEvent.GroupName {
+			// don't change order if not the same task group
+			return false
+		}
+		return ape[i].DriftEvent.Identifier.String() < ape[j].DriftEvent.Identifier.String()
+	case event.RetryType:
+		if ape[i].RetryEvent.GroupName != ape[j].RetryEvent.GroupName {
+			// don't change order if not the same task group
+			return false
+		}
+		return ape[i].RetryEvent.Identifier.String() < ape[j].RetryEvent.Identifier.String()
 	case event.ValidationType:
 		return ape[i].ValidationEvent.Identifiers.Hash() < ape[j].ValidationEvent.Identifiers.Hash()
 	default: